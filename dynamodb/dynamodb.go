@@ -20,6 +20,7 @@ import (
 	"github.com/thalesfsp/dal/v2/internal/logging"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/customsort"
@@ -103,12 +104,26 @@ func ToDynamoDBSort(s customsort.Sort) (bool, error) {
 	return true, nil // ScanIndexForward = true for ascending
 }
 
+// database returns the database context of the spans of `trgt`.
+func (d *DynamoDB) database(trgt string) tracing.Database {
+	return tracing.Database{Name: d.Region, Collection: trgt}
+}
+
 //////
 // Implements the IStorage interface.
 //////
 
 // Count returns the number of items in the storage.
 func (d *DynamoDB) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, d, storage.OperationCount, target, "")
+
+	c, err := d.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (d *DynamoDB) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -159,6 +174,8 @@ func (d *DynamoDB) Count(ctx context.Context, target string, prm *count.Count, o
 		return 0, customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(d.database(trgt))
+
 	//////
 	// Count.
 	//////
@@ -220,6 +237,8 @@ func (d *DynamoDB) Count(ctx context.Context, target string, prm *count.Count, o
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: aws.StringValue(scanInput.FilterExpression)})
+
 	// Scan pages are capped at 1MB — paginate to count the whole table.
 	var count int64
 
@@ -273,6 +292,13 @@ func (d *DynamoDB) Count(ctx context.Context, target string, prm *count.Count, o
 
 // Delete removes data.
 func (d *DynamoDB) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, d, storage.OperationDelete, target, id)
+
+	return o.Done(nil, d.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (d *DynamoDB) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -310,6 +336,10 @@ func (d *DynamoDB) Delete(ctx context.Context, id, target string, prm *delete.De
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterDeletedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -332,6 +362,8 @@ func (d *DynamoDB) Delete(ctx context.Context, id, target string, prm *delete.De
 		return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(d.database(trgt))
+
 	//////
 	// Delete.
 	//////
@@ -371,6 +403,8 @@ func (d *DynamoDB) Delete(ctx context.Context, id, target string, prm *delete.De
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, d, id, trgt, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterDeletedFailed())
@@ -399,6 +433,13 @@ func (d *DynamoDB) Delete(ctx context.Context, id, target string, prm *delete.De
 
 // Retrieve data.
 func (d *DynamoDB) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, d, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, d.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (d *DynamoDB) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -458,6 +499,8 @@ func (d *DynamoDB) Retrieve(ctx context.Context, id, target string, v any, prm *
 		return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(d.database(trgt))
+
 	//////
 	// Retrieve.
 	//////
@@ -514,6 +557,8 @@ func (d *DynamoDB) Retrieve(ctx context.Context, id, target string, v any, prm *
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, d, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterRetrievedFailed())
@@ -543,9 +588,16 @@ func (d *DynamoDB) Retrieve(ctx context.Context, id, target string, v any, prm *
 // List data.
 //
 // NOTE: It uses param.List.Any for DynamoDB filter expressions.
+func (d *DynamoDB) List(ctx context.Context, target string, v any, prm *list.List, opts ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, d, storage.OperationList, target, "")
+
+	return o.Done(v, d.list(ctx, target, v, prm, opts...), ErrorKind)
+}
+
+// list is List's implementation. See List.
 //
 //nolint:gocognit,cyclop,funlen,gocyclo,maintidx
-func (d *DynamoDB) List(ctx context.Context, target string, v any, prm *list.List, opts ...storage.Func[*list.List]) error {
+func (d *DynamoDB) list(ctx context.Context, target string, v any, prm *list.List, opts ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -596,6 +648,8 @@ func (d *DynamoDB) List(ctx context.Context, target string, v any, prm *list.Lis
 		return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(d.database(trgt))
+
 	//////
 	// Query preparation.
 	//////
@@ -670,6 +724,8 @@ func (d *DynamoDB) List(ctx context.Context, target string, v any, prm *list.Lis
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: aws.StringValue(scanInput.FilterExpression)})
+
 	var allItems []map[string]*dynamodb.AttributeValue
 	var lastEvaluatedKey map[string]*dynamodb.AttributeValue
 
@@ -729,6 +785,8 @@ func (d *DynamoDB) List(ctx context.Context, target string, v any, prm *list.Lis
 		)
 	}
 
+	span.SetRows(int64(len(allItems)))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, d, "", trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterListedFailed())
@@ -759,8 +817,21 @@ func (d *DynamoDB) List(ctx context.Context, target string, v any, prm *list.Lis
 //
 // NOTE: DynamoDB requires the primary key to be set in the model (`v`).
 //
-//nolint:gocognit,nestif
+// NOTE: It's insert-only (`attribute_not_exists` condition), failing with
+// `storage.ErrAlreadyExists` if the item exists. Use `storage.WithOverwrite`
+// to replace it.
 func (d *DynamoDB) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, d, storage.OperationCreate, target, id)
+
+	createdID, err := d.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+//
+//nolint:gocognit,nestif
+func (d *DynamoDB) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	if id == "" {
 		return "", customapm.TraceError(
 			ctx,
@@ -798,6 +869,10 @@ func (d *DynamoDB) Create(ctx context.Context, id, target string, v any, prm *cr
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return "", customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterCreatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -820,6 +895,8 @@ func (d *DynamoDB) Create(ctx context.Context, id, target string, v any, prm *cr
 		return "", customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(d.database(trgt))
+
 	//////
 	// Create.
 	//////
@@ -881,7 +958,21 @@ func (d *DynamoDB) Create(ctx context.Context, id, target string, v any, prm *cr
 		Item:      item,
 	}
 
+	// Insert-only, unless asked to overwrite.
+	if !o.Overwrite {
+		putInput.ConditionExpression = aws.String("attribute_not_exists(#pk)")
+		putInput.ExpressionAttributeNames = map[string]*string{"#pk": aws.String(d.PrimaryKey)}
+	}
+
+	span.SetDatabase(tracing.Database{Statement: aws.StringValue(putInput.ConditionExpression)})
+
 	if _, err := d.Client.PutItemWithContext(ctx, putInput); err != nil {
+		// The only condition is the key's absence.
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			err = fmt.Errorf("%w: %w", storage.ErrAlreadyExists, err)
+		}
+
 		return "", customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(storage.OperationCreate.String(), customerror.WithError(err)),
@@ -890,6 +981,8 @@ func (d *DynamoDB) Create(ctx context.Context, id, target string, v any, prm *cr
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, d, id, trgt, v, finalParam); err != nil {
 			return "", customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterCreatedFailed())
@@ -918,6 +1011,13 @@ func (d *DynamoDB) Create(ctx context.Context, id, target string, v any, prm *cr
 
 // Update data.
 func (d *DynamoDB) Update(ctx context.Context, id, target string, v any, prm *update.Update, opts ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, d, storage.OperationUpdate, target, id)
+
+	return o.Done(v, d.update(ctx, id, target, v, prm, opts...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (d *DynamoDB) update(ctx context.Context, id, target string, v any, prm *update.Update, opts ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -955,6 +1055,10 @@ func (d *DynamoDB) Update(ctx context.Context, id, target string, v any, prm *up
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterUpdatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -977,6 +1081,8 @@ func (d *DynamoDB) Update(ctx context.Context, id, target string, v any, prm *up
 		return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(d.database(trgt))
+
 	//////
 	// Update.
 	//////
@@ -1048,6 +1154,8 @@ func (d *DynamoDB) Update(ctx context.Context, id, target string, v any, prm *up
 		ExpressionAttributeValues: expressionAttributeValues,
 	}
 
+	span.SetDatabase(tracing.Database{Statement: aws.StringValue(updateInput.UpdateExpression)})
+
 	if _, err := d.Client.UpdateItemWithContext(ctx, updateInput); err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -1057,6 +1165,8 @@ func (d *DynamoDB) Update(ctx context.Context, id, target string, v any, prm *up
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, d, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, d.GetLogger(), d.GetCounterUpdatedFailed())
@@ -1093,22 +1203,28 @@ func (d *DynamoDB) GetClient() any {
 //////
 
 // New creates a new DynamoDB storage.
-func New(ctx context.Context, region string, cfg *Config) (*DynamoDB, error) {
-	return NewWithTable(ctx, region, "", cfg)
+func New(ctx context.Context, region string, cfg *Config, options ...storage.ConfigFunc) (*DynamoDB, error) {
+	return NewWithTable(ctx, region, "", cfg, options...)
 }
 
 // NewWithTable creates a new DynamoDB storage with a static table name.
-func NewWithTable(ctx context.Context, region, tableName string, cfg *Config) (*DynamoDB, error) {
-	return NewWithDynamicTable(ctx, region, func() string { return tableName }, cfg)
+func NewWithTable(ctx context.Context, region, tableName string, cfg *Config, options ...storage.ConfigFunc) (*DynamoDB, error) {
+	return NewWithDynamicTable(ctx, region, func() string { return tableName }, cfg, options...)
 }
 
 // NewWithDynamicTable creates a new DynamoDB storage with a dynamic table function.
 // This allows for table names to be computed at runtime, useful for time-based partitioning.
-func NewWithDynamicTable(ctx context.Context, region string, dynamicTableFunc DynamicTableFunc, cfg *Config) (*DynamoDB, error) {
+func NewWithDynamicTable(
+	ctx context.Context,
+	region string,
+	dynamicTableFunc DynamicTableFunc,
+	cfg *Config,
+	options ...storage.ConfigFunc,
+) (*DynamoDB, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*DynamoDB)(nil)
 
-	s, err := storage.New(ctx, Name)
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -9,9 +9,11 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/storage"
 )
 
 //////
@@ -96,6 +98,37 @@ func IsProvisionedThroughputExceededError(err error) bool {
 	return false
 }
 
+// IsTransientError checks if the error is worth retrying: throttling
+// (provisioned throughput, request limit), internal server errors, transaction
+// conflicts, 5xx responses, and whatever `storage.IsTransientError` classifies.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case dynamodb.ErrCodeProvisionedThroughputExceededException,
+			dynamodb.ErrCodeRequestLimitExceeded,
+			dynamodb.ErrCodeInternalServerError,
+			dynamodb.ErrCodeTransactionConflictException:
+			return true
+		}
+
+		if request.IsErrorThrottle(awsErr) {
+			return true
+		}
+
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() >= http.StatusInternalServerError {
+			return true
+		}
+	}
+
+	return storage.IsTransientError(err)
+}
+
 // BuildFilterExpression builds a DynamoDB filter expression from a map of conditions.
 func BuildFilterExpression(conditions map[string]interface{}) (
 	*string,
@@ -236,3 +269,32 @@ func MarshalKey(keyName string, keyValue interface{}) (map[string]*dynamodb.Attr
 		keyName: av,
 	}, nil
 }
+
+// ErrorKind maps `err` to a storage error kind: missing tables, or items to
+// `storage.ErrNotFound`, failed conditions, and transaction conflicts to
+// `storage.ErrConflict`, validation errors to `storage.ErrInvalidArgument`,
+// access denied to `storage.ErrPermissionDenied`, and throttling, and other
+// transient errors to `storage.ErrUnavailable`.
+func ErrorKind(err error) error {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case dynamodb.ErrCodeResourceNotFoundException:
+			return storage.ErrNotFound
+		case dynamodb.ErrCodeConditionalCheckFailedException,
+			dynamodb.ErrCodeTransactionConflictException,
+			dynamodb.ErrCodeTransactionCanceledException:
+			return storage.ErrConflict
+		case "ValidationException", "SerializationException":
+			return storage.ErrInvalidArgument
+		case "AccessDeniedException", "UnrecognizedClientException", "MissingAuthenticationTokenException":
+			return storage.ErrPermissionDenied
+		}
+	}
+
+	if IsTransientError(err) {
+		return storage.ErrUnavailable
+	}
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/storage"
 )
 
 var placeholderRE = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
@@ -125,6 +126,22 @@ func TestErrorHelpers(t *testing.T) {
 
 	assert.True(t, IsProvisionedThroughputExceededError(throughput))
 	assert.False(t, IsProvisionedThroughputExceededError(condFailed))
+
+	// Throttling, and 5xx are transient, even when wrapped.
+	assert.True(t, IsTransientError(throughput))
+	assert.True(t, IsTransientError(customerror.NewFailedToError("retrieve", customerror.WithError(throughput))))
+	assert.True(t, IsTransientError(awserr.New("ThrottlingException", "slow down", nil)))
+	assert.True(t, IsTransientError(awserr.NewRequestFailure(awserr.New("Unknown", "boom", nil), http.StatusBadGateway, "req")))
+	assert.False(t, IsTransientError(condFailed))
+	assert.False(t, IsTransientError(notFound))
+	assert.False(t, IsTransientError(nil))
+
+	// Error kinds.
+	assert.ErrorIs(t, storage.WrapError("d", storage.OperationRetrieve, "t", "1", notFound, ErrorKind), storage.ErrNotFound)
+	assert.Equal(t, storage.ErrConflict, ErrorKind(condFailed))
+	assert.Equal(t, storage.ErrUnavailable, ErrorKind(throughput))
+	assert.Equal(t, storage.ErrInvalidArgument, ErrorKind(awserr.New("ValidationException", "bad", nil)))
+	assert.Nil(t, ErrorKind(assert.AnError))
 }
 
 func TestMarshalRoundTrip(t *testing.T) {
@@ -16,6 +16,7 @@ import (
 	"github.com/thalesfsp/dal/v2/internal/logging"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/customsort"
@@ -90,6 +91,11 @@ func ToElasticSearchString(s customsort.SortSlice) (string, error) {
 	return "[" + strings.Join(sortFields, ",") + "]", nil
 }
 
+// database returns the database context of the spans of `trgt`.
+func (es *ElasticSearch) database(trgt string) tracing.Database {
+	return tracing.Database{Collection: trgt, User: es.Config.Username}
+}
+
 // buildQuery builds a query string from the list parameters and optional addons.
 // It uses the ElasticSearch query syntax to construct the query.
 func buildQuery(params *list.List, addons ...string) (string, error) {
@@ -233,6 +239,15 @@ func (es *ElasticSearch) DeleteIndex(ctx context.Context, name string) error {
 
 // Count returns the number of items in the storage.
 func (es *ElasticSearch) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, es, storage.OperationCount, target, "")
+
+	c, err := es.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (es *ElasticSearch) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -285,6 +300,8 @@ func (es *ElasticSearch) Count(ctx context.Context, target string, prm *count.Co
 		return 0, customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(es.database(trgt))
+
 	//////
 	// Count.
 	//////
@@ -315,6 +332,8 @@ func (es *ElasticSearch) Count(ctx context.Context, target string, prm *count.Co
 		return 0, customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Statement: query})
+
 	// Set the query.
 	req.Body = strings.NewReader(query)
 
@@ -377,6 +396,13 @@ func (es *ElasticSearch) Count(ctx context.Context, target string, prm *count.Co
 
 // Delete removes data.
 func (es *ElasticSearch) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, es, storage.OperationDelete, target, id)
+
+	return o.Done(nil, es.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (es *ElasticSearch) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -414,6 +440,10 @@ func (es *ElasticSearch) Delete(ctx context.Context, id, target string, prm *del
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterDeletedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -436,6 +466,8 @@ func (es *ElasticSearch) Delete(ctx context.Context, id, target string, prm *del
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(es.database(trgt))
+
 	//////
 	// Delete.
 	//////
@@ -470,6 +502,8 @@ func (es *ElasticSearch) Delete(ctx context.Context, id, target string, prm *del
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterDeletedFailed())
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, es, id, trgt, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterDeletedFailed())
@@ -498,6 +532,13 @@ func (es *ElasticSearch) Delete(ctx context.Context, id, target string, prm *del
 
 // Retrieve data.
 func (es *ElasticSearch) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, es, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, es.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (es *ElasticSearch) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -557,6 +598,8 @@ func (es *ElasticSearch) Retrieve(ctx context.Context, id, target string, v any,
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(es.database(trgt))
+
 	//////
 	// Retrieve.
 	//////
@@ -603,6 +646,8 @@ func (es *ElasticSearch) Retrieve(ctx context.Context, id, target string, v any,
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterRetrievedFailed())
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, es, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterRetrievedFailed())
@@ -632,9 +677,16 @@ func (es *ElasticSearch) Retrieve(ctx context.Context, id, target string, v any,
 // List data.
 //
 // NOTE: It uses param.List.Search to query the data.
+func (es *ElasticSearch) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, es, storage.OperationList, target, "")
+
+	return o.Done(v, es.list(ctx, target, v, prm, options...), ErrorKind)
+}
+
+// list is List's implementation. See List.
 //
 //nolint:nestif,gocognit
-func (es *ElasticSearch) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+func (es *ElasticSearch) list(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -687,6 +739,8 @@ func (es *ElasticSearch) List(ctx context.Context, target string, v any, prm *li
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(es.database(trgt))
+
 	//////
 	// List.
 	//////
@@ -712,6 +766,8 @@ func (es *ElasticSearch) List(ctx context.Context, target string, v any, prm *li
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Statement: query})
+
 	// Set the query.
 	req.Body = strings.NewReader(query)
 
@@ -787,6 +843,8 @@ func (es *ElasticSearch) List(ctx context.Context, target string, v any, prm *li
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterListedFailed())
 	}
 
+	span.SetRows(int64(len(mapHits)))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, es, "", trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterListedFailed())
@@ -815,9 +873,22 @@ func (es *ElasticSearch) List(ctx context.Context, target string, v any, prm *li
 
 // Create data.
 //
+// NOTE: It's insert-only (`op_type=create`), failing with
+// `storage.ErrAlreadyExists` if the document exists. Use
+// `storage.WithOverwrite` to replace it.
+//
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set. You are better off setting the ID yourself.
 func (es *ElasticSearch) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, es, storage.OperationCreate, target, id)
+
+	createdID, err := es.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+func (es *ElasticSearch) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -846,6 +917,10 @@ func (es *ElasticSearch) Create(ctx context.Context, id, target string, v any, p
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return "", customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterCreatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -868,6 +943,8 @@ func (es *ElasticSearch) Create(ctx context.Context, id, target string, v any, p
 		return "", customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(es.database(trgt))
+
 	//////
 	// Create.
 	//////
@@ -893,6 +970,11 @@ func (es *ElasticSearch) Create(ctx context.Context, id, target string, v any, p
 		reqOpts = append(reqOpts, es.Client.Index.WithRouting(finalParam.Routing))
 	}
 
+	// Insert-only, unless asked to overwrite.
+	if !o.Overwrite {
+		reqOpts = append(reqOpts, es.Client.Index.WithOpType("create"))
+	}
+
 	res, err := es.Client.Index(trgt, bytes.NewReader(valueAsJSON), reqOpts...)
 	if err != nil {
 		return id, customapm.TraceError(
@@ -909,6 +991,8 @@ func (es *ElasticSearch) Create(ctx context.Context, id, target string, v any, p
 		return "", customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterCreatedFailed())
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, es, id, trgt, v, finalParam); err != nil {
 			return "", customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterCreatedFailed())
@@ -937,6 +1021,13 @@ func (es *ElasticSearch) Create(ctx context.Context, id, target string, v any, p
 
 // Update data.
 func (es *ElasticSearch) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, es, storage.OperationUpdate, target, id)
+
+	return o.Done(v, es.update(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (es *ElasticSearch) update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -974,6 +1065,10 @@ func (es *ElasticSearch) Update(ctx context.Context, id, target string, v any, p
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterUpdatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -996,6 +1091,8 @@ func (es *ElasticSearch) Update(ctx context.Context, id, target string, v any, p
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(es.database(trgt))
+
 	//////
 	// Update.
 	//////
@@ -1042,6 +1139,8 @@ func (es *ElasticSearch) Update(ctx context.Context, id, target string, v any, p
 		return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterUpdatedFailed())
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, es, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, es.GetLogger(), es.GetCounterUpdatedFailed())
@@ -1078,8 +1177,8 @@ func (es *ElasticSearch) GetClient() any {
 //////
 
 // New returns a new `ElasticSearch` storage.
-func New(ctx context.Context, cfg Config) (*ElasticSearch, error) {
-	return NewWithIndex(ctx, "", cfg)
+func New(ctx context.Context, cfg Config, options ...storage.ConfigFunc) (*ElasticSearch, error) {
+	return NewWithIndex(ctx, "", cfg, options...)
 }
 
 // NewWithIndex returns a new `ElasticSearch` storage specifying the index name.
@@ -1087,8 +1186,9 @@ func NewWithIndex(
 	ctx context.Context,
 	indexName string,
 	cfg Config,
+	options ...storage.ConfigFunc,
 ) (*ElasticSearch, error) {
-	return NewWithDynamicIndex(ctx, func() string { return indexName }, cfg)
+	return NewWithDynamicIndex(ctx, func() string { return indexName }, cfg, options...)
 }
 
 // NewWithDynamicIndex returns a new `ElasticSearch` storage. It allows to
@@ -1098,11 +1198,12 @@ func NewWithDynamicIndex(
 	ctx context.Context,
 	dynamicIndexFunc DynamicIndexFunc,
 	cfg Config,
+	options ...storage.ConfigFunc,
 ) (*ElasticSearch, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*ElasticSearch)(nil)
 
-	s, err := storage.New(ctx, Name)
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
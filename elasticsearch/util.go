@@ -2,11 +2,13 @@ package elasticsearch
 
 import (
 	"io"
+	"net/http"
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v8/esapi"
 	"github.com/thalesfsp/customerror"
 	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/dal/v2/storage"
 )
 
 // maxCausedByDepth bounds the recursion when walking nested `caused_by`
@@ -104,3 +106,30 @@ func checkResponseIsError(res *esapi.Response, ignoreIf ...string) error {
 
 	return nil
 }
+
+// IsTransientError reports whether `err` is worth retrying: request timeouts
+// (408), rejected executions, and tripped circuit breakers (429), 502, 503,
+// 504, and whatever `storage.IsTransientError` classifies.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if storage.HasStatusCode(err, http.StatusRequestTimeout) {
+		return true
+	}
+
+	return storage.IsTransientError(err)
+}
+
+// ErrorKind maps `err` to a storage error kind. ES reports errors through HTTP
+// status codes, handled by `storage.KindOf`, except for creating a document
+// which exists (409, `version_conflict_engine_exception`, "document already
+// exists") which maps to `storage.ErrAlreadyExists`.
+func ErrorKind(err error) error {
+	if storage.HasStatusCode(err, http.StatusConflict) && strings.Contains(err.Error(), "document already exists") {
+		return storage.ErrAlreadyExists
+	}
+
+	return nil
+}
@@ -3,11 +3,14 @@ package elasticsearch
 import (
 	"bytes"
 	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/storage"
 )
 
 // Real-shape sample: a production `search_phase_execution_exception` whose
@@ -164,3 +167,36 @@ func TestParseResponseBodyError_MalformedJSON_ReturnsError(t *testing.T) {
 	_, err := parseResponseBodyError(bytes.NewReader([]byte(`{not json`)))
 	require.Error(t, err, "malformed JSON must produce a parse error, not a panic")
 }
+
+func TestIsTransientError(t *testing.T) {
+	for _, code := range []int{
+		http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	} {
+		assert.True(t, IsTransientError(customerror.New("rejected", customerror.WithStatusCode(code))), code)
+	}
+
+	for _, code := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict} {
+		assert.False(t, IsTransientError(customerror.New("rejected", customerror.WithStatusCode(code))), code)
+	}
+
+	assert.False(t, IsTransientError(nil))
+}
+
+func TestErrorKind(t *testing.T) {
+	exists := customerror.New(
+		"version_conflict_engine_exception: [1]: version conflict, document already exists",
+		customerror.WithStatusCode(http.StatusConflict),
+	)
+
+	assert.Equal(t, storage.ErrAlreadyExists, ErrorKind(exists))
+	assert.Nil(t, ErrorKind(customerror.New("version conflict", customerror.WithStatusCode(http.StatusConflict))))
+
+	// Everything else is classified by status code.
+	assert.ErrorIs(t, storage.WrapError("es", storage.OperationRetrieve, "i", "1", customerror.New(
+		"not found", customerror.WithStatusCode(http.StatusNotFound),
+	), ErrorKind), storage.ErrNotFound)
+}
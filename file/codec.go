@@ -0,0 +1,208 @@
+package file
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml"
+	"github.com/thalesfsp/customerror"
+	"gopkg.in/yaml.v3"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// Codec encodes, and decodes files. Register custom ones with RegisterCodec.
+type Codec interface {
+	// Encode writes `v` to `w`.
+	Encode(w io.Writer, v any) error
+
+	// Decode reads `r` into `v`, a pointer.
+	Decode(r io.Reader, v any) error
+}
+
+// Built-in codecs.
+var (
+	// JSON codec, the default.
+	JSON Codec = jsonCodec{}
+
+	// PrettyJSON is the JSON codec, indented.
+	PrettyJSON Codec = jsonCodec{indent: "  "}
+
+	// YAML codec.
+	YAML Codec = yamlCodec{}
+
+	// TOML codec. Documents are structs, or maps.
+	TOML Codec = tomlCodec{}
+
+	// Gob codec.
+	Gob Codec = gobCodec{}
+
+	// CSV codec. Documents are slices of structs - a header, then a record
+	// per struct. See csvCodec.
+	CSV Codec = csvCodec{}
+)
+
+// codecs are the codecs, by extension.
+var codecs = struct {
+	sync.RWMutex
+
+	m map[string]Codec
+}{m: map[string]Codec{
+	".json": JSON,
+	".yaml": YAML,
+	".yml":  YAML,
+	".toml": TOML,
+	".gob":  Gob,
+	".csv":  CSV,
+}}
+
+// jsonCodec is the JSON codec.
+type jsonCodec struct {
+	indent string
+}
+
+// yamlCodec is the YAML codec.
+type yamlCodec struct{}
+
+// tomlCodec is the TOML codec.
+type tomlCodec struct{}
+
+// gobCodec is the Gob codec.
+type gobCodec struct{}
+
+//////
+// Methods.
+//////
+
+// Encode implements the Codec interface.
+func (c jsonCodec) Encode(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", c.indent)
+
+	return encoder.Encode(v)
+}
+
+// Decode implements the Codec interface.
+func (c jsonCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// Encode implements the Codec interface.
+func (c yamlCodec) Encode(w io.Writer, v any) error {
+	encoder := yaml.NewEncoder(w)
+
+	if err := encoder.Encode(v); err != nil {
+		encoder.Close()
+
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// Decode implements the Codec interface.
+func (c yamlCodec) Decode(r io.Reader, v any) error {
+	return yaml.NewDecoder(r).Decode(v)
+}
+
+// Encode implements the Codec interface.
+func (c tomlCodec) Encode(w io.Writer, v any) error {
+	return toml.NewEncoder(w).Encode(v)
+}
+
+// Decode implements the Codec interface.
+func (c tomlCodec) Decode(r io.Reader, v any) error {
+	return toml.NewDecoder(r).Decode(v)
+}
+
+// Encode implements the Codec interface.
+func (c gobCodec) Encode(w io.Writer, v any) error {
+	return gob.NewEncoder(w).Encode(v)
+}
+
+// Decode implements the Codec interface.
+func (c gobCodec) Decode(r io.Reader, v any) error {
+	return gob.NewDecoder(r).Decode(v)
+}
+
+//////
+// Helpers.
+//////
+
+// normalizeExtension returns `ext` lowercased, and dot-prefixed.
+func normalizeExtension(ext string) string {
+	return "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// codec returns the codec of `path`: `Codec`, if set, or the one of its
+// extension, JSON if none.
+func (s *File) codec(path string) Codec {
+	if s.Codec != nil {
+		return s.Codec
+	}
+
+	if codec, ok := GetCodec(filepath.Ext(path)); ok {
+		return codec
+	}
+
+	return JSON
+}
+
+// toJSON converts `b`, encoded with `codec`, to JSON. Gob documents are
+// skipped, nil: Gob only decodes into the encoded type, never `any`.
+func toJSON(codec Codec, b []byte) ([]byte, error) {
+	switch codec.(type) {
+	case jsonCodec:
+		return b, nil
+	case gobCodec:
+		return nil, nil
+	}
+
+	var v any
+
+	if err := codec.Decode(bytes.NewReader(b), &v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// RegisterCodec registers `codec` for the files with the `ext` extension,
+// e.g.: `.yaml`, replacing the registered one, if any.
+func RegisterCodec(ext string, codec Codec) error {
+	if ext == "" || ext == "." {
+		return customerror.NewRequiredError("extension")
+	}
+
+	if codec == nil {
+		return customerror.NewRequiredError("codec")
+	}
+
+	codecs.Lock()
+	defer codecs.Unlock()
+
+	codecs.m[normalizeExtension(ext)] = codec
+
+	return nil
+}
+
+// GetCodec returns the codec registered for the `ext` extension, if any.
+func GetCodec(ext string) (Codec, bool) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+
+	codec, ok := codecs.m[normalizeExtension(ext)]
+
+	return codec, ok
+}
@@ -0,0 +1,196 @@
+package file
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+type csvRow struct {
+	Name    string            `csv:"name"`
+	Age     int               `json:"age"`
+	Score   *float64          `csv:"score"`
+	Born    time.Time         `csv:"born"`
+	Tags    map[string]string `csv:"tags"`
+	Ignored string            `csv:"-"`
+}
+
+// Codecs are picked by extension.
+func TestFile_Codecs(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		ext      string
+		contains string
+	}{
+		{ext: ".json", contains: `"name":"`},
+		{ext: ".yaml", contains: "name: "},
+		{ext: ".YML", contains: "name: "},
+		{ext: ".toml", contains: "Name = "},
+		{ext: ".gob"},
+		{ext: ".unknown", contains: `"name":"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ext, func(t *testing.T) {
+			path := filepath.Join(dir, "data"+tt.ext)
+
+			_, err := str.Create(ctx, "id", path, shared.TestData, nil)
+			require.NoError(t, err)
+
+			require.NoError(t, str.Update(ctx, "id", path, shared.UpdatedTestData, nil))
+
+			var got shared.TestDataS
+			require.NoError(t, str.Retrieve(ctx, "id", path, &got, nil))
+			assert.Equal(t, *shared.UpdatedTestData, got)
+
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(b), tt.contains)
+		})
+	}
+}
+
+// Codecs can be set explicitly, and registered.
+func TestFile_CodecOverrides(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	dir := t.TempDir()
+
+	str.Codec = PrettyJSON
+
+	path := filepath.Join(dir, "data.yaml")
+
+	_, err := str.Create(ctx, "id", path, shared.TestData, nil)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), "{\n  \"name\""), "explicit codecs win over extensions")
+
+	str.Codec = nil
+
+	require.NoError(t, RegisterCodec("upper", upperCodec{}))
+	require.Error(t, RegisterCodec("", upperCodec{}))
+	require.Error(t, RegisterCodec(".x", nil))
+
+	codec, ok := GetCodec(".UPPER")
+	require.True(t, ok)
+	assert.Equal(t, upperCodec{}, codec)
+
+	path = filepath.Join(dir, "data.upper")
+
+	_, err = str.Create(ctx, "id", path, "hello", nil)
+	require.NoError(t, err)
+
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", string(b))
+
+	var got string
+	require.NoError(t, str.Retrieve(ctx, "id", path, &got, nil))
+	assert.Equal(t, "hello", got)
+
+	_, err = str.Create(ctx, "id", filepath.Join(dir, "bad.json"), make(chan int), nil)
+	require.Error(t, err, "encoding errors fail the write")
+	assert.NoFileExists(t, filepath.Join(dir, "bad.json"))
+}
+
+// upperCodec stores strings uppercased.
+type upperCodec struct{}
+
+func (upperCodec) Encode(w io.Writer, v any) error {
+	_, err := io.WriteString(w, strings.ToUpper(v.(string)))
+
+	return err
+}
+
+func (upperCodec) Decode(r io.Reader, v any) error {
+	b, err := io.ReadAll(r)
+
+	*v.(*string) = strings.ToLower(string(b))
+
+	return err
+}
+
+func TestCSV(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	score := 9.5
+	born := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	rows := []csvRow{
+		{Name: "Ann, Jr.", Age: 30, Score: &score, Born: born, Tags: map[string]string{"k": "v"}, Ignored: "x"},
+		{Name: "Bob", Age: 40},
+	}
+
+	path := filepath.Join(t.TempDir(), "rows.csv")
+
+	_, err := str.Create(ctx, "id", path, rows, nil)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "name,age,score,born,tags\n"+
+		`"Ann, Jr.",30,9.5,2000-01-02T03:04:05Z,"{""k"":""v""}"`+"\n"+
+		"Bob,40,,0001-01-01T00:00:00Z,null\n", string(b))
+
+	var got []*csvRow
+	require.NoError(t, str.Retrieve(ctx, "id", path, &got, nil))
+	require.Len(t, got, 2)
+
+	rows[0].Ignored = ""
+
+	assert.Equal(t, rows[0], *got[0])
+	assert.Equal(t, rows[1], *got[1])
+
+	var untyped any
+	require.NoError(t, CSV.Decode(strings.NewReader(string(b)), &untyped))
+	assert.Equal(t, "Bob", untyped.([]map[string]string)[1]["name"])
+
+	_, err = str.Create(ctx, "id", filepath.Join(t.TempDir(), "bad.csv"), shared.TestData, nil)
+	assert.Error(t, err, "only slices of structs")
+}
+
+// Watched documents are converted to JSON.
+func TestFile_WatchCodec(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	dir := t.TempDir()
+
+	events := str.Watch(ctx, dir, storage.WithPollInterval(10*time.Millisecond), storage.WithFullDocument())
+
+	_, err := str.Create(ctx, "doc", filepath.Join(dir, "doc.yaml"), shared.TestData, nil)
+	require.NoError(t, err)
+
+	event := <-events
+	require.NoError(t, event.Err)
+
+	var got shared.TestDataS
+	require.NoError(t, json.Unmarshal(event.Document, &got))
+	assert.Equal(t, *shared.TestData, got)
+
+	// Gob only decodes into the encoded type: no document.
+	_, err = str.Create(ctx, "doc", filepath.Join(dir, "doc.gob"), shared.TestData, nil)
+	require.NoError(t, err)
+
+	event = <-events
+	require.NoError(t, event.Err)
+	assert.Equal(t, "doc.gob", event.ID)
+	assert.Nil(t, event.Document)
+}
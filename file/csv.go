@@ -0,0 +1,320 @@
+package file
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// csvCodec is the CSV codec. Documents are slices of structs, or of pointers
+// to structs: a header, then a record per struct.
+//
+// Columns are the exported fields, named by their `csv` tag, their `json` one
+// otherwise, or their name - `-` skips them. Values are formatted as text
+// (`encoding.TextMarshaler`), numbers, booleans, strings, or JSON otherwise.
+// Unknown columns are ignored when decoding.
+//
+// Decoding into `any` results in a slice of maps of strings, by column.
+type csvCodec struct{}
+
+// csvField is a column of a CSV document.
+type csvField struct {
+	name  string
+	index int
+}
+
+//////
+// Helpers.
+//////
+
+// csvFields returns the columns of `t`, a struct.
+func csvFields(t reflect.Type) []csvField {
+	fields := []csvField{}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+
+		for _, key := range []string{"csv", "json"} {
+			if tag, _, _ := strings.Cut(f.Tag.Get(key), ","); tag != "" {
+				name = tag
+
+				break
+			}
+		}
+
+		if name == "-" {
+			continue
+		}
+
+		fields = append(fields, csvField{name: name, index: i})
+	}
+
+	return fields
+}
+
+// csvStruct returns the struct type of the elements of `t`, a slice, or an
+// array.
+func csvStruct(t reflect.Type) (reflect.Type, error) {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil, customerror.NewInvalidError(fmt.Sprintf("csv document %s, expected a slice of structs", t))
+	}
+
+	elem := t.Elem()
+
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return nil, customerror.NewInvalidError(fmt.Sprintf("csv document %s, expected a slice of structs", t))
+	}
+
+	return elem, nil
+}
+
+// formatCSV formats `v` as a CSV value.
+func formatCSV(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+
+		v = v.Elem()
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+
+	b, err := json.Marshal(v.Interface())
+
+	return string(b), err
+}
+
+// parseCSV parses `s`, a CSV value, into `v`.
+func parseCSV(s string, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		if s == "" {
+			return nil
+		}
+
+		v.Set(reflect.New(v.Type().Elem()))
+
+		v = v.Elem()
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	if s == "" && v.Kind() != reflect.String {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(f)
+	default:
+		return json.Unmarshal([]byte(s), v.Addr().Interface())
+	}
+
+	return nil
+}
+
+//////
+// Methods.
+//////
+
+// Encode implements the Codec interface.
+func (c csvCodec) Encode(w io.Writer, v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	if !rv.IsValid() {
+		return customerror.NewRequiredError("csv document")
+	}
+
+	t, err := csvStruct(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	fields := csvFields(t)
+
+	record := make([]string, len(fields))
+
+	for i, f := range fields {
+		record[i] = f.name
+	}
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+
+	for i := range rv.Len() {
+		elem := reflect.Indirect(rv.Index(i))
+
+		for j, f := range fields {
+			record[j] = ""
+
+			if !elem.IsValid() {
+				continue
+			}
+
+			if record[j], err = formatCSV(elem.Field(f.index)); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// Decode implements the Codec interface.
+func (c csvCodec) Decode(r io.Reader, v any) error {
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return customerror.NewInvalidError("csv document, expected a pointer")
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	header, records := records[0], records[1:]
+
+	if rv.Elem().Kind() == reflect.Interface {
+		rows := make([]map[string]string, 0, len(records))
+
+		for _, record := range records {
+			row := make(map[string]string, len(header))
+
+			for i, name := range header {
+				row[name] = record[i]
+			}
+
+			rows = append(rows, row)
+		}
+
+		rv.Elem().Set(reflect.ValueOf(rows))
+
+		return nil
+	}
+
+	slice := rv.Elem()
+
+	if slice.Kind() != reflect.Slice {
+		return customerror.NewInvalidError(fmt.Sprintf("csv document %s, expected a slice of structs", slice.Type()))
+	}
+
+	t, err := csvStruct(slice.Type())
+	if err != nil {
+		return err
+	}
+
+	columns := map[string]int{}
+
+	for _, f := range csvFields(t) {
+		columns[f.name] = f.index
+	}
+
+	rows := reflect.MakeSlice(slice.Type(), 0, len(records))
+
+	for _, record := range records {
+		elem := reflect.New(t).Elem()
+
+		for i, name := range header {
+			index, ok := columns[name]
+			if !ok {
+				continue
+			}
+
+			if err := parseCSV(record[i], elem.Field(index)); err != nil {
+				return customerror.NewInvalidError(fmt.Sprintf("csv column %q", name), customerror.WithError(err))
+			}
+		}
+
+		if slice.Type().Elem().Kind() == reflect.Pointer {
+			elem = elem.Addr()
+		}
+
+		rows = reflect.Append(rows, elem)
+	}
+
+	slice.Set(rows)
+
+	return nil
+}
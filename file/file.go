@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 
 	"github.com/thalesfsp/customerror"
@@ -14,6 +15,7 @@ import (
 	"github.com/thalesfsp/dal/v2/internal/logging"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/delete"
@@ -52,6 +54,10 @@ type File struct {
 	// usage, the target can be static or dynamic - defined at the index time,
 	// for example: log-{YYYY}-{MM}. For File, it isn't used at all.
 	Target string `json:"-" validate:"omitempty,gt=0"`
+
+	// Codec, if set, encodes, and decodes every file. Otherwise, it's the one
+	// registered for the file's extension (see RegisterCodec), JSON if none.
+	Codec Codec `json:"-"`
 }
 
 //////
@@ -60,6 +66,15 @@ type File struct {
 
 // Count returns the number of items in the storage.
 func (s *File) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, s, storage.OperationCount, target, "")
+
+	c, err := s.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, nil)
+}
+
+// count is Count's implementation. See Count.
+func (s *File) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -113,6 +128,8 @@ func (s *File) Count(ctx context.Context, target string, prm *count.Count, optio
 		return 0, customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Count.
 	//////
@@ -133,6 +150,8 @@ func (s *File) Count(ctx context.Context, target string, prm *count.Count, optio
 		), s.GetLogger(), s.GetCounterCountedFailed())
 	}
 
+	matches = slices.DeleteFunc(matches, isTemp)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, "", target, int64(len(matches)), finalParam); err != nil {
 			return 0, customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCountedFailed())
@@ -161,6 +180,13 @@ func (s *File) Count(ctx context.Context, target string, prm *count.Count, optio
 
 // Delete removes data.
 func (s *File) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationDelete, target, id)
+
+	return o.Done(nil, s.delete(ctx, id, target, prm, options...), nil)
+}
+
+// delete is Delete's implementation. See Delete.
+func (s *File) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -198,6 +224,10 @@ func (s *File) Delete(ctx context.Context, id, target string, prm *delete.Delete
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -221,6 +251,8 @@ func (s *File) Delete(ctx context.Context, id, target string, prm *delete.Delete
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Delete.
 	//////
@@ -247,6 +279,8 @@ func (s *File) Delete(ctx context.Context, id, target string, prm *delete.Delete
 			s.GetCounterDeletedFailed())
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
@@ -274,7 +308,16 @@ func (s *File) Delete(ctx context.Context, id, target string, prm *delete.Delete
 }
 
 // Retrieve data.
+//
+// NOTE: Files are decoded by their codec. See `File.Codec`.
 func (s *File) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, s.retrieve(ctx, id, target, v, prm, options...), nil)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (s *File) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -334,6 +377,8 @@ func (s *File) Retrieve(ctx context.Context, id, target string, v any, prm *retr
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Retrieve.
 	//////
@@ -368,10 +413,17 @@ func (s *File) Retrieve(ctx context.Context, id, target string, v any, prm *retr
 	}
 	defer file.Close()
 
-	if err := shared.Decode(file, v); err != nil {
-		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
+	if err := s.codec(trgt).Decode(file, v); err != nil {
+		return customapm.TraceError(
+			ctx,
+			customerror.NewFailedToError("decode", customerror.WithError(err)),
+			s.GetLogger(),
+			s.GetCounterRetrievedFailed(),
+		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
@@ -405,6 +457,13 @@ func (s *File) Retrieve(ctx context.Context, id, target string, v any, prm *retr
 // NOTE: File does not support the concept of "offset" and "limit" in the same
 // way that a traditional SQL database does.
 func (s *File) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationList, target, "")
+
+	return o.Done(v, s.list(ctx, target, v, prm, options...), nil)
+}
+
+// list is List's implementation. See List.
+func (s *File) list(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -458,6 +517,8 @@ func (s *File) List(ctx context.Context, target string, v any, prm *list.List, o
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Query.
 	//////
@@ -480,12 +541,16 @@ func (s *File) List(ctx context.Context, target string, v any, prm *list.List, o
 		), s.GetLogger(), s.GetCounterListedFailed())
 	}
 
+	matches = slices.DeleteFunc(matches, isTemp)
+
 	keys.Keys = matches
 
 	if err := storage.ParseToStruct(keys, v); err != nil {
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
 	}
 
+	span.SetRows(int64(len(keys.Keys)))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, "", target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
@@ -514,11 +579,28 @@ func (s *File) List(ctx context.Context, target string, v any, prm *list.List, o
 
 // Create data.
 //
+// NOTE: Files are encoded by their codec. See `File.Codec`.
+//
+// NOTE: Writes are crash-safe - to a temporary file, synced, then moved into
+// place -, and serialized: in-process, and across processes (Linux, `flock`).
+//
+// NOTE: It's insert-only, failing with `storage.ErrAlreadyExists` if the file
+// exists. Use `storage.WithOverwrite` to replace it.
+//
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set. You are better off setting the ID yourself.
+func (s *File) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, s, storage.OperationCreate, target, id)
+
+	createdID, err := s.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, nil)
+}
+
+// create is Create's implementation. See Create.
 //
 //nolint:nestif,gocognit
-func (s *File) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+func (s *File) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -547,6 +629,10 @@ func (s *File) Create(ctx context.Context, id, target string, v any, prm *create
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -571,6 +657,8 @@ func (s *File) Create(ctx context.Context, id, target string, v any, prm *create
 		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	// Check if finalParam.Any is type of CreateAny.
 	if cA, ok := finalParam.Any.(*CreateAny); ok {
 		if cA.CreateIfNotExist {
@@ -585,20 +673,6 @@ func (s *File) Create(ctx context.Context, id, target string, v any, prm *create
 					return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
 				}
 			}
-
-			// Check if the file already exists.
-			if _, err := os.Stat(trgt); errors.Is(err, fs.ErrNotExist) {
-				// Create the file if it doesn't exist, closing the handle so
-				// it doesn't leak.
-				f, err := os.Create(trgt)
-				if err != nil {
-					return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
-				}
-
-				if err := f.Close(); err != nil {
-					return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
-				}
-			}
 		}
 	}
 
@@ -612,8 +686,8 @@ func (s *File) Create(ctx context.Context, id, target string, v any, prm *create
 		}
 	}
 
-	file, err := os.Create(trgt)
-	if err != nil {
+	// Insert-only, unless asked to overwrite.
+	if err := writeFile(trgt, v, s.codec(trgt), o.Overwrite); err != nil {
 		return "", customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(
@@ -624,16 +698,8 @@ func (s *File) Create(ctx context.Context, id, target string, v any, prm *create
 			s.GetCounterCreatedFailed(),
 		)
 	}
-	if err := shared.Encode(file, v); err != nil {
-		file.Close()
 
-		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
-	}
-
-	// A failed close means the data may not have hit the disk — surface it.
-	if err := file.Close(); err != nil {
-		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
-	}
+	span.SetRows(1)
 
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
@@ -663,8 +729,16 @@ func (s *File) Create(ctx context.Context, id, target string, v any, prm *create
 
 // Update data.
 //
-// NOTE: Not truly an update, it's an insert.
+// NOTE: Not truly an update, it's an insert. Writes are crash-safe, and
+// serialized, as Create's.
 func (s *File) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationUpdate, target, id)
+
+	return o.Done(v, s.update(ctx, id, target, v, prm, options...), nil)
+}
+
+// update is Update's implementation. See Update.
+func (s *File) update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -702,6 +776,10 @@ func (s *File) Update(ctx context.Context, id, target string, v any, prm *update
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -726,6 +804,8 @@ func (s *File) Update(ctx context.Context, id, target string, v any, prm *update
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Update.
 	//////
@@ -736,8 +816,7 @@ func (s *File) Update(ctx context.Context, id, target string, v any, prm *update
 		}
 	}
 
-	file, err := os.Create(trgt)
-	if err != nil {
+	if err := writeFile(trgt, v, s.codec(trgt), true); err != nil {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(
@@ -748,16 +827,8 @@ func (s *File) Update(ctx context.Context, id, target string, v any, prm *update
 			s.GetCounterUpdatedFailed(),
 		)
 	}
-	if err := shared.Encode(file, v); err != nil {
-		file.Close()
-
-		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
-	}
 
-	// A failed close means the data may not have hit the disk — surface it.
-	if err := file.Close(); err != nil {
-		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
-	}
+	span.SetRows(1)
 
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
@@ -790,20 +861,79 @@ func (s *File) GetClient() any {
 	return nil
 }
 
+// Watch streams the changes to the files of the `target` directory, detected
+// by polling their modification time, and size every `WithPollInterval`.
+// Event IDs are file names, as listed by `List`. Documents are converted to
+// JSON, and omitted for Gob files, which only decode into the encoded type,
+// or if their codec can't decode them into `any`.
+//
+// NOTE: Resume tokens aren't supported.
+func (s *File) Watch(ctx context.Context, target string, options ...storage.WatchFunc) <-chan storage.ChangeEvent {
+	o, err := storage.NewWatchOptions(options...)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	trgt, err := shared.TargetName(target, s.Target)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	snapshot := func(_ context.Context) (map[string]storage.PollEntry, error) {
+		entries, err := os.ReadDir(trgt)
+		if err != nil {
+			return nil, customerror.NewFailedToError("watch "+trgt, customerror.WithError(err))
+		}
+
+		files := make(map[string]storage.PollEntry, len(entries))
+
+		for _, entry := range entries {
+			if entry.IsDir() || isTemp(entry.Name()) {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				// Removed in the meantime.
+				continue
+			}
+
+			files[entry.Name()] = storage.PollEntry{ModTime: info.ModTime(), Size: info.Size()}
+		}
+
+		return files, nil
+	}
+
+	read := func(_ context.Context, id string) ([]byte, error) {
+		path := filepath.Join(trgt, id)
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return toJSON(s.codec(path), b)
+	}
+
+	return storage.PollWatch(ctx, target, o, snapshot, read)
+}
+
 //////
 // Factory.
 //////
 
 // New creates a new File storage.
-func New(ctx context.Context) (*File, error) {
+func New(ctx context.Context, options ...storage.ConfigFunc) (*File, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*File)(nil)
 
+	var _ storage.IWatcher = (*File)(nil)
+
 	//////
 	// Storage.
 	//////
 
-	s, err := storage.New(ctx, Name)
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
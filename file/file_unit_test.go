@@ -4,12 +4,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thalesfsp/customerror"
 	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/dal/v2/storage"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/delete"
@@ -57,6 +63,30 @@ func TestFile_CRUDRoundTrip(t *testing.T) {
 	assert.NoFileExists(t, target)
 }
 
+// Create is insert-only, unless asked to overwrite.
+func TestFile_CreateIsInsertOnly(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	target := filepath.Join(t.TempDir(), "data.json")
+
+	_, err := str.Create(ctx, "id-1", target, shared.TestData, nil)
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "id-1", target, shared.UpdatedTestData, nil)
+	assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+
+	var got shared.TestDataS
+	require.NoError(t, str.Retrieve(ctx, "id-1", target, &got, nil))
+	assert.Equal(t, *shared.TestData, got, "a failed create must not clobber the file")
+
+	_, err = str.Create(ctx, "id-1", target, shared.UpdatedTestData, nil, storage.WithOverwrite())
+	require.NoError(t, err)
+
+	require.NoError(t, str.Retrieve(ctx, "id-1", target, &got, nil))
+	assert.Equal(t, shared.DocumentNameUpdated, got.Name)
+}
+
 // Regression: Create with a nil params struct used to panic dereferencing
 // prm.Any before the nil check.
 func TestFile_CreateNilParams(t *testing.T) {
@@ -103,6 +133,7 @@ func TestFile_RetrieveMissing(t *testing.T) {
 	cE, ok := customerror.To(err)
 	require.True(t, ok)
 	assert.Equal(t, 404, cE.StatusCode)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
 }
 
 // Regression: a target-resolution failure in Retrieve must be attributed to
@@ -189,3 +220,105 @@ func TestFile_CreateIfNotExistDoesNotLeakFDs(t *testing.T) {
 	assert.Less(t, after-before, n,
 		"open file descriptors must not grow linearly with CreateIfNotExist calls (leak)")
 }
+
+// Watch detects created, updated, and deleted files by polling.
+func TestFile_Watch(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.json")
+
+	events := str.Watch(ctx, dir, storage.WithPollInterval(10*time.Millisecond), storage.WithFullDocument())
+
+	_, err := str.Create(ctx, "doc", path, shared.TestData, nil)
+	require.NoError(t, err)
+
+	event := <-events
+	require.NoError(t, event.Err)
+	assert.Equal(t, storage.OperationCreate, event.Operation)
+	assert.Equal(t, "doc.json", event.ID)
+	assert.NotEmpty(t, event.Document)
+
+	// Size changes even if the modification time resolution is coarse.
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"1","name":"updated"}`), 0o600))
+
+	event = <-events
+	require.NoError(t, event.Err)
+	assert.Equal(t, storage.OperationUpdate, event.Operation)
+
+	require.NoError(t, str.Delete(ctx, "doc", path, nil))
+
+	event = <-events
+	require.NoError(t, event.Err)
+	assert.Equal(t, storage.OperationDelete, event.Operation)
+	assert.Equal(t, "doc.json", event.ID)
+
+	event = <-str.Watch(ctx, filepath.Join(dir, "missing"))
+	require.Error(t, event.Err)
+}
+
+// Concurrent writers never interleave: the file always holds one complete
+// document, and no temporary file is left behind.
+func TestFile_ConcurrentWrites(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int64
+	)
+
+	for i := range 16 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			doc := &shared.TestDataS{Name: strings.Repeat("x", 1024*i), Version: strconv.Itoa(i)}
+
+			if _, err := str.Create(ctx, "id", path, doc, nil); err == nil {
+				created.Add(1)
+			} else {
+				assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+			}
+
+			assert.NoError(t, str.Update(ctx, "id", path, doc, nil))
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(1), created.Load(), "only one create wins")
+
+	var got shared.TestDataS
+	require.NoError(t, str.Retrieve(ctx, "id", path, &got, nil))
+	version, err := strconv.Atoi(got.Version)
+	require.NoError(t, err)
+	assert.Len(t, got.Name, 1024*version, "the document is complete")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are removed")
+}
+
+// Updates replace the file, keeping its permissions.
+func TestFile_UpdateKeepsPermissions(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	_, err := str.Create(ctx, "id", path, shared.TestData, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Chmod(path, 0o600))
+	require.NoError(t, str.Update(ctx, "id", path, shared.UpdatedTestData, nil))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// dirLock is an advisory (`flock`), exclusive lock of a directory.
+type dirLock struct {
+	f *os.File
+}
+
+// lockDir locks `dir`, waiting for other processes to unlock it.
+func lockDir(dir string) (*dirLock, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+
+	if err != nil {
+		f.Close()
+
+		return nil, &os.PathError{Op: "flock", Path: dir, Err: err}
+	}
+
+	return &dirLock{f: f}, nil
+}
+
+// sync flushes the directory entries, e.g.: renames, to disk.
+func (d *dirLock) sync() error {
+	return d.f.Sync()
+}
+
+// unlock unlocks the directory.
+func (d *dirLock) unlock() {
+	// Closing releases the lock.
+	_ = d.f.Close()
+}
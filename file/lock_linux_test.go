@@ -0,0 +1,40 @@
+//go:build linux
+
+package file
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The directory lock is exclusive across open files, as across processes.
+func TestLockDir(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := lockDir(dir)
+	require.NoError(t, err)
+
+	locked := make(chan *dirLock)
+
+	go func() {
+		second, err := lockDir(dir)
+		assert.NoError(t, err)
+
+		locked <- second
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("the directory was locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, first.sync())
+	first.unlock()
+
+	second := <-locked
+	second.unlock()
+}
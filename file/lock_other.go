@@ -0,0 +1,19 @@
+//go:build !linux
+
+package file
+
+// dirLock is a no-op: directories are only locked, and synced on Linux.
+type dirLock struct{}
+
+// lockDir is a no-op. See the Linux implementation.
+func lockDir(_ string) (*dirLock, error) {
+	return &dirLock{}, nil
+}
+
+// sync is a no-op. See the Linux implementation.
+func (d *dirLock) sync() error {
+	return nil
+}
+
+// unlock is a no-op. See the Linux implementation.
+func (d *dirLock) unlock() {}
@@ -7,6 +7,6 @@ type ResponseListKeys struct {
 
 // CreateAny is a struct for the `create.Create` `Any` field.
 type CreateAny struct {
-	// Create directory and file if it does not exist.
+	// Create the file's directory if it does not exist.
 	CreateIfNotExist bool `default:"true" json:"create_if_not_exist"`
 }
@@ -0,0 +1,159 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// Temporary files are hidden, and suffixed, e.g.: `.data.json.123.tmp`.
+const (
+	tempPrefix = "."
+	tempSuffix = ".tmp"
+)
+
+// pathLock serializes the in-process writers of a path.
+type pathLock struct {
+	sync.Mutex
+
+	// refs is the number of writers holding, or waiting for the lock.
+	refs int
+}
+
+// pathLocks are the locks of the paths being written.
+var pathLocks = struct {
+	sync.Mutex
+
+	m map[string]*pathLock
+}{m: map[string]*pathLock{}}
+
+//////
+// Helpers.
+//////
+
+// lockPath locks `path` against the other writers of the process, returning
+// the unlock function.
+func lockPath(path string) func() {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	pathLocks.Lock()
+
+	l, ok := pathLocks.m[path]
+	if !ok {
+		l = &pathLock{}
+
+		pathLocks.m[path] = l
+	}
+
+	l.refs++
+
+	pathLocks.Unlock()
+
+	l.Lock()
+
+	return func() {
+		l.Unlock()
+
+		pathLocks.Lock()
+
+		l.refs--
+
+		if l.refs == 0 {
+			delete(pathLocks.m, path)
+		}
+
+		pathLocks.Unlock()
+	}
+}
+
+// isTemp reports whether `name` is a temporary file of writeFile.
+func isTemp(name string) bool {
+	name = filepath.Base(name)
+
+	return strings.HasPrefix(name, tempPrefix) && strings.HasSuffix(name, tempSuffix)
+}
+
+// writeFile atomically writes `v` to `path`: it's encoded with `codec` into a
+// temporary file of the same directory, synced, then moved into place, and
+// the directory synced. A crash leaves either the previous, or the new
+// content, never a truncated one.
+//
+// Writers are serialized by a per-path lock in-process, and by an advisory
+// lock of the directory across processes (Linux only).
+//
+// Unless `overwrite`, it fails with `os.ErrExist` if `path` exists. Replaced
+// files keep their permissions.
+func writeFile(path string, v any, codec Codec, overwrite bool) error {
+	unlock := lockPath(path)
+	defer unlock()
+
+	dir := filepath.Dir(path)
+
+	d, err := lockDir(dir)
+	if err != nil {
+		return err
+	}
+	defer d.unlock()
+
+	mode := os.FileMode(0o644)
+
+	if info, err := os.Stat(path); err == nil {
+		if !overwrite {
+			return &os.PathError{Op: "create", Path: path, Err: os.ErrExist}
+		}
+
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(dir, tempPrefix+filepath.Base(path)+".*"+tempSuffix)
+	if err != nil {
+		return err
+	}
+
+	// No-op once renamed, but needed once linked.
+	defer os.Remove(f.Name())
+
+	if err := codec.Encode(f, v); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	// A failed close means the data may not have hit the disk — surface it.
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	// Linking fails if `path` exists - created by another process, in the
+	// meantime -, where renaming replaces it.
+	if overwrite {
+		err = os.Rename(f.Name(), path)
+	} else {
+		err = os.Link(f.Name(), path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return d.sync()
+}
@@ -2,11 +2,100 @@ package customapm
 
 import (
 	"context"
-	"fmt"
+	"sync"
 
+	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/tracing"
+	"github.com/thalesfsp/sypl/v2/fields"
 	"go.elastic.co/apm"
 )
 
+//////
+// Vars, consts, and types.
+//////
+
+// Operation is what the span of a storage operation learns - its database
+// context, and the fields correlating logs with it - for whoever observes the
+// operation once it's done, e.g.: the slow operation log. See `WithOperation`.
+type Operation struct {
+	mu sync.Mutex
+
+	database tracing.Database
+	fields   fields.Fields
+}
+
+// operationKey is the context key of the Operation.
+type operationKey struct{}
+
+// Span wraps the span of a traced operation. See `tracing.ISpan`.
+type Span struct {
+	span tracing.ISpan
+
+	// operation is non-nil if the operation is observed.
+	operation *Operation
+}
+
+//////
+// Methods.
+//////
+
+// Database returns the database context of the operation, e.g.: the
+// statement.
+func (o *Operation) Database() tracing.Database {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.database
+}
+
+// Fields returns a copy of the fields correlating logs with the operation's
+// span.
+func (o *Operation) Fields() fields.Fields {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	f := make(fields.Fields, len(o.fields))
+
+	for k, v := range o.fields {
+		f[k] = v
+	}
+
+	return f
+}
+
+// SetDatabase describes the database operation, e.g.: the statement.
+func (s *Span) SetDatabase(db tracing.Database) {
+	if s == nil || s.span == nil {
+		return
+	}
+
+	s.span.SetDatabase(db)
+
+	if s.operation != nil {
+		s.operation.mu.Lock()
+		s.operation.database.Merge(db)
+		s.operation.mu.Unlock()
+	}
+}
+
+// SetRows sets the number of rows affected, or returned by the operation.
+func (s *Span) SetRows(n int64) {
+	if s == nil || s.span == nil {
+		return
+	}
+
+	s.span.SetRows(n)
+}
+
+// End ends the span, and the transaction if this operation created it.
+func (s *Span) End() {
+	if s == nil || s.span == nil {
+		return
+	}
+
+	s.span.End()
+}
+
 //////
 // Exported functionalities.
 //////
@@ -62,34 +151,9 @@ func TXFromCtx(ctx context.Context, txName string, txType string) *apm.Transacti
 	return tx
 }
 
-// Span wraps the APM span of a traced operation and, when the operation had to
-// start its own transaction (none was found in the incoming context), the
-// transaction as well. End ends both, so implicitly-created transactions are
-// reported and returned to the tracer's pool instead of leaking.
-type Span struct {
-	span *apm.Span
-
-	// tx is non-nil only when Trace created the transaction itself.
-	tx *apm.Transaction
-}
-
-// End ends the span, and the transaction if this operation created it.
-func (s *Span) End() {
-	if s == nil {
-		return
-	}
-
-	if s.span != nil {
-		s.span.End()
-	}
-
-	if s.tx != nil {
-		s.tx.End()
-	}
-}
-
-// Trace will trace an operation. It uses the existing TX otherwise it fallback
-// creating a new TX then it creates a new span within the TX.
+// Trace will trace an operation with the tracer in use (see `tracing.Get`).
+// With Elastic APM, it uses the existing TX otherwise it fallback creating a
+// new TX then it creates a new span within the TX.
 //
 // NOTE: It's up to the developer to call `span.End()`.
 //
@@ -136,26 +200,30 @@ func Trace(
 	ctx context.Context,
 	what, nameOf, operation string,
 ) (context.Context, *Span) {
-	//////
-	// APM.
-	//////
+	ctx, span := tracing.Get().Start(ctx, what, nameOf, operation)
 
-	var createdTX *apm.Transaction
+	s := &Span{span: span}
 
-	tx := apm.TransactionFromContext(ctx)
-	if tx == nil {
-		tx = apm.DefaultTracer.StartTransaction(nameOf, what)
+	if o, ok := ctx.Value(operationKey{}).(*Operation); ok {
+		o.mu.Lock()
+		o.fields = logging.ToAPM(ctx, make(fields.Fields))
+		o.mu.Unlock()
 
-		createdTX = tx
+		s.operation = o
 	}
 
-	ctx = apm.ContextWithTransaction(ctx, tx)
+	s.SetDatabase(tracing.Database{
+		System:    tracing.DBSystem(nameOf),
+		Operation: operation,
+	})
+
+	return ctx, s
+}
 
-	span, ctx := apm.StartSpan(
-		ctx,
-		fmt.Sprintf("%s.%s", nameOf, operation),
-		fmt.Sprintf("%s.%s.%s", what, nameOf, operation),
-	)
+// WithOperation returns a context which collects what the span of the
+// operation started with it learns.
+func WithOperation(ctx context.Context) (context.Context, *Operation) {
+	o := &Operation{}
 
-	return ctx, &Span{span: span, tx: createdTX}
+	return context.WithValue(ctx, operationKey{}, o), o
 }
@@ -6,10 +6,10 @@ import (
 	"expvar"
 
 	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/sypl/v2"
 	"github.com/thalesfsp/sypl/v2/fields"
 	"github.com/thalesfsp/sypl/v2/level"
-	"go.elastic.co/apm"
 )
 
 //////
@@ -36,8 +36,8 @@ func (o Outcome) String() string {
 //////
 
 // TraceError is a helper function to trace an error. It will log the error
-// with the APM fields, and tell APM that it was an error. It will also set
-// the span outcome to failure.
+// with the tracing fields, and tell the tracer (see `tracing.Get`) that it was
+// an error. It will also set the span outcome to failure.
 func TraceError(
 	ctx context.Context,
 	err error,
@@ -57,20 +57,14 @@ func TraceError(
 	}
 
 	//////
-	// APM.
+	// Tracing.
 	//////
 
-	// Get the current span from the context, if any, set the outcome.
-	span := apm.SpanFromContext(ctx)
-	if span != nil {
-		span.Outcome = string(Failure)
-	}
+	tracing.Get().RecordError(ctx, err)
 
 	originalError := err
 
-	// Unwrap any nested errorcatalog. By default, apm.CaptureError() does not
-	// automatically unwrap nested errors or extract any additional context or
-	// metadata from the error.
+	// Logs the innermost error.
 	for {
 		unwrapped := errors.Unwrap(err)
 		if unwrapped == nil {
@@ -80,11 +74,6 @@ func TraceError(
 		err = unwrapped
 	}
 
-	// Tells APM that it that was an error.
-	if apmErr := apm.CaptureError(ctx, err); apmErr != nil {
-		apmErr.Send()
-	}
-
 	//////
 	// Logging
 	//////
@@ -4,13 +4,13 @@ import (
 	"context"
 	"sync"
 
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/sypl/v2"
 	"github.com/thalesfsp/sypl/v2/fields"
 	"github.com/thalesfsp/sypl/v2/formatter"
 	"github.com/thalesfsp/sypl/v2/level"
 	"github.com/thalesfsp/sypl/v2/output"
 	"github.com/thalesfsp/sypl/v2/processor"
-	"go.elastic.co/apm"
 )
 
 //////
@@ -67,25 +67,9 @@ func Get() *Logger {
 	return singletonLogger
 }
 
-// ToAPM adds the required APM fields enabling log correlation.
-//
-// NOTE: It expects the `apm.Transaction` to be in the context.
+// ToAPM adds the fields enabling log correlation with the trace in `ctx`,
+// e.g.: `trace.id`, and `span.id`. They're set by the tracer in use, see
+// `tracing.Get`.
 func ToAPM(ctx context.Context, f fields.Fields) fields.Fields {
-	if f == nil {
-		f = fields.Fields{}
-	}
-
-	tx := apm.TransactionFromContext(ctx)
-	if tx != nil {
-		traceContext := tx.TraceContext()
-
-		f["trace.id"] = traceContext.Trace.String()
-		f["transaction.id"] = traceContext.Span.String()
-
-		if span := apm.SpanFromContext(ctx); span != nil {
-			f["span.id"] = span.TraceContext().Span.String()
-		}
-	}
-
-	return f
+	return tracing.Get().LogFields(ctx, f)
 }
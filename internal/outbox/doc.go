@@ -0,0 +1,2 @@
+// Package outbox implements the transactional outbox of the SQL storages.
+package outbox
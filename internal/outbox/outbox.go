@@ -0,0 +1,257 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// maxErrorLength is the size of the `last_error` column.
+const maxErrorLength = 1024
+
+// Dialect describes how a SQL storage stores its outbox.
+type Dialect struct {
+	// Name of the goqu dialect.
+	Name string
+
+	// Columns of the outbox table, as in `CREATE TABLE t (<Columns>)`. See
+	// `row`.
+	Columns string
+
+	// SkipLocked claims events with `FOR UPDATE SKIP LOCKED`, so relays don't
+	// block each other.
+	SkipLocked bool
+}
+
+// row is an outbox event, as stored. Times are Unix milliseconds, which every
+// driver scans the same way. `processed_at`, and `failed_at` are nullable.
+type row struct {
+	ID          int64  `db:"id"           goqu:"skipinsert"`
+	Operation   string `db:"operation"`
+	Target      string `db:"target"`
+	DocumentID  string `db:"document_id"`
+	Payload     string `db:"payload"`
+	CreatedAt   int64  `db:"created_at"`
+	AvailableAt int64  `db:"available_at"`
+	Attempts    int    `db:"attempts"`
+	LastError   string `db:"last_error"`
+}
+
+//////
+// Helpers.
+//////
+
+// toEvent converts a stored row.
+func (r row) toEvent() *storage.OutboxEvent {
+	event := &storage.OutboxEvent{
+		ID:          r.ID,
+		Operation:   storage.Operation(r.Operation),
+		Target:      r.Target,
+		DocumentID:  r.DocumentID,
+		CreatedAt:   time.UnixMilli(r.CreatedAt),
+		AvailableAt: time.UnixMilli(r.AvailableAt),
+		Attempts:    r.Attempts,
+		LastError:   r.LastError,
+	}
+
+	if r.Payload != "" {
+		event.Payload = []byte(r.Payload)
+	}
+
+	return event
+}
+
+// outcome returns the columns recording the delivery of `event`.
+func outcome(event *storage.OutboxEvent) goqu.Record {
+	lastError := event.LastError
+	if len(lastError) > maxErrorLength {
+		lastError = lastError[:maxErrorLength]
+	}
+
+	record := goqu.Record{
+		"attempts":     event.Attempts,
+		"last_error":   lastError,
+		"available_at": event.AvailableAt.UnixMilli(),
+	}
+
+	if event.ProcessedAt != nil {
+		record["processed_at"] = event.ProcessedAt.UnixMilli()
+	}
+
+	if event.FailedAt != nil {
+		record["failed_at"] = event.FailedAt.UnixMilli()
+	}
+
+	return record
+}
+
+//////
+// Exported functionalities.
+//////
+
+// CreateTable creates the outbox `table`, if it doesn't exist.
+func CreateTable(ctx context.Context, db sqlx.ExecerContext, d Dialect, table string) error {
+	if err := shared.ValidateSQLIdentifier(table); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, d.Columns)); err != nil {
+		return customerror.NewFailedToError("create outbox", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// Begin returns what a mutation should run against: `db` if `table` is empty,
+// otherwise a transaction to `Commit` with the outbox event.
+func Begin(ctx context.Context, db *sqlx.DB, table string) (sqlx.ExtContext, *sqlx.Tx, error) {
+	if table == "" {
+		return db, nil, nil
+	}
+
+	if err := shared.ValidateSQLIdentifier(table); err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, customerror.NewFailedToError("begin outbox transaction", customerror.WithError(err))
+	}
+
+	return tx, tx, nil
+}
+
+// Rollback rolls `tx` back, if any, and not committed.
+func Rollback(tx *sqlx.Tx) {
+	if tx != nil {
+		_ = tx.Rollback()
+	}
+}
+
+// Commit writes the event describing the `operation` of `target`/`id` which
+// wrote `v` to the outbox `table`, then commits `tx`. No-op if `tx` is nil.
+func Commit(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	d Dialect,
+	table string,
+	operation storage.Operation,
+	target, id string,
+	v any,
+) error {
+	if tx == nil {
+		return nil
+	}
+
+	event, err := storage.NewOutboxEvent(operation, target, id, v)
+	if err != nil {
+		return err
+	}
+
+	insertSQL, args, err := goqu.Dialect(d.Name).Insert(table).Rows(row{
+		Operation:   string(event.Operation),
+		Target:      event.Target,
+		DocumentID:  event.DocumentID,
+		Payload:     string(event.Payload),
+		CreatedAt:   event.CreatedAt.UnixMilli(),
+		AvailableAt: event.AvailableAt.UnixMilli(),
+	}).ToSQL()
+	if err != nil {
+		return customerror.NewFailedToError("build outbox event", customerror.WithError(err))
+	}
+
+	if _, err := tx.ExecContext(ctx, insertSQL, args...); err != nil {
+		return customerror.NewFailedToError("write outbox event", customerror.WithError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return customerror.NewFailedToError("commit outbox transaction", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// Relay claims up to `limit` pending events of `table` in a transaction,
+// calls `deliver` for each, persists the outcomes, and commits. Returns the
+// number of claimed events. See `storage.IOutbox`.
+func Relay(
+	ctx context.Context,
+	db *sqlx.DB,
+	d Dialect,
+	table string,
+	limit int,
+	deliver storage.OutboxDeliverFunc,
+) (int, error) {
+	if err := shared.ValidateSQLIdentifier(table); err != nil {
+		return 0, err
+	}
+
+	ds := goqu.Dialect(d.Name).
+		From(table).
+		Select("id", "operation", "target", "document_id", "payload", "created_at", "available_at", "attempts", "last_error").
+		Where(
+			goqu.C("processed_at").IsNull(),
+			goqu.C("failed_at").IsNull(),
+			goqu.C("available_at").Lte(time.Now().UnixMilli()),
+		).
+		Order(goqu.C("id").Asc()).
+		Limit(uint(max(limit, 0)))
+
+	if d.SkipLocked {
+		ds = ds.ForUpdate(exp.SkipLocked)
+	}
+
+	selectSQL, args, err := ds.ToSQL()
+	if err != nil {
+		return 0, customerror.NewFailedToError("build outbox claim", customerror.WithError(err))
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, customerror.NewFailedToError("begin outbox transaction", customerror.WithError(err))
+	}
+
+	defer Rollback(tx)
+
+	rows := []row{}
+
+	if err := tx.SelectContext(ctx, &rows, selectSQL, args...); err != nil {
+		return 0, customerror.NewFailedToError("claim outbox events", customerror.WithError(err))
+	}
+
+	for _, r := range rows {
+		event := r.toEvent()
+
+		deliver(ctx, event)
+
+		updateSQL, args, err := goqu.Dialect(d.Name).
+			Update(table).
+			Set(outcome(event)).
+			Where(goqu.C("id").Eq(event.ID)).
+			ToSQL()
+		if err != nil {
+			return 0, customerror.NewFailedToError("build outbox outcome", customerror.WithError(err))
+		}
+
+		if _, err := tx.ExecContext(ctx, updateSQL, args...); err != nil {
+			return 0, customerror.NewFailedToError("record outbox outcome", customerror.WithError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, customerror.NewFailedToError("commit outbox transaction", customerror.WithError(err))
+	}
+
+	return len(rows), nil
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"io"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -158,3 +159,27 @@ func TargetName(name, alternative string) (string, error) {
 
 	return "", customerror.NewMissingError("target name")
 }
+
+// Rows returns the number of documents in `v`: the length of a slice, or
+// array, zero for nil, otherwise one - e.g.: a struct, or a map. Pointers are
+// dereferenced.
+func Rows(v any) int64 {
+	value := reflect.ValueOf(v)
+
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return 0
+		}
+
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		return 0
+	case reflect.Slice, reflect.Array:
+		return int64(value.Len())
+	default:
+		return 1
+	}
+}
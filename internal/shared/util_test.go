@@ -38,3 +38,30 @@ func TestPrintErrorMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestRows(t *testing.T) {
+	type doc struct{ ID string }
+
+	var nilSlice *[]doc
+
+	tests := []struct {
+		name string
+		v    any
+		want int64
+	}{
+		{name: "nil", v: nil, want: 0},
+		{name: "nil pointer", v: nilSlice, want: 0},
+		{name: "struct", v: &doc{}, want: 1},
+		{name: "map", v: map[string]any{"a": 1, "b": 2}, want: 1},
+		{name: "slice", v: &[]doc{{}, {}, {}}, want: 3},
+		{name: "empty slice", v: []doc{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rows(tt.v); got != tt.want {
+				t.Errorf("Rows() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package memory
+
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// touchStripes is the number of stripes retrievals are buffered in, so
+	// concurrent ones rarely contend.
+	touchStripes = 16
+
+	// touchBatchSize is the number of retrievals a stripe buffers before
+	// they're applied.
+	touchBatchSize = 64
+)
+
+// EvictionPolicy picks the documents evicted to stay within capacity.
+type EvictionPolicy string
+
+// Eviction policies.
+const (
+	// LRU evicts the least recently written, or retrieved document.
+	LRU EvictionPolicy = "lru"
+
+	// LFU evicts the least frequently written, or retrieved document, the
+	// least recently one among equals.
+	LFU EvictionPolicy = "lfu"
+
+	// FIFO evicts the first created document.
+	FIFO EvictionPolicy = "fifo"
+)
+
+// EvictionReason is why a document was evicted.
+type EvictionReason string
+
+// Eviction reasons.
+const (
+	// EvictionReasonExpired is for documents past their TTL.
+	EvictionReasonExpired EvictionReason = "expired"
+
+	// EvictionReasonCapacity is for documents evicted to stay within
+	// capacity.
+	EvictionReasonCapacity EvictionReason = "capacity"
+)
+
+// EvictFunc is called with each evicted document, once evicted.
+type EvictFunc func(target, id string, reason EvictionReason)
+
+// item is a tracked document.
+type item struct {
+	target string
+	id     string
+	entry  *entry
+
+	// hits is the number of writes, and retrievals.
+	hits uint64
+
+	// seq orders items by creation (FIFO), or last use (LRU, LFU).
+	seq uint64
+
+	// index in the heap.
+	index int
+}
+
+// touch is a buffered retrieval.
+type touch struct {
+	target string
+	id     string
+	entry  *entry
+
+	// seq is the sequence number of the retrieval.
+	seq uint64
+}
+
+// touchStripe buffers retrievals.
+type touchStripe struct {
+	mu      sync.Mutex
+	touches []touch
+}
+
+// tracker tracks documents to keep them within capacity. Its mutex must be
+// held while writing documents, so the tracked ones are the stored ones.
+// Retrievals don't take it: they're buffered, and applied in batches, or
+// before evicting.
+type tracker struct {
+	mu sync.Mutex
+
+	policy     EvictionPolicy
+	maxEntries int
+	maxBytes   int64
+
+	bytes int64
+	seq   atomic.Uint64
+	items map[[2]string]*item
+
+	// heap of items, the next to evict first.
+	heap []*item
+
+	// stripes buffer retrievals, round-robin.
+	stripes [touchStripes]touchStripe
+}
+
+//////
+// Helpers.
+//////
+
+// Len is the heap.Interface implementation.
+func (t *tracker) Len() int {
+	return len(t.heap)
+}
+
+// Less is the heap.Interface implementation.
+func (t *tracker) Less(i, j int) bool {
+	a, b := t.heap[i], t.heap[j]
+
+	if t.policy == LFU && a.hits != b.hits {
+		return a.hits < b.hits
+	}
+
+	return a.seq < b.seq
+}
+
+// Swap is the heap.Interface implementation.
+func (t *tracker) Swap(i, j int) {
+	t.heap[i], t.heap[j] = t.heap[j], t.heap[i]
+	t.heap[i].index = i
+	t.heap[j].index = j
+}
+
+// Push is the heap.Interface implementation.
+func (t *tracker) Push(x any) {
+	it := x.(*item)
+	it.index = len(t.heap)
+
+	t.heap = append(t.heap, it)
+}
+
+// Pop is the heap.Interface implementation.
+func (t *tracker) Pop() any {
+	n := len(t.heap) - 1
+	it := t.heap[n]
+
+	t.heap[n] = nil
+	t.heap = t.heap[:n]
+
+	return it
+}
+
+// next returns the next sequence number.
+func (t *tracker) next() uint64 {
+	return t.seq.Add(1)
+}
+
+// over reports whether the tracked documents exceed capacity.
+func (t *tracker) over() bool {
+	return (t.maxEntries > 0 && len(t.items) > t.maxEntries) || (t.maxBytes > 0 && t.bytes > t.maxBytes)
+}
+
+// put tracks `e`, stored as `id` of `target`, and untracks the documents to
+// evict to stay within capacity, which it returns. `e` isn't one of them.
+func (t *tracker) put(target, id string, e *entry) []*item {
+	t.flush()
+
+	key := [2]string{target, id}
+
+	if it, ok := t.items[key]; ok {
+		t.bytes += int64(len(e.data) - len(it.entry.data))
+
+		it.entry = e
+		it.hits++
+
+		if t.policy != FIFO {
+			it.seq = t.next()
+		}
+
+		heap.Fix(t, it.index)
+	} else {
+		it = &item{target: target, id: id, entry: e, hits: 1, seq: t.next()}
+
+		t.items[key] = it
+		t.bytes += int64(len(e.data))
+
+		heap.Push(t, it)
+	}
+
+	victims := []*item{}
+
+	var current *item
+
+	for t.over() && t.Len() > 0 {
+		it := heap.Pop(t).(*item)
+
+		if it.entry == e {
+			current = it
+
+			continue
+		}
+
+		delete(t.items, [2]string{it.target, it.id})
+
+		t.bytes -= int64(len(it.entry.data))
+
+		victims = append(victims, it)
+	}
+
+	if current != nil {
+		heap.Push(t, current)
+	}
+
+	return victims
+}
+
+// touch buffers a retrieval of `e`, stored as `id` of `target`, applying the
+// stripe's retrievals once it's full. Must be called without `mu` held.
+func (t *tracker) touch(target, id string, e *entry) {
+	seq := t.next()
+	stripe := &t.stripes[seq%touchStripes]
+
+	stripe.mu.Lock()
+
+	stripe.touches = append(stripe.touches, touch{target: target, id: id, entry: e, seq: seq})
+
+	if len(stripe.touches) < touchBatchSize {
+		stripe.mu.Unlock()
+
+		return
+	}
+
+	touches := stripe.touches
+	stripe.touches = make([]touch, 0, touchBatchSize)
+
+	stripe.mu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.apply(touches)
+}
+
+// apply applies buffered retrievals. Must be called with `mu` held.
+func (t *tracker) apply(touches []touch) {
+	for _, tc := range touches {
+		it, ok := t.items[[2]string{tc.target, tc.id}]
+		if !ok || it.entry != tc.entry {
+			continue
+		}
+
+		it.hits++
+
+		// Retrievals are applied out of order, keep the latest use.
+		if t.policy != FIFO && tc.seq > it.seq {
+			it.seq = tc.seq
+		}
+
+		heap.Fix(t, it.index)
+	}
+}
+
+// flush applies every buffered retrieval. Must be called with `mu` held.
+func (t *tracker) flush() {
+	for i := range t.stripes {
+		stripe := &t.stripes[i]
+
+		stripe.mu.Lock()
+
+		touches := stripe.touches
+		stripe.touches = nil
+
+		stripe.mu.Unlock()
+
+		t.apply(touches)
+	}
+}
+
+// remove untracks `id` of `target`.
+func (t *tracker) remove(target, id string) {
+	key := [2]string{target, id}
+
+	it, ok := t.items[key]
+	if !ok {
+		return
+	}
+
+	heap.Remove(t, it.index)
+
+	delete(t.items, key)
+
+	t.bytes -= int64(len(it.entry.data))
+}
+
+// removeTarget untracks every document of `target`.
+func (t *tracker) removeTarget(target string) {
+	for key := range t.items {
+		if key[0] == target {
+			t.remove(key[0], key[1])
+		}
+	}
+}
+
+// reset untracks every document.
+func (t *tracker) reset() {
+	t.bytes = 0
+	t.items = map[[2]string]*item{}
+	t.heap = nil
+}
+
+//////
+// Factory.
+//////
+
+// newTracker returns a new tracker, or nil if capacity is unlimited.
+func newTracker(cfg *Config) *tracker {
+	if cfg.MaxEntries == 0 && cfg.MaxBytes == 0 {
+		return nil
+	}
+
+	return &tracker{
+		policy:     cfg.EvictionPolicy,
+		maxEntries: cfg.MaxEntries,
+		maxBytes:   cfg.MaxBytes,
+		items:      map[[2]string]*item{},
+	}
+}
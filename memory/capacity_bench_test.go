@@ -0,0 +1,11 @@
+package memory
+
+// The capacity configurations also benchmarked: the 1000 documents of
+// `benchmarkStorage` fit, more are evicted.
+func init() {
+	benchmarkConfigs = append(benchmarkConfigs,
+		benchmarkConfig{name: "LRU", cfg: &Config{MaxEntries: 1000, EvictionPolicy: LRU}},
+		benchmarkConfig{name: "LFU", cfg: &Config{MaxEntries: 1000, EvictionPolicy: LFU}},
+		benchmarkConfig{name: "FIFO", cfg: &Config{MaxEntries: 1000, EvictionPolicy: FIFO}},
+	)
+}
@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+type evictions struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (e *evictions) record(target, id string, reason EvictionReason) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.ids = append(e.ids, fmt.Sprintf("%s/%s:%s", target, id, reason))
+}
+
+// newCapacityStorage returns a storage with `cfg`, closed, and whose counters,
+// shared by name, are reset once the test is done, not to skew other tests'.
+func newCapacityStorage(t *testing.T, cfg *Config) *Memory {
+	t.Helper()
+
+	str, err := NewWithConfig(t.Context(), cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = str.Close()
+
+		storage.ResetStats(str)
+	})
+
+	return str
+}
+
+func TestMemory_Capacity(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy EvictionPolicy
+		want   []string
+	}{
+		{
+			name:   "Should evict the least recently used",
+			policy: LRU,
+			want:   []string{"users/2:capacity"},
+		},
+		{
+			name:   "Should evict the least frequently used",
+			policy: LFU,
+			want:   []string{"users/3:capacity"},
+		},
+		{
+			name:   "Should evict the first in",
+			policy: FIFO,
+			want:   []string{"users/1:capacity"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			evicted := &evictions{}
+
+			str := newCapacityStorage(t, &Config{MaxEntries: 3, EvictionPolicy: tt.policy, OnEvict: evicted.record})
+
+			before := str.GetCounterCapacityEvicted().Value()
+
+			for _, id := range []string{"1", "2", "3"} {
+				_, err := str.Create(ctx, id, "users", map[string]any{"id": id}, nil)
+				require.NoError(t, err)
+			}
+
+			var got map[string]any
+
+			// 2 is the most frequently used, but the least recently one, 3
+			// the least recently used among the least frequently used.
+			for _, id := range []string{"2", "2", "3", "1"} {
+				require.NoError(t, str.Retrieve(ctx, id, "users", &got, nil))
+			}
+
+			_, err := str.Create(ctx, "4", "users", map[string]any{"id": "4"}, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, evicted.ids)
+			assert.Equal(t, before+1, str.GetCounterCapacityEvicted().Value())
+
+			c, err := str.Count(ctx, "users", nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), c)
+
+			require.NoError(t, str.Retrieve(ctx, "4", "users", &got, nil), "the new document is never evicted")
+		})
+	}
+}
+
+func TestMemory_CapacityBytes(t *testing.T) {
+	ctx := t.Context()
+	evicted := &evictions{}
+
+	// Documents are `"xxxx"`: 6 bytes.
+	str := newCapacityStorage(t, &Config{MaxBytes: 14, OnEvict: evicted.record})
+
+	_, err := str.Create(ctx, "1", "a", "xxxx", nil)
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "1", "b", "xxxx", nil)
+	require.NoError(t, err)
+
+	// Growing a document evicts others.
+	require.NoError(t, str.Update(ctx, "1", "b", "xxxxxxxx", nil))
+
+	assert.Equal(t, []string{"a/1:capacity"}, evicted.ids)
+
+	_, err = str.Create(ctx, "2", "a", "xxxxxxxxxxxxxxxxx", nil)
+	assert.Error(t, err, "documents larger than capacity are rejected")
+
+	// Deleted, and dropped documents free capacity.
+	require.NoError(t, str.Delete(ctx, "1", "b", nil))
+
+	_, err = str.Create(ctx, "1", "a", "xxxx", nil)
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "2", "a", "xxxx", nil)
+	require.NoError(t, err)
+
+	str.DropTarget("a")
+
+	_, err = str.Create(ctx, "1", "c", "xxxx", nil)
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "2", "c", "xxxx", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a/1:capacity"}, evicted.ids)
+
+	// Restores are trimmed to capacity.
+	var buf bytes.Buffer
+	require.NoError(t, str.Snapshot(&buf))
+
+	small := newCapacityStorage(t, &Config{MaxEntries: 1})
+
+	require.NoError(t, small.Restore(&buf))
+
+	c, err := small.Count(ctx, "c", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	_, err = NewWithConfig(ctx, &Config{MaxEntries: 1, EvictionPolicy: "random"})
+	assert.Error(t, err)
+}
+
+// Concurrent writes stay within capacity (meaningful under -race).
+func TestMemory_CapacityConcurrency(t *testing.T) {
+	ctx := t.Context()
+
+	str := newCapacityStorage(t, &Config{MaxEntries: 10, EvictionPolicy: LFU})
+
+	var wg sync.WaitGroup
+
+	for w := range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range 100 {
+				id := fmt.Sprintf("%d", (w*100+i)%25)
+
+				_, _ = str.Create(ctx, id, "users", map[string]any{"i": i}, nil, storage.WithOverwrite())
+
+				var got map[string]any
+
+				_ = str.Retrieve(ctx, id, "users", &got, nil)
+				_ = str.Delete(ctx, fmt.Sprintf("%d", i%25), "users", nil)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	c, err := str.Count(ctx, "users", nil)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, c, int64(10))
+
+	str.tracker.mu.Lock()
+	defer str.tracker.mu.Unlock()
+
+	assert.Equal(t, int(c), len(str.tracker.items), "tracked documents are the stored ones")
+}
+
+func TestTracker_BatchedTouches(t *testing.T) {
+	for _, last := range []string{"a", "b"} {
+		t.Run(last, func(t *testing.T) {
+			tr := newTracker(&Config{MaxEntries: 2, EvictionPolicy: LRU})
+
+			entries := map[string]*entry{"a": {data: []byte(`"a"`)}, "b": {data: []byte(`"b"`)}}
+
+			for _, id := range []string{"a", "b"} {
+				require.Empty(t, tr.put("t", id, entries[id]))
+			}
+
+			other := "a"
+			if last == "a" {
+				other = "b"
+			}
+
+			// Enough retrievals of `other` to fill, and apply, several
+			// stripes, then one of `last`, still buffered.
+			for range touchStripes * touchBatchSize {
+				tr.touch("t", other, entries[other])
+			}
+
+			tr.touch("t", last, entries[last])
+
+			tr.mu.Lock()
+			victims := tr.put("t", "c", &entry{data: []byte(`"c"`)})
+			tr.mu.Unlock()
+
+			require.Len(t, victims, 1)
+			assert.Equal(t, other, victims[0].id)
+		})
+	}
+}
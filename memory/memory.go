@@ -1,15 +1,22 @@
 package memory
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
 	"fmt"
+	"io/fs"
 	"path"
-	"strings"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/thalesfsp/customerror"
 	"github.com/thalesfsp/dal/v2/internal/customapm"
 	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
 	"github.com/thalesfsp/params/v2/count"
@@ -29,8 +36,14 @@ import (
 // Const, vars, and types.
 //////
 
-// Name of the storage.
-const Name = "memory"
+const (
+	// Name of the storage.
+	Name = "memory"
+
+	// DefaultJanitorInterval is the default interval at which expired
+	// documents are evicted.
+	DefaultJanitorInterval = time.Minute
+)
 
 // Singleton.
 var (
@@ -38,26 +51,319 @@ var (
 	singletonMutex sync.RWMutex
 )
 
+// Config is the Memory configuration.
+type Config struct {
+	// Clock returns the current time, used to expire documents. Default is
+	// `time.Now`. Tests can inject a fake one.
+	Clock func() time.Time `json:"-"`
+
+	// JanitorInterval is the interval at which expired documents are evicted.
+	// Default is `DefaultJanitorInterval`.
+	JanitorInterval time.Duration `json:"janitorInterval" validate:"gte=0"`
+
+	// SnapshotPath is the file documents are restored from by `New`, if it
+	// exists, and snapshotted to every `SnapshotInterval`, and on `Close`.
+	// Snapshots are disabled if empty.
+	SnapshotPath string `json:"snapshotPath"`
+
+	// SnapshotInterval is the interval of periodic snapshots to
+	// `SnapshotPath`. Disabled if zero.
+	SnapshotInterval time.Duration `json:"snapshotInterval" validate:"gte=0"`
+
+	// MaxEntries caps the number of documents, across targets. Unlimited if
+	// zero.
+	MaxEntries int `json:"maxEntries" validate:"gte=0"`
+
+	// MaxBytes caps the total size of documents, as JSON, across targets.
+	// Unlimited if zero. Larger documents are rejected.
+	MaxBytes int64 `json:"maxBytes" validate:"gte=0"`
+
+	// EvictionPolicy picks the documents evicted to stay within capacity.
+	// Default is `LRU`.
+	EvictionPolicy EvictionPolicy `json:"evictionPolicy" validate:"oneof=lru lfu fifo"`
+
+	// OnEvict, if set, is called with each evicted document - expired, or to
+	// stay within capacity -, once evicted.
+	OnEvict EvictFunc `json:"-"`
+}
+
+// entry is a stored document.
+type entry struct {
+	// data is the document, as JSON.
+	data []byte
+
+	// expiresAt is when the document expires. Zero if it doesn't.
+	expiresAt time.Time
+}
+
 // Memory storage definition.
 type Memory struct {
 	*storage.Storage
 
+	// Config is the Memory configuration.
+	Config *Config `json:"-" validate:"required"`
+
+	// client holds the documents of each target (`*sync.Map` of ID to
+	// document), by target.
 	client *sync.Map
 
+	// mu is read-locked by writers, and locked by snapshots and restores, so
+	// they see, and replace, a consistent state.
+	mu sync.RWMutex
+
+	// tracker keeps documents within capacity. Nil if unlimited.
+	tracker *tracker
+
+	// done stops the janitor, and the snapshotter once closed. See Close.
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// snapshotterWG tracks the snapshotter, so Close's snapshot is the last.
+	snapshotterWG sync.WaitGroup
+
+	// Metrics.
+	counterEvicted         *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterCapacityEvicted *expvar.Int `json:"-" validate:"required,gte=0"`
+
+	// broadcaster streams changes to watchers.
+	broadcaster *storage.Broadcaster
+
 	// Target allows to set a static target. If it is empty, the target will be
 	// dynamic - the one set at the operation (count, create, delete, etc) time.
 	// Depending on the storage, target is a collection, a table, a bucket, etc.
 	// For ElasticSearch, for example it doesn't have a concept of a database -
 	// the target then is the index. Due to different cases of ElasticSearch
 	// usage, the target can be static or dynamic - defined at the index time,
-	// for example: log-{YYYY}-{MM}. For Memory, it isn't used at all.
+	// for example: log-{YYYY}-{MM}. For Memory, targets are namespaces: the
+	// same ID can exist in different targets. If both are empty, documents go
+	// to the unnamed target.
 	Target string `json:"-" validate:"omitempty,gt=0"`
 }
 
 //////
-// Implements the IStorage interface.
+// Helpers.
 //////
 
+// expired reports whether the document is expired at `now`.
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// newEntry returns the document `b`, expiring after `ttl`, if set.
+func (s *Memory) newEntry(b []byte, ttl time.Duration) *entry {
+	e := &entry{data: b}
+
+	if ttl > 0 {
+		e.expiresAt = s.Config.Clock().Add(ttl)
+	}
+
+	return e
+}
+
+// lockCapacity locks the tracker, if capacity is limited.
+func (s *Memory) lockCapacity() {
+	if s.tracker != nil {
+		s.tracker.mu.Lock()
+	}
+}
+
+// unlockCapacity unlocks the tracker, if capacity is limited.
+func (s *Memory) unlockCapacity() {
+	if s.tracker != nil {
+		s.tracker.mu.Unlock()
+	}
+}
+
+// write stores `e` as `id` of `target` with `store`, which reports whether it
+// did, then, if capacity is limited, tracks it, and evicts documents to stay
+// within capacity.
+func (s *Memory) write(target, id string, e *entry, store func(documents *sync.Map) bool) bool {
+	victims := []*item{}
+
+	s.mu.RLock()
+	s.lockCapacity()
+
+	stored := store(s.documents(target, true))
+
+	if stored && s.tracker != nil {
+		victims = s.tracker.put(target, id, e)
+
+		for _, victim := range victims {
+			s.documents(victim.target, false).CompareAndDelete(victim.id, victim.entry)
+		}
+	}
+
+	s.unlockCapacity()
+	s.mu.RUnlock()
+
+	for _, victim := range victims {
+		s.evicted(victim.target, victim.id, EvictionReasonCapacity)
+	}
+
+	return stored
+}
+
+// remove deletes `id` of `target` from `documents` - only if it's still `e`,
+// if set. Returns whether it did.
+func (s *Memory) remove(documents *sync.Map, target, id string, e *entry) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.lockCapacity()
+	defer s.unlockCapacity()
+
+	var removed bool
+
+	if e == nil {
+		_, removed = documents.LoadAndDelete(id)
+	} else {
+		removed = documents.CompareAndDelete(id, e)
+	}
+
+	if removed && s.tracker != nil {
+		s.tracker.remove(target, id)
+	}
+
+	return removed
+}
+
+// touch records a retrieval of `e`, stored as `id` of `target`, if capacity
+// is limited.
+func (s *Memory) touch(target, id string, e *entry) {
+	if s.tracker != nil {
+		s.tracker.touch(target, id, e)
+	}
+}
+
+// evicted counts the eviction of `id` of `target`, calls `OnEvict`, and
+// notifies watchers.
+func (s *Memory) evicted(target, id string, reason EvictionReason) {
+	if reason == EvictionReasonExpired {
+		s.counterEvicted.Add(1)
+	} else {
+		s.counterCapacityEvicted.Add(1)
+	}
+
+	if s.Config.OnEvict != nil {
+		s.Config.OnEvict(target, id, reason)
+	}
+
+	s.publish(storage.OperationDelete, id, target, nil)
+}
+
+// evict deletes `e`, stored as `id` of `target`, unless it was replaced in
+// the meantime. Returns whether it did.
+func (s *Memory) evict(documents *sync.Map, target, id string, e *entry) bool {
+	if !s.remove(documents, target, id, e) {
+		return false
+	}
+
+	s.evicted(target, id, EvictionReasonExpired)
+
+	return true
+}
+
+// fits checks that `b` fits within capacity.
+func (s *Memory) fits(b []byte) error {
+	if s.Config.MaxBytes > 0 && int64(len(b)) > s.Config.MaxBytes {
+		return customerror.NewInvalidError(
+			fmt.Sprintf("document size, %d bytes exceeds the capacity of %d", len(b), s.Config.MaxBytes),
+		)
+	}
+
+	return nil
+}
+
+// expire evicts `e`, stored as `id` of `target`, if it's expired. Returns
+// whether it is.
+func (s *Memory) expire(documents *sync.Map, target, id string, e *entry) bool {
+	if !e.expired(s.Config.Clock()) {
+		return false
+	}
+
+	s.evict(documents, target, id, e)
+
+	return true
+}
+
+// insert stores `e` as `id` in `documents`, unless a live document exists, in
+// which case it returns false. An expired one is replaced, and returned.
+func (s *Memory) insert(documents *sync.Map, id string, e *entry) (bool, *entry) {
+	for {
+		existing, loaded := documents.LoadOrStore(id, e)
+		if !loaded {
+			return true, nil
+		}
+
+		current, ok := existing.(*entry)
+		if !ok || !current.expired(s.Config.Clock()) {
+			return false, nil
+		}
+
+		if documents.CompareAndSwap(id, current, e) {
+			return true, current
+		}
+	}
+}
+
+// janitor evicts expired documents every `JanitorInterval`, until closed.
+func (s *Memory) janitor() {
+	ticker := time.NewTicker(s.Config.JanitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.EvictExpired()
+		}
+	}
+}
+
+// snapshotter snapshots to `SnapshotPath` every `SnapshotInterval`, until
+// closed. Failures are logged, and retried on the next tick.
+func (s *Memory) snapshotter() {
+	ticker := time.NewTicker(s.Config.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.SnapshotToFile(s.Config.SnapshotPath); err != nil {
+				s.GetLogger().Errorln("failed to snapshot:", err)
+			}
+		}
+	}
+}
+
+// targetName returns the provided target name, or the configured one.
+func (s *Memory) targetName(target string) string {
+	if target != "" {
+		return target
+	}
+
+	return s.Target
+}
+
+// documents returns the documents of `target`. If it has none, they're
+// created if `create`, otherwise an empty, detached map is returned.
+func (s *Memory) documents(target string, create bool) *sync.Map {
+	if documents, ok := s.client.Load(target); ok {
+		return documents.(*sync.Map)
+	}
+
+	if !create {
+		return &sync.Map{}
+	}
+
+	documents, _ := s.client.LoadOrStore(target, &sync.Map{})
+
+	return documents.(*sync.Map)
+}
+
 // keyMatches reports whether the stored key matches the glob pattern. Keys
 // that aren't strings never match. A malformed pattern returns an error.
 func keyMatches(pattern string, key interface{}) (bool, error) {
@@ -79,6 +385,15 @@ func keyMatches(pattern string, key interface{}) (bool, error) {
 
 // Count returns the number of items in the storage.
 func (s *Memory) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, s, storage.OperationCount, target, "")
+
+	c, err := s.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, nil)
+}
+
+// count is Count's implementation. See Count.
+func (s *Memory) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -123,6 +438,12 @@ func (s *Memory) Count(ctx context.Context, target string, prm *count.Count, opt
 		finalParam = prm
 	}
 
+	//////
+	// Target definition.
+	//////
+
+	trgt := s.targetName(target)
+
 	//////
 	// Count.
 	//////
@@ -142,7 +463,9 @@ func (s *Memory) Count(ctx context.Context, target string, prm *count.Count, opt
 
 	var matchErr error
 
-	s.client.Range(func(key, value interface{}) bool {
+	documents := s.documents(trgt, false)
+
+	documents.Range(func(key, value interface{}) bool {
 		matched, err := keyMatches(pattern, key)
 		if err != nil {
 			matchErr = err
@@ -150,7 +473,7 @@ func (s *Memory) Count(ctx context.Context, target string, prm *count.Count, opt
 			return false
 		}
 
-		if matched {
+		if e, ok := value.(*entry); ok && matched && !s.expire(documents, trgt, key.(string), e) {
 			count++
 		}
 
@@ -189,6 +512,13 @@ func (s *Memory) Count(ctx context.Context, target string, prm *count.Count, opt
 
 // Delete removes data.
 func (s *Memory) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationDelete, target, id)
+
+	return o.Done(nil, s.delete(ctx, id, target, prm, options...), nil)
+}
+
+// delete is Delete's implementation. See Delete.
+func (s *Memory) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -226,6 +556,10 @@ func (s *Memory) Delete(ctx context.Context, id, target string, prm *delete.Dele
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -240,6 +574,12 @@ func (s *Memory) Delete(ctx context.Context, id, target string, prm *delete.Dele
 		finalParam = prm
 	}
 
+	//////
+	// Target definition.
+	//////
+
+	trgt := s.targetName(target)
+
 	//////
 	// Delete.
 	//////
@@ -250,7 +590,14 @@ func (s *Memory) Delete(ctx context.Context, id, target string, prm *delete.Dele
 		}
 	}
 
-	s.client.Delete(id)
+	// Deleting nothing isn't a mutation: no event.
+	if s.remove(s.documents(trgt, false), trgt, id, nil) {
+		span.SetRows(1)
+
+		s.publish(storage.OperationDelete, id, trgt, nil)
+	} else {
+		span.SetRows(0)
+	}
 
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, nil, finalParam); err != nil {
@@ -280,6 +627,13 @@ func (s *Memory) Delete(ctx context.Context, id, target string, prm *delete.Dele
 
 // Retrieve data.
 func (s *Memory) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, s.retrieve(ctx, id, target, v, prm, options...), nil)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (s *Memory) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -330,6 +684,12 @@ func (s *Memory) Retrieve(ctx context.Context, id, target string, v any, prm *re
 		finalParam = prm
 	}
 
+	//////
+	// Target definition.
+	//////
+
+	trgt := s.targetName(target)
+
 	//////
 	// Retrieve.
 	//////
@@ -341,7 +701,9 @@ func (s *Memory) Retrieve(ctx context.Context, id, target string, v any, prm *re
 	}
 
 	// Retrieve a value
-	val, ok := s.client.Load(id)
+	documents := s.documents(trgt, false)
+
+	val, ok := documents.Load(id)
 	if !ok {
 		return customapm.TraceError(
 			ctx,
@@ -351,20 +713,31 @@ func (s *Memory) Retrieve(ctx context.Context, id, target string, v any, prm *re
 		)
 	}
 
-	b, ok := val.([]byte)
+	e, ok := val.(*entry)
 	if !ok {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(
 				storage.OperationRetrieve.String(),
-				customerror.WithError(fmt.Errorf("stored value for id %q is %T, not []byte", id, val)),
+				customerror.WithError(fmt.Errorf("stored value for id %q is %T, not a document", id, val)),
 			),
 			s.GetLogger(),
 			s.GetCounterRetrievedFailed(),
 		)
 	}
 
-	if err := shared.Unmarshal(b, v); err != nil {
+	if s.expire(documents, trgt, id, e) {
+		return customapm.TraceError(
+			ctx,
+			customerror.NewNotFoundError(storage.OperationRetrieve.String()),
+			s.GetLogger(),
+			s.GetCounterRetrievedFailed(),
+		)
+	}
+
+	s.touch(trgt, id, e)
+
+	if err := shared.Unmarshal(e.data, v); err != nil {
 		return customapm.TraceError(
 			ctx,
 			err,
@@ -373,6 +746,8 @@ func (s *Memory) Retrieve(ctx context.Context, id, target string, v any, prm *re
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
@@ -401,11 +776,18 @@ func (s *Memory) Retrieve(ctx context.Context, id, target string, v any, prm *re
 
 // List data.
 //
-// NOTE: It uses params.List.Search to query the data.
-//
-// NOTE: Memory does not support the concept of "offset" and "limit" in the same
-// way that a traditional SQL database does.
+// NOTE: It uses params.List.Search to glob-match IDs, then evaluates the rest
+// of the query against the documents: filters (`ListAny`, as `Any`), sort,
+// offset, limit - unlimited by default -, and fields. Without sort, documents
+// are sorted by ID.
 func (s *Memory) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationList, target, "")
+
+	return o.Done(v, s.list(ctx, target, v, prm, options...), nil)
+}
+
+// list is List's implementation. See List.
+func (s *Memory) list(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -445,11 +827,23 @@ func (s *Memory) List(ctx context.Context, target string, v any, prm *list.List,
 
 	// Application's default values.
 	finalParam.Search = "*"
+	finalParam.Limit = 0
 
 	if prm != nil {
 		finalParam = prm
 	}
 
+	q, err := newQuery(finalParam)
+	if err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
+	}
+
+	//////
+	// Target definition.
+	//////
+
+	trgt := s.targetName(target)
+
 	//////
 	// Query.
 	//////
@@ -465,11 +859,14 @@ func (s *Memory) List(ctx context.Context, target string, v any, prm *list.List,
 		pattern = "*"
 	}
 
-	items := `{"items":[`
+	var (
+		found    []document
+		matchErr error
+	)
 
-	var matchErr error
+	documents := s.documents(trgt, false)
 
-	s.client.Range(func(key, value interface{}) bool {
+	documents.Range(func(key, value interface{}) bool {
 		matched, err := keyMatches(pattern, key)
 		if err != nil {
 			matchErr = err
@@ -481,10 +878,20 @@ func (s *Memory) List(ctx context.Context, target string, v any, prm *list.List,
 			return true
 		}
 
-		if b, ok := value.([]byte); ok {
-			items += string(b) + ","
+		e, ok := value.(*entry)
+		if !ok || s.expire(documents, trgt, key.(string), e) {
+			return true
+		}
+
+		d := document{id: key.(string), data: e.data}
+
+		if q.decodes() {
+			// Documents which aren't JSON never match fields.
+			_ = json.Unmarshal(e.data, &d.value)
 		}
 
+		found = append(found, d)
+
 		return true
 	})
 
@@ -492,15 +899,19 @@ func (s *Memory) List(ctx context.Context, target string, v any, prm *list.List,
 		return customapm.TraceError(ctx, matchErr, s.GetLogger(), s.GetCounterListedFailed())
 	}
 
-	// Remove the last comma.
-	items = strings.TrimSuffix(items, ",")
+	results, err := q.run(found)
+	if err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
+	}
 
-	items += `]}`
+	items := `{"items":[` + string(bytes.Join(results, []byte(","))) + `]}`
 
 	if err := shared.Unmarshal([]byte(items), v); err != nil {
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
 	}
 
+	span.SetRows(int64(len(results)))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, "", target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
@@ -529,9 +940,24 @@ func (s *Memory) List(ctx context.Context, target string, v any, prm *list.List,
 
 // Create data.
 //
+// NOTE: It's insert-only (`LoadOrStore`), failing with
+// `storage.ErrAlreadyExists` if the key exists. Use `storage.WithOverwrite` to
+// replace it. An expired document counts as missing.
+//
+// NOTE: The document expires after `prm.TTL`, if set.
+//
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set. You are better off setting the ID yourself.
 func (s *Memory) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, s, storage.OperationCreate, target, id)
+
+	createdID, err := s.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, nil)
+}
+
+// create is Create's implementation. See Create.
+func (s *Memory) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	// The id is the storage key — an empty one would create a record that
 	// Retrieve/Delete/Update (which reject empty ids) could never address.
 	if id == "" {
@@ -571,6 +997,10 @@ func (s *Memory) Create(ctx context.Context, id, target string, v any, prm *crea
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -580,12 +1010,16 @@ func (s *Memory) Create(ctx context.Context, id, target string, v any, prm *crea
 		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
 	}
 
-	finalParam.TTL = 0
-
 	if prm != nil {
 		finalParam = prm
 	}
 
+	//////
+	// Target definition.
+	//////
+
+	trgt := s.targetName(target)
+
 	//////
 	// Create.
 	//////
@@ -601,7 +1035,47 @@ func (s *Memory) Create(ctx context.Context, id, target string, v any, prm *crea
 		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
 	}
 
-	s.client.Store(id, b)
+	if err := s.fits(b); err != nil {
+		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
+	}
+
+	e := s.newEntry(b, finalParam.TTL)
+
+	var replaced *entry
+
+	inserted := s.write(trgt, id, e, func(documents *sync.Map) bool {
+		if o.Overwrite {
+			documents.Store(id, e)
+
+			return true
+		}
+
+		inserted, expired := s.insert(documents, id, e)
+
+		replaced = expired
+
+		return inserted
+	})
+
+	if replaced != nil {
+		s.evicted(trgt, id, EvictionReasonExpired)
+	}
+
+	if !inserted {
+		return "", customapm.TraceError(
+			ctx,
+			customerror.NewFailedToError(
+				storage.OperationCreate.String(),
+				customerror.WithError(storage.ErrAlreadyExists),
+			),
+			s.GetLogger(),
+			s.GetCounterCreatedFailed(),
+		)
+	}
+
+	span.SetRows(1)
+
+	s.publish(storage.OperationCreate, id, trgt, b)
 
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
@@ -631,8 +1105,16 @@ func (s *Memory) Create(ctx context.Context, id, target string, v any, prm *crea
 
 // Update data.
 //
-// NOTE: Not truly an update, it's an insert.
+// NOTE: Not truly an update, it's an insert. Like Redis' `SET`, it resets the
+// expiry: the document expires after `prm.TTL`, if set, otherwise never.
 func (s *Memory) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationUpdate, target, id)
+
+	return o.Done(v, s.update(ctx, id, target, v, prm, options...), nil)
+}
+
+// update is Update's implementation. See Update.
+func (s *Memory) update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -670,6 +1152,10 @@ func (s *Memory) Update(ctx context.Context, id, target string, v any, prm *upda
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -679,12 +1165,16 @@ func (s *Memory) Update(ctx context.Context, id, target string, v any, prm *upda
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
 	}
 
-	finalParam.TTL = 0
-
 	if prm != nil {
 		finalParam = prm
 	}
 
+	//////
+	// Target definition.
+	//////
+
+	trgt := s.targetName(target)
+
 	//////
 	// Update.
 	//////
@@ -700,7 +1190,21 @@ func (s *Memory) Update(ctx context.Context, id, target string, v any, prm *upda
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
 	}
 
-	s.client.Store(id, b)
+	if err := s.fits(b); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
+	}
+
+	e := s.newEntry(b, finalParam.TTL)
+
+	s.write(trgt, id, e, func(documents *sync.Map) bool {
+		documents.Store(id, e)
+
+		return true
+	})
+
+	span.SetRows(1)
+
+	s.publish(storage.OperationUpdate, id, trgt, b)
 
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
@@ -728,29 +1232,207 @@ func (s *Memory) Update(ctx context.Context, id, target string, v any, prm *upda
 	return nil
 }
 
-// GetClient returns the client.
+// GetClient returns the client: a `*sync.Map` of the documents of each
+// target, by target.
 func (s *Memory) GetClient() any {
 	return s.client
 }
 
+// ListTargets returns the targets written to, sorted, including emptied ones,
+// like tables which outlive their rows. Dropped targets are excluded.
+func (s *Memory) ListTargets() []string {
+	targets := []string{}
+
+	s.client.Range(func(key, _ any) bool {
+		targets = append(targets, key.(string))
+
+		return true
+	})
+
+	slices.Sort(targets)
+
+	return targets
+}
+
+// DropTarget deletes `target`, and all its documents - watchers receive a
+// deletion for each. Dropping a missing target is a no-op.
+func (s *Memory) DropTarget(target string) {
+	trgt := s.targetName(target)
+
+	s.mu.RLock()
+	s.lockCapacity()
+
+	documents, ok := s.client.LoadAndDelete(trgt)
+
+	if ok && s.tracker != nil {
+		s.tracker.removeTarget(trgt)
+	}
+
+	s.unlockCapacity()
+	s.mu.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	documents.(*sync.Map).Range(func(key, _ any) bool {
+		if id, ok := key.(string); ok {
+			s.publish(storage.OperationDelete, id, trgt, nil)
+		}
+
+		return true
+	})
+}
+
+// EvictExpired evicts the expired documents of every target - watchers receive
+// a deletion for each -, and returns how many. The janitor calls it every
+// `JanitorInterval`; reads evict the expired documents they find too.
+func (s *Memory) EvictExpired() int {
+	evicted := 0
+	now := s.Config.Clock()
+
+	s.client.Range(func(target, documents any) bool {
+		trgt, _ := target.(string)
+
+		docs, ok := documents.(*sync.Map)
+		if !ok {
+			return true
+		}
+
+		docs.Range(func(key, value any) bool {
+			id, _ := key.(string)
+
+			if e, ok := value.(*entry); ok && e.expired(now) && s.evict(docs, trgt, id, e) {
+				evicted++
+			}
+
+			return true
+		})
+
+		return true
+	})
+
+	return evicted
+}
+
+// GetCounterEvicted returns the metric of evicted, expired documents.
+func (s *Memory) GetCounterEvicted() *expvar.Int {
+	return s.counterEvicted
+}
+
+// GetCounterCapacityEvicted returns the metric of documents evicted to stay
+// within capacity.
+func (s *Memory) GetCounterCapacityEvicted() *expvar.Int {
+	return s.counterCapacityEvicted
+}
+
+// Close stops the janitor, and the snapshotter - waiting for an in-flight
+// snapshot - then snapshots to `SnapshotPath`, if set. The storage remains usable, expired documents being
+// evicted on read.
+func (s *Memory) Close() error {
+	closed := false
+
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		closed = true
+	})
+
+	if !closed || s.Config.SnapshotPath == "" {
+		return nil
+	}
+
+	s.snapshotterWG.Wait()
+
+	return s.SnapshotToFile(s.Config.SnapshotPath)
+}
+
+// Watch streams the changes to `target` made through this storage, until
+// `ctx` is done.
+//
+// NOTE: Writers wait for watchers to receive events, so watchers should
+// drain the channel, or set a large enough buffer (`WithWatchBuffer`). Resume
+// tokens aren't supported.
+func (s *Memory) Watch(ctx context.Context, target string, options ...storage.WatchFunc) <-chan storage.ChangeEvent {
+	o, err := storage.NewWatchOptions(options...)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	if o.ResumeToken != "" {
+		return storage.FailedWatch(target, customerror.NewFailedToError(
+			"resume memory watch",
+			customerror.WithError(storage.ErrUnsupported),
+		))
+	}
+
+	return s.broadcaster.Subscribe(ctx, s.targetName(target), o)
+}
+
+// publish streams a change to watchers, if any.
+func (s *Memory) publish(operation storage.Operation, id, target string, document []byte) {
+	if !s.broadcaster.HasSubscribers(target) {
+		return
+	}
+
+	s.broadcaster.Publish(storage.ChangeEvent{
+		Operation: operation,
+		ID:        id,
+		Target:    target,
+		Document:  document,
+	})
+}
+
 //////
 // Factory.
 //////
 
-// New creates a new Memory storage.
-func New(ctx context.Context) (*Memory, error) {
+// New creates a new Memory storage, with the default configuration. Call
+// `Close` to stop its janitor.
+func New(ctx context.Context, options ...storage.ConfigFunc) (*Memory, error) {
+	return NewWithConfig(ctx, nil, options...)
+}
+
+// NewWithConfig creates a new Memory storage, with `cfg`, if set. Unset fields
+// are defaulted. Documents are restored from `SnapshotPath`, if it exists.
+// Call `Close` to stop its janitor, and snapshotter.
+func NewWithConfig(ctx context.Context, cfg *Config, options ...storage.ConfigFunc) (*Memory, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*Memory)(nil)
 
+	var _ storage.IWatcher = (*Memory)(nil)
+
 	//////
 	// Storage.
 	//////
 
-	s, err := storage.New(ctx, Name)
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
 
+	//////
+	// Config.
+	//////
+
+	config := &Config{}
+
+	if cfg != nil {
+		*config = *cfg
+	}
+
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+
+	if config.JanitorInterval == 0 {
+		config.JanitorInterval = DefaultJanitorInterval
+	}
+
+	if config.EvictionPolicy == "" {
+		config.EvictionPolicy = LRU
+	}
+
 	//////
 	// Validation.
 	//////
@@ -758,13 +1440,36 @@ func New(ctx context.Context) (*Memory, error) {
 	storage := &Memory{
 		Storage: s,
 
+		Config: config,
+
 		client: &sync.Map{},
+
+		broadcaster: &storage.Broadcaster{},
+
+		tracker: newTracker(config),
+
+		done: make(chan struct{}),
+
+		counterEvicted:         metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", storage.Type, Name, "evicted", storage.DefaultMetricCounterLabel)),
+		counterCapacityEvicted: metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", storage.Type, Name, "capacity.evicted", storage.DefaultMetricCounterLabel)),
 	}
 
 	if err := validation.Validate(storage); err != nil {
 		return nil, customapm.TraceError(ctx, err, s.GetLogger(), nil)
 	}
 
+	if config.SnapshotPath != "" {
+		if err := storage.RestoreFromFile(config.SnapshotPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterInstantiationFailed())
+		}
+	}
+
+	go storage.janitor()
+
+	if config.SnapshotPath != "" && config.SnapshotInterval > 0 {
+		storage.snapshotterWG.Go(storage.snapshotter)
+	}
+
 	//////
 	// Singleton.
 	//////
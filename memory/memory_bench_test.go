@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+// benchmarkStorage returns a storage with `cfg`, and 1000 documents.
+func benchmarkStorage(b *testing.B, cfg *Config) *Memory {
+	b.Helper()
+
+	str, err := NewWithConfig(b.Context(), cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Cleanup(func() {
+		_ = str.Close()
+	})
+
+	for i := range 1000 {
+		if _, err := str.Create(b.Context(), strconv.Itoa(i), "bench", map[string]any{"i": i}, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return str
+}
+
+// benchmarkConfig is a configuration benchmarks run with.
+type benchmarkConfig struct {
+	name string
+	cfg  *Config
+}
+
+// benchmarkConfigs are the configurations compared. This file only uses the
+// API which predates capacity limits, so it can be copied to older commits to
+// compare their unlimited storage with this one, see `make bench-memory`.
+// Others are added by capacity_bench_test.go.
+var benchmarkConfigs = []benchmarkConfig{
+	{name: "Unlimited", cfg: &Config{}},
+}
+
+func BenchmarkMemory_Update(b *testing.B) {
+	for _, bc := range benchmarkConfigs {
+		b.Run(bc.name, func(b *testing.B) {
+			str := benchmarkStorage(b, bc.cfg)
+			doc := map[string]any{"i": 1}
+
+			var n atomic.Int64
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := str.Update(b.Context(), strconv.Itoa(int(n.Add(1)%2000)), "bench", doc, nil); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkMemory_Retrieve(b *testing.B) {
+	for _, bc := range benchmarkConfigs {
+		b.Run(bc.name, func(b *testing.B) {
+			str := benchmarkStorage(b, bc.cfg)
+
+			var n atomic.Int64
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				var got map[string]any
+
+				for pb.Next() {
+					if err := str.Retrieve(b.Context(), strconv.Itoa(int(n.Add(1)%1000)), "bench", &got, nil); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkMemory_Create(b *testing.B) {
+	for _, bc := range benchmarkConfigs {
+		b.Run(bc.name, func(b *testing.B) {
+			str := benchmarkStorage(b, bc.cfg)
+			doc := map[string]any{"i": 1}
+
+			var n atomic.Int64
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_, err := str.Create(b.Context(), strconv.Itoa(int(n.Add(1))), "bench", doc, nil, storage.WithOverwrite())
+					if err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
+	"context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thalesfsp/customerror"
 	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/dal/v2/storage"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/delete"
@@ -56,6 +59,47 @@ func TestMemory_CRUDRoundTrip(t *testing.T) {
 	assert.Error(t, err, "retrieving a deleted document must fail")
 }
 
+// Mutations with an outbox fail, memory doesn't implement IOutbox.
+func TestMemory_OutboxUnsupported(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	_, err := str.Create(ctx, "doc-1", "", shared.TestData, &create.Create{}, storage.WithOutbox[*create.Create](""))
+	require.ErrorIs(t, err, storage.ErrUnsupported)
+
+	require.ErrorIs(t, str.Update(ctx, "doc-1", "", shared.TestData, &update.Update{}, storage.WithOutbox[*update.Update]("")), storage.ErrUnsupported)
+	require.ErrorIs(t, str.Delete(ctx, "doc-1", "", &delete.Delete{}, storage.WithOutbox[*delete.Delete]("")), storage.ErrUnsupported)
+
+	var got shared.TestDataS
+	assert.Error(t, str.Retrieve(ctx, "doc-1", "", &got, &retrieve.Retrieve{}), "nothing was written")
+}
+
+// Create is insert-only, unless asked to overwrite.
+func TestMemory_CreateIsInsertOnly(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	_, err := str.Create(ctx, "insert-only", "", shared.TestData, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, str.Delete(ctx, "insert-only", "", nil))
+	}()
+
+	_, err = str.Create(ctx, "insert-only", "", shared.UpdatedTestData, nil)
+	assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+
+	var got shared.TestDataS
+	require.NoError(t, str.Retrieve(ctx, "insert-only", "", &got, nil))
+	assert.Equal(t, *shared.TestData, got, "a failed create must not clobber the record")
+
+	_, err = str.Create(ctx, "insert-only", "", shared.UpdatedTestData, nil, storage.WithOverwrite())
+	require.NoError(t, err)
+
+	require.NoError(t, str.Retrieve(ctx, "insert-only", "", &got, nil))
+	assert.Equal(t, shared.DocumentNameUpdated, got.Name)
+}
+
 // Nil params are legal for every operation ("use defaults").
 func TestMemory_NilParams(t *testing.T) {
 	ctx := t.Context()
@@ -93,9 +137,11 @@ func TestMemory_BadPaths(t *testing.T) {
 	cE, ok := customerror.To(err)
 	require.True(t, ok)
 	assert.Equal(t, 404, cE.StatusCode)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
 
 	err = str.Delete(ctx, "", "", &delete.Delete{})
 	assert.Error(t, err, "empty id must be rejected")
+	assert.ErrorIs(t, err, storage.ErrInvalidArgument)
 }
 
 // Count and List honor the documented Search glob over keys.
@@ -218,3 +264,274 @@ func TestMemory_ConcurrentCRUD(t *testing.T) {
 
 	wg.Wait()
 }
+
+// Watch streams the changes to the watched target only.
+func TestMemory_Watch(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	events := str.Watch(ctx, "users", storage.WithFullDocument())
+
+	_, err := str.Create(ctx, "1", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	// Other target.
+	_, err = str.Create(ctx, "2", "orders", shared.TestData, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, str.Update(ctx, "1", "users", shared.UpdatedTestData, nil))
+	require.NoError(t, str.Delete(ctx, "1", "users", nil))
+
+	// Deleting nothing isn't a mutation: no event.
+	require.NoError(t, str.Delete(ctx, "1", "users", nil))
+
+	for _, expected := range []storage.Operation{
+		storage.OperationCreate,
+		storage.OperationUpdate,
+		storage.OperationDelete,
+	} {
+		event := <-events
+
+		require.NoError(t, event.Err)
+		assert.Equal(t, expected, event.Operation)
+		assert.Equal(t, "1", event.ID)
+		assert.Equal(t, "users", event.Target)
+
+		if expected == storage.OperationDelete {
+			assert.Empty(t, event.Document)
+		} else {
+			assert.NotEmpty(t, event.Document)
+		}
+	}
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event: %+v", event)
+	default:
+	}
+}
+
+// Watch stops, and closes the channel once the context is done.
+func TestMemory_WatchStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	str := newTestStorage(t)
+
+	events := str.Watch(ctx, "users")
+
+	cancel()
+
+	for range events {
+	}
+
+	// Writes don't block without watchers.
+	_, err := str.Create(t.Context(), "1", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	event := <-str.Watch(t.Context(), "users", storage.WithResumeToken("1"))
+	assert.ErrorIs(t, event.Err, storage.ErrUnsupported)
+}
+
+// Successful mutations are emitted to the sinks.
+func TestMemory_Emitter(t *testing.T) {
+	ctx := t.Context()
+
+	sink := storage.NewChannelSink(10)
+
+	e, err := storage.NewEmitter("memoryemitter", []storage.ISink{sink})
+	require.NoError(t, err)
+
+	str, err := New(ctx, storage.WithEmitter(e))
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "1", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	// Failed.
+	_, err = str.Create(ctx, "1", "users", shared.TestData, nil)
+	require.Error(t, err)
+
+	require.NoError(t, str.Delete(ctx, "1", "users", nil))
+
+	require.NoError(t, e.Close(ctx))
+
+	event := <-sink.Events()
+	assert.Equal(t, "dal.create", event.Type)
+	assert.Equal(t, Name, event.Source)
+	assert.Equal(t, "users/1", event.Subject)
+	assert.NotEmpty(t, event.Data)
+
+	event = <-sink.Events()
+	assert.Equal(t, "dal.delete", event.Type)
+
+	assert.Empty(t, sink.Events())
+}
+
+// Targets are namespaces: the same ID can exist in each, and count, and list
+// only see their own.
+func TestMemory_TargetsArePartitioned(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	_, err := str.Create(ctx, "1", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "1", "orders", shared.UpdatedTestData, nil)
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "2", "orders", shared.UpdatedTestData, nil)
+	require.NoError(t, err)
+
+	var got shared.TestDataS
+	require.NoError(t, str.Retrieve(ctx, "1", "users", &got, nil))
+	assert.Equal(t, *shared.TestData, got)
+
+	require.NoError(t, str.Retrieve(ctx, "1", "orders", &got, nil))
+	assert.Equal(t, *shared.UpdatedTestData, got)
+
+	c, err := str.Count(ctx, "users", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	c, err = str.Count(ctx, "orders", &count.Count{Search: "*"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+
+	c, err = str.Count(ctx, "missing", nil)
+	require.NoError(t, err)
+	assert.Zero(t, c)
+
+	require.NoError(t, str.Delete(ctx, "1", "orders", nil))
+	require.NoError(t, str.Retrieve(ctx, "1", "users", &got, nil))
+
+	assert.Equal(t, []string{"orders", "users"}, str.ListTargets())
+
+	// The static target is the default.
+	str.Target = "users"
+
+	require.NoError(t, str.Retrieve(ctx, "1", "", &got, nil))
+
+	events := str.Watch(ctx, "orders")
+
+	str.DropTarget("orders")
+
+	event := <-events
+	assert.Equal(t, storage.OperationDelete, event.Operation)
+	assert.Equal(t, "2", event.ID)
+
+	assert.Equal(t, []string{"users"}, str.ListTargets())
+
+	c, err = str.Count(ctx, "orders", nil)
+	require.NoError(t, err)
+	assert.Zero(t, c)
+
+	// No-op.
+	str.DropTarget("orders")
+}
+
+// fakeClock is a clock tests can advance.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Documents expire after their TTL: lazily on read, or by the janitor.
+func TestMemory_TTL(t *testing.T) {
+	ctx := t.Context()
+	clock := &fakeClock{now: time.Now()}
+
+	str, err := NewWithConfig(ctx, &Config{Clock: clock.Now})
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, str.Close())
+	}()
+
+	evicted := str.GetCounterEvicted().Value()
+
+	_, err = str.Create(ctx, "1", "sessions", shared.TestData, &create.Create{TTL: time.Minute})
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "2", "sessions", shared.TestData, &create.Create{TTL: time.Hour})
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "3", "sessions", shared.TestData, nil)
+	require.NoError(t, err)
+
+	// An update resets the expiry.
+	require.NoError(t, str.Update(ctx, "2", "sessions", shared.UpdatedTestData, &update.Update{TTL: time.Second}))
+
+	c, err := str.Count(ctx, "sessions", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), c)
+
+	clock.Advance(time.Minute)
+
+	var got shared.TestDataS
+	assert.ErrorIs(t, str.Retrieve(ctx, "1", "sessions", &got, nil), storage.ErrNotFound)
+
+	var docs ResponseList[shared.TestDataS]
+	require.NoError(t, str.List(ctx, "sessions", &docs, nil))
+	assert.Len(t, docs.Items, 1)
+
+	c, err = str.Count(ctx, "sessions", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	assert.Equal(t, evicted+2, str.GetCounterEvicted().Value())
+
+	// An expired document doesn't block inserts.
+	_, err = str.Create(ctx, "4", "sessions", shared.TestData, &create.Create{TTL: time.Second})
+	require.NoError(t, err)
+
+	clock.Advance(time.Second)
+
+	_, err = str.Create(ctx, "4", "sessions", shared.TestData, &create.Create{TTL: time.Second})
+	require.NoError(t, err)
+
+	clock.Advance(time.Second)
+
+	assert.Equal(t, 1, str.EvictExpired())
+	assert.Equal(t, evicted+4, str.GetCounterEvicted().Value())
+
+	require.NoError(t, str.Retrieve(ctx, "3", "sessions", &got, nil))
+}
+
+// The janitor evicts expired documents until closed.
+func TestMemory_Janitor(t *testing.T) {
+	ctx := t.Context()
+	clock := &fakeClock{now: time.Now()}
+
+	str, err := NewWithConfig(ctx, &Config{Clock: clock.Now, JanitorInterval: time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "1", "sessions", shared.TestData, &create.Create{TTL: time.Second})
+	require.NoError(t, err)
+
+	clock.Advance(time.Second)
+
+	assert.Eventually(t, func() bool {
+		_, ok := str.documents("sessions", false).Load("1")
+
+		return !ok
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, str.Close())
+	require.NoError(t, str.Close())
+
+	_, err = NewWithConfig(ctx, &Config{JanitorInterval: -time.Second})
+	assert.Error(t, err)
+}
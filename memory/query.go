@@ -0,0 +1,359 @@
+package memory
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/customsort"
+	"github.com/thalesfsp/params/v2/list"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// whereOperators are the operators of `ListAny.Where`, two-character ones
+// first.
+var whereOperators = []Operator{NotEqual, GreaterOrEqual, LessOrEqual, Equal, Greater, Less}
+
+// query is a List query evaluated against the stored documents.
+type query struct {
+	filter []Predicate
+	sort   [][]string
+	fields []string
+	offset int
+	limit  int
+}
+
+// document is a document matching the key pattern of a query.
+type document struct {
+	id    string
+	data  []byte
+	value any
+}
+
+//////
+// Helpers.
+//////
+
+// newQuery returns the query of `prm`. Filters are taken from `prm.Any`, if
+// it's a `*ListAny`.
+func newQuery(prm *list.List) (*query, error) {
+	if prm.Offset < 0 {
+		return nil, customerror.NewInvalidError(fmt.Sprintf("offset %d, must be >= 0", prm.Offset))
+	}
+
+	if prm.Limit < 0 {
+		return nil, customerror.NewInvalidError(fmt.Sprintf("limit %d, must be >= 0", prm.Limit))
+	}
+
+	q := &query{
+		sort:   prm.Sort,
+		fields: prm.Fields,
+		offset: prm.Offset,
+		limit:  prm.Limit,
+	}
+
+	if lA, ok := prm.Any.(*ListAny); ok && lA != nil {
+		where, err := ParseWhere(lA.Where)
+		if err != nil {
+			return nil, err
+		}
+
+		q.filter = append(slices.Clone(lA.Filter), where...)
+	}
+
+	for i, predicate := range q.filter {
+		if predicate.Field == "" || !slices.Contains(whereOperators, predicate.Operator) {
+			return nil, customerror.NewInvalidError(fmt.Sprintf("filter predicate %+v", predicate))
+		}
+
+		value, err := normalize(predicate.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		q.filter[i].Value = value
+	}
+
+	for _, s := range q.sort {
+		if len(s) != 2 || s[0] == "" || (s[1] != customsort.Asc && s[1] != customsort.Desc) {
+			return nil, customerror.NewInvalidError(fmt.Sprintf("sort %v. Expected: `key:order`", s))
+		}
+	}
+
+	return q, nil
+}
+
+// decodes reports whether the query needs documents decoded.
+func (q *query) decodes() bool {
+	return len(q.filter) > 0 || len(q.sort) > 0 || len(q.fields) > 0
+}
+
+// matches reports whether `value` matches every predicate.
+func (q *query) matches(value any) bool {
+	for _, predicate := range q.filter {
+		field, _ := lookup(value, predicate.Field)
+
+		c, comparable := compare(field, predicate.Value)
+
+		var ok bool
+
+		switch predicate.Operator {
+		case Equal:
+			ok = equal(field, predicate.Value)
+		case NotEqual:
+			ok = !equal(field, predicate.Value)
+		case Greater:
+			ok = comparable && c > 0
+		case GreaterOrEqual:
+			ok = comparable && c >= 0
+		case Less:
+			ok = comparable && c < 0
+		case LessOrEqual:
+			ok = comparable && c <= 0
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// run filters, sorts, paginates, and projects `documents`, returning them as
+// JSON. Without sort, documents are sorted by ID, so pages are stable.
+func (q *query) run(documents []document) ([][]byte, error) {
+	if len(q.filter) > 0 {
+		documents = slices.DeleteFunc(documents, func(d document) bool {
+			return !q.matches(d.value)
+		})
+	}
+
+	slices.SortFunc(documents, func(a, b document) int {
+		for _, s := range q.sort {
+			x, _ := lookup(a.value, s[0])
+			y, _ := lookup(b.value, s[0])
+
+			c := order(x, y)
+
+			if s[1] == customsort.Desc {
+				c = -c
+			}
+
+			if c != 0 {
+				return c
+			}
+		}
+
+		return strings.Compare(a.id, b.id)
+	})
+
+	documents = documents[min(q.offset, len(documents)):]
+
+	if q.limit > 0 {
+		documents = documents[:min(q.limit, len(documents))]
+	}
+
+	items := make([][]byte, 0, len(documents))
+
+	for _, d := range documents {
+		if len(q.fields) == 0 {
+			items = append(items, d.data)
+
+			continue
+		}
+
+		b, err := shared.Marshal(project(d.value, q.fields))
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, b)
+	}
+
+	return items, nil
+}
+
+// lookup returns the field at the dot-separated `path` of `value`.
+func lookup(value any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// project returns the `fields` of `value`, if it's an object.
+func project(value any, fields []string) any {
+	if _, ok := value.(map[string]any); !ok {
+		return value
+	}
+
+	projected := map[string]any{}
+
+	for _, field := range fields {
+		v, ok := lookup(value, field)
+		if !ok {
+			continue
+		}
+
+		keys := strings.Split(field, ".")
+		object := projected
+
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := object[key].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				object[key] = child
+			}
+
+			object = child
+		}
+
+		object[keys[len(keys)-1]] = v
+	}
+
+	return projected
+}
+
+// normalize returns `v` as decoded from JSON, so it compares to documents'
+// fields, e.g.: numbers become float64.
+func normalize(v any) (any, error) {
+	b, err := shared.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized any
+
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return nil, customerror.NewFailedToError("normalize value", customerror.WithError(err))
+	}
+
+	return normalized, nil
+}
+
+// compare compares numbers, or strings. Returns false for anything else.
+func compare(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y), true
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	}
+
+	return 0, false
+}
+
+// equal reports whether `a`, and `b` are equal.
+func equal(a, b any) bool {
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// rank orders the JSON types, for sorting values of different types.
+func rank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// order orders any two values: missing, and null ones first, then booleans,
+// numbers, strings, and others.
+func order(a, b any) int {
+	if c, ok := compare(a, b); ok {
+		return c
+	}
+
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+
+	return cmp.Compare(rank(a), rank(b))
+}
+
+//////
+// Exported functionalities.
+//////
+
+// ParseWhere parses a `ListAny.Where` expression.
+//
+// NOTE: Values can't contain commas.
+func ParseWhere(expr string) ([]Predicate, error) {
+	predicates := []Predicate{}
+
+	for raw := range strings.SplitSeq(expr, ",") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		i, operator := -1, Operator("")
+
+		for _, op := range whereOperators {
+			if j := strings.Index(raw, string(op)); j > 0 && (i == -1 || j < i) {
+				i, operator = j, op
+			}
+		}
+
+		if i == -1 {
+			return nil, customerror.NewInvalidError(
+				fmt.Sprintf("where predicate %q. Expected: `<field><operator><value>`", raw),
+			)
+		}
+
+		predicate := Predicate{
+			Field:    strings.TrimSpace(raw[:i]),
+			Operator: operator,
+		}
+
+		value := strings.TrimSpace(raw[i+len(operator):])
+
+		if err := json.Unmarshal([]byte(value), &predicate.Value); err != nil {
+			predicate.Value = value
+		}
+
+		predicates = append(predicates, predicate)
+	}
+
+	return predicates, nil
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/params/v2/customsort"
+	"github.com/thalesfsp/params/v2/field"
+	"github.com/thalesfsp/params/v2/list"
+)
+
+type person struct {
+	Name    string         `json:"name"`
+	Age     int            `json:"age,omitempty"`
+	Address map[string]any `json:"address,omitempty"`
+}
+
+func newQueryStorage(t *testing.T) *Memory {
+	t.Helper()
+
+	str := newTestStorage(t)
+
+	for id, p := range map[string]person{
+		"1": {Name: "Ana", Age: 31, Address: map[string]any{"city": "Paris"}},
+		"2": {Name: "Bob", Age: 17, Address: map[string]any{"city": "Lyon"}},
+		"3": {Name: "Cid", Age: 45, Address: map[string]any{"city": "Paris"}},
+		"4": {Name: "Dee"},
+	} {
+		_, err := str.Create(t.Context(), id, "people", p, nil)
+		require.NoError(t, err)
+	}
+
+	return str
+}
+
+func names(people []person) []string {
+	n := []string{}
+
+	for _, p := range people {
+		n = append(n, p.Name)
+	}
+
+	return n
+}
+
+func TestMemory_Query(t *testing.T) {
+	ctx := t.Context()
+	str := newQueryStorage(t)
+
+	tests := []struct {
+		name string
+		prm  *list.List
+		want []string
+	}{
+		{
+			name: "Should list every document by ID",
+			prm:  nil,
+			want: []string{"Ana", "Bob", "Cid", "Dee"},
+		},
+		{
+			name: "Should filter by equality",
+			prm: &list.List{Any: &ListAny{Filter: []Predicate{
+				{Field: "address.city", Operator: Equal, Value: "Paris"},
+			}}},
+			want: []string{"Ana", "Cid"},
+		},
+		{
+			name: "Should filter by range",
+			prm: &list.List{Any: &ListAny{Filter: []Predicate{
+				{Field: "age", Operator: GreaterOrEqual, Value: 18},
+				{Field: "age", Operator: Less, Value: 45},
+			}}},
+			want: []string{"Ana"},
+		},
+		{
+			name: "Should filter by expression",
+			prm:  &list.List{Any: &ListAny{Where: `age>18, address.city!="Lyon"`}},
+			want: []string{"Ana", "Cid"},
+		},
+		{
+			name: "Should match missing fields only for inequality",
+			prm:  &list.List{Any: &ListAny{Where: "address.city!=Paris"}},
+			want: []string{"Bob", "Dee"},
+		},
+		{
+			name: "Should sort, missing fields first",
+			prm:  &list.List{Sort: customsort.SortSlice{{"age", customsort.Desc}}},
+			want: []string{"Cid", "Ana", "Bob", "Dee"},
+		},
+		{
+			name: "Should sort on several fields",
+			prm: &list.List{Sort: customsort.SortSlice{
+				{"address.city", customsort.Asc},
+				{"name", customsort.Desc},
+			}},
+			want: []string{"Dee", "Bob", "Cid", "Ana"},
+		},
+		{
+			name: "Should paginate",
+			prm:  &list.List{Sort: customsort.SortSlice{{"age", customsort.Asc}}, Offset: 1, Limit: 2},
+			want: []string{"Bob", "Ana"},
+		},
+		{
+			name: "Should paginate past the end",
+			prm:  &list.List{Offset: 10},
+			want: []string{},
+		},
+		{
+			name: "Should combine search, and filter",
+			prm:  &list.List{Search: "[12]", Any: &ListAny{Where: "age>0"}},
+			want: []string{"Ana", "Bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ResponseList[person]
+
+			require.NoError(t, str.List(ctx, "people", &got, tt.prm))
+
+			assert.Equal(t, tt.want, names(got.Items))
+		})
+	}
+}
+
+func TestMemory_QueryFields(t *testing.T) {
+	str := newQueryStorage(t)
+
+	var got ResponseList[map[string]any]
+
+	require.NoError(t, str.List(t.Context(), "people", &got, &list.List{
+		Fields: field.Fields{"name", "address.city"},
+		Any:    &ListAny{Where: "name=Ana"},
+	}))
+
+	assert.Equal(t, []map[string]any{
+		{"name": "Ana", "address": map[string]any{"city": "Paris"}},
+	}, got.Items)
+}
+
+func TestMemory_QueryInvalid(t *testing.T) {
+	str := newQueryStorage(t)
+
+	for _, prm := range []*list.List{
+		{Any: &ListAny{Where: "age"}},
+		{Any: &ListAny{Filter: []Predicate{{Field: "age", Operator: "~"}}}},
+		{Any: &ListAny{Filter: []Predicate{{Operator: Equal}}}},
+		{Sort: customsort.SortSlice{{"age", "up"}}},
+		{Offset: -1},
+		{Limit: -1},
+	} {
+		var got ResponseList[person]
+
+		assert.Error(t, str.List(t.Context(), "people", &got, prm))
+	}
+}
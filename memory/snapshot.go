@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// SnapshotVersion is the version of the snapshot format written by Snapshot.
+const SnapshotVersion = 1
+
+// snapshotDocument is a document, as snapshotted.
+type snapshotDocument struct {
+	// Document is the document, as JSON.
+	Document json.RawMessage `json:"document"`
+
+	// ExpiresAt is when the document expires, if it does.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// snapshot is the snapshot format.
+type snapshot struct {
+	// Version of the format.
+	Version int `json:"version"`
+
+	// CreatedAt is when the snapshot was taken.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Targets holds the documents of each target, by ID, by target.
+	Targets map[string]map[string]snapshotDocument `json:"targets"`
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Snapshot writes every live document, with its expiry, to `w`, as versioned
+// JSON. Writers wait while documents are collected, so the snapshot is
+// consistent: a point in time.
+func (s *Memory) Snapshot(w io.Writer) error {
+	snap := snapshot{
+		Version: SnapshotVersion,
+		Targets: map[string]map[string]snapshotDocument{},
+	}
+
+	s.mu.Lock()
+
+	snap.CreatedAt = s.Config.Clock()
+
+	s.client.Range(func(target, documents any) bool {
+		trgt, _ := target.(string)
+
+		docs, ok := documents.(*sync.Map)
+		if !ok {
+			return true
+		}
+
+		snap.Targets[trgt] = map[string]snapshotDocument{}
+
+		docs.Range(func(key, value any) bool {
+			id, _ := key.(string)
+
+			e, ok := value.(*entry)
+			if !ok || e.expired(snap.CreatedAt) {
+				return true
+			}
+
+			document := snapshotDocument{Document: e.data}
+
+			if !e.expiresAt.IsZero() {
+				document.ExpiresAt = &e.expiresAt
+			}
+
+			snap.Targets[trgt][id] = document
+
+			return true
+		})
+
+		return true
+	})
+
+	s.mu.Unlock()
+
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		return customerror.NewFailedToError("write snapshot", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// Restore replaces every document with the ones of the snapshot read from
+// `r`. Expired documents are skipped, and ones exceeding capacity evicted.
+// Watchers aren't notified, but of evictions.
+func (s *Memory) Restore(r io.Reader) error {
+	var snap snapshot
+
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return customerror.NewFailedToError("read snapshot", customerror.WithError(err))
+	}
+
+	if snap.Version != SnapshotVersion {
+		return customerror.NewInvalidError(
+			fmt.Sprintf("snapshot version %d, expected %d", snap.Version, SnapshotVersion),
+		)
+	}
+
+	now := s.Config.Clock()
+
+	client := map[string]*sync.Map{}
+
+	for target, documents := range snap.Targets {
+		client[target] = &sync.Map{}
+
+		for id, document := range documents {
+			e := &entry{data: document.Document}
+
+			if document.ExpiresAt != nil {
+				e.expiresAt = *document.ExpiresAt
+			}
+
+			if !e.expired(now) {
+				client[target].Store(id, e)
+			}
+		}
+	}
+
+	victims := []*item{}
+
+	s.mu.Lock()
+	s.lockCapacity()
+
+	s.client.Clear()
+
+	if s.tracker != nil {
+		s.tracker.reset()
+	}
+
+	for target, documents := range client {
+		s.client.Store(target, documents)
+
+		if s.tracker == nil {
+			continue
+		}
+
+		documents.Range(func(key, value any) bool {
+			for _, victim := range s.tracker.put(target, key.(string), value.(*entry)) {
+				client[victim.target].CompareAndDelete(victim.id, victim.entry)
+
+				victims = append(victims, victim)
+			}
+
+			return true
+		})
+	}
+
+	s.unlockCapacity()
+	s.mu.Unlock()
+
+	for _, victim := range victims {
+		s.evicted(victim.target, victim.id, EvictionReasonCapacity)
+	}
+
+	return nil
+}
+
+// SnapshotToFile snapshots to `path` atomically: to a temporary file, synced,
+// then renamed, so `path` always holds a complete snapshot. The directory is
+// synced too, so the rename survives a crash.
+func (s *Memory) SnapshotToFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return customerror.NewFailedToError("create snapshot file", customerror.WithError(err))
+	}
+
+	// No-op once renamed.
+	defer os.Remove(f.Name())
+
+	if err := s.Snapshot(f); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+
+		return customerror.NewFailedToError("sync snapshot file", customerror.WithError(err))
+	}
+
+	if err := f.Close(); err != nil {
+		return customerror.NewFailedToError("close snapshot file", customerror.WithError(err))
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return customerror.NewFailedToError("rename snapshot file", customerror.WithError(err))
+	}
+
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return customerror.NewFailedToError("sync snapshot directory", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// RestoreFromFile restores the snapshot at `path`. See Restore.
+func (s *Memory) RestoreFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return customerror.NewFailedToError("open snapshot file", customerror.WithError(err))
+	}
+
+	defer f.Close()
+
+	return s.Restore(f)
+}
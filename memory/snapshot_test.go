@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/create"
+)
+
+func TestMemory_SnapshotRestore(t *testing.T) {
+	ctx := t.Context()
+	clock := &fakeClock{now: time.Now()}
+
+	str, err := NewWithConfig(ctx, &Config{Clock: clock.Now})
+	require.NoError(t, err)
+
+	defer str.Close()
+
+	_, err = str.Create(ctx, "1", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "1", "sessions", shared.UpdatedTestData, &create.Create{TTL: time.Hour})
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "2", "sessions", shared.UpdatedTestData, &create.Create{TTL: time.Second})
+	require.NoError(t, err)
+
+	clock.Advance(time.Second)
+
+	var buf bytes.Buffer
+	require.NoError(t, str.Snapshot(&buf))
+
+	restored, err := NewWithConfig(ctx, &Config{Clock: clock.Now})
+	require.NoError(t, err)
+
+	defer restored.Close()
+
+	_, err = restored.Create(ctx, "stale", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, restored.Restore(&buf))
+
+	var got shared.TestDataS
+	require.NoError(t, restored.Retrieve(ctx, "1", "users", &got, nil))
+	assert.Equal(t, *shared.TestData, got)
+
+	require.NoError(t, restored.Retrieve(ctx, "1", "sessions", &got, nil))
+	assert.Equal(t, *shared.UpdatedTestData, got)
+
+	assert.Error(t, restored.Retrieve(ctx, "2", "sessions", &got, nil), "expired documents aren't snapshotted")
+	assert.Error(t, restored.Retrieve(ctx, "stale", "users", &got, nil), "restores replace documents")
+
+	// Expiries survive.
+	clock.Advance(time.Hour)
+
+	assert.Error(t, restored.Retrieve(ctx, "1", "sessions", &got, nil))
+
+	assert.Error(t, restored.Restore(strings.NewReader(`{"version":99}`)))
+	assert.Error(t, restored.Restore(strings.NewReader(`not json`)))
+
+	require.NoError(t, restored.Retrieve(ctx, "1", "users", &got, nil), "failed restores keep documents")
+}
+
+// Documents are restored from `SnapshotPath` by New, and snapshotted to it
+// periodically, and on Close.
+func TestMemory_SnapshotPath(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "memory.json")
+
+	str, err := NewWithConfig(ctx, &Config{SnapshotPath: path, SnapshotInterval: time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "1", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		restored, err := NewWithConfig(ctx, &Config{SnapshotPath: path})
+		if err != nil {
+			return false
+		}
+
+		defer restored.Close()
+
+		var got shared.TestDataS
+
+		return restored.Retrieve(ctx, "1", "users", &got, nil) == nil
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = str.Create(ctx, "2", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, str.Close())
+
+	restored, err := NewWithConfig(ctx, &Config{SnapshotPath: path})
+	require.NoError(t, err)
+
+	c, err := restored.Count(ctx, "users", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+
+	require.NoError(t, restored.Close())
+
+	matches, err := filepath.Glob(path + ".*.tmp")
+	require.NoError(t, err)
+	assert.Empty(t, matches, "temporary files are cleaned up")
+}
+
+// Snapshots are consistent with concurrent writes (meaningful under -race):
+// documents written in pairs are snapshotted in pairs.
+func TestMemory_SnapshotConcurrentWrites(t *testing.T) {
+	ctx := t.Context()
+	str := newTestStorage(t)
+
+	defer str.Close()
+
+	var wg sync.WaitGroup
+
+	for w := range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range 50 {
+				id := fmt.Sprintf("%d-%d", w, i)
+
+				str.mu.RLock()
+				str.documents("a", true).Store(id, &entry{data: []byte(`{}`)})
+				str.documents("b", true).Store(id, &entry{data: []byte(`{}`)})
+				str.mu.RUnlock()
+			}
+		}()
+	}
+
+	for range 20 {
+		var buf bytes.Buffer
+		require.NoError(t, str.Snapshot(&buf))
+
+		restored := newTestStorage(t)
+		require.NoError(t, restored.Restore(&buf))
+
+		a, err := restored.Count(ctx, "a", nil)
+		require.NoError(t, err)
+
+		b, err := restored.Count(ctx, "b", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, a, b)
+	}
+
+	wg.Wait()
+}
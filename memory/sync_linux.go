@@ -0,0 +1,17 @@
+//go:build linux
+
+package memory
+
+import "os"
+
+// syncDir syncs `dir`, so renames into it are durable.
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return f.Sync()
+}
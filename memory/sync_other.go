@@ -0,0 +1,8 @@
+//go:build !linux
+
+package memory
+
+// syncDir is a no-op: directories are only synced on Linux.
+func syncDir(_ string) error {
+	return nil
+}
@@ -4,3 +4,43 @@ package memory
 type ResponseList[T any] struct {
 	Items []T `json:"items"`
 }
+
+// Operator compares a document's field to a value.
+type Operator string
+
+// Operators.
+const (
+	Equal          Operator = "="
+	NotEqual       Operator = "!="
+	Greater        Operator = ">"
+	GreaterOrEqual Operator = ">="
+	Less           Operator = "<"
+	LessOrEqual    Operator = "<="
+)
+
+// Predicate matches documents whose `Field` compares to `Value` with
+// `Operator`.
+type Predicate struct {
+	// Field is the path of the field, dot-separated for nested ones, e.g.:
+	// `address.city`.
+	Field string `json:"field"`
+
+	// Operator compares the field to the value.
+	Operator Operator `json:"operator"`
+
+	// Value the field is compared to. Numbers compare to numbers, strings to
+	// strings (lexicographically), and anything else only for (in)equality.
+	Value any `json:"value"`
+}
+
+// ListAny is a struct for the `list.List` `Any` field.
+type ListAny struct {
+	// Filter documents must match: every predicate.
+	Filter []Predicate `json:"filter"`
+
+	// Where is a simple expression documents must match too: comma-separated
+	// `<field><operator><value>` predicates, e.g.: `age>=18,address.city=Paris`.
+	// Values are JSON (numbers, booleans, quoted strings, null), or else raw
+	// strings.
+	Where string `json:"where"`
+}
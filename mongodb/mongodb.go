@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/eapache/go-resiliency/retrier"
 	"github.com/thalesfsp/customerror"
@@ -14,6 +15,7 @@ import (
 	"github.com/thalesfsp/dal/v2/internal/logging"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/customsort"
@@ -27,6 +29,7 @@ import (
 	"github.com/thalesfsp/sypl/v2/level"
 	"github.com/thalesfsp/validation"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -96,12 +99,54 @@ func ToMongoString(s customsort.Sort) (bson.D, error) {
 	return sortFields, nil
 }
 
+// statement returns the extended JSON of a filter, or an update.
+func statement(v any) string {
+	b, err := bson.MarshalExtJSON(v, false, false)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
+// documentID returns the string form of a document `_id`.
+func documentID(id any) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case primitive.ObjectID:
+		return v.Hex()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// database returns the database context of the spans of `trgt` in `db`.
+func (m *MongoDB) database(db, trgt string) tracing.Database {
+	d := tracing.Database{Name: db, Collection: trgt}
+
+	if m.Config != nil && m.Config.Auth != nil {
+		d.User = m.Config.Auth.Username
+	}
+
+	return d
+}
+
 //////
 // Implements the IStorage interface.
 //////
 
 // Count returns the number of items in the storage.
 func (m *MongoDB) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, m, storage.OperationCount, target, "")
+
+	c, err := m.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (m *MongoDB) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -188,6 +233,8 @@ func (m *MongoDB) Count(ctx context.Context, target string, prm *count.Count, op
 		return 0, customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(m.database(o.Database, trgt))
+
 	//////
 	// Count.
 	//////
@@ -198,6 +245,8 @@ func (m *MongoDB) Count(ctx context.Context, target string, prm *count.Count, op
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: statement(filter)})
+
 	count, err := m.
 		Client.
 		Database(o.Database).
@@ -240,6 +289,13 @@ func (m *MongoDB) Count(ctx context.Context, target string, prm *count.Count, op
 
 // Delete removes data.
 func (m *MongoDB) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, m, storage.OperationDelete, target, id)
+
+	return o.Done(nil, m.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (m *MongoDB) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -280,6 +336,10 @@ func (m *MongoDB) Delete(ctx context.Context, id, target string, prm *delete.Del
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterDeletedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -302,6 +362,8 @@ func (m *MongoDB) Delete(ctx context.Context, id, target string, prm *delete.Del
 		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(m.database(o.Database, trgt))
+
 	//////
 	// Delete.
 	//////
@@ -312,11 +374,16 @@ func (m *MongoDB) Delete(ctx context.Context, id, target string, prm *delete.Del
 		}
 	}
 
-	if _, err := m.
+	filter := bson.M{"_id": id}
+
+	span.SetDatabase(tracing.Database{Statement: statement(filter)})
+
+	deleteResult, err := m.
 		Client.
 		Database(o.Database).
 		Collection(trgt).
-		DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		DeleteOne(ctx, filter)
+	if err != nil {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(
@@ -328,6 +395,8 @@ func (m *MongoDB) Delete(ctx context.Context, id, target string, prm *delete.Del
 		)
 	}
 
+	span.SetRows(deleteResult.DeletedCount)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, id, trgt, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterDeletedFailed())
@@ -356,6 +425,13 @@ func (m *MongoDB) Delete(ctx context.Context, id, target string, prm *delete.Del
 
 // Retrieve data.
 func (m *MongoDB) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, m, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, m.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (m *MongoDB) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -418,6 +494,8 @@ func (m *MongoDB) Retrieve(ctx context.Context, id, target string, v any, prm *r
 		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(m.database(o.Database, trgt))
+
 	//////
 	// Retrieve.
 	//////
@@ -428,13 +506,17 @@ func (m *MongoDB) Retrieve(ctx context.Context, id, target string, v any, prm *r
 		}
 	}
 
+	filter := bson.M{"_id": id}
+
+	span.SetDatabase(tracing.Database{Statement: statement(filter)})
+
 	var result bson.M
 
 	if err := m.
 		Client.
 		Database(o.Database).
 		Collection(trgt).
-		FindOne(ctx, bson.M{"_id": id}).
+		FindOne(ctx, filter).
 		Decode(&result); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return customapm.TraceError(
@@ -473,6 +555,8 @@ func (m *MongoDB) Retrieve(ctx context.Context, id, target string, v any, prm *r
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterRetrievedFailed())
@@ -507,6 +591,13 @@ func (m *MongoDB) Retrieve(ctx context.Context, id, target string, v any, prm *r
 //
 // NOTE: It uses param.List.Search to query the data.
 func (m *MongoDB) List(ctx context.Context, target string, v any, prm *list.List, opts ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, m, storage.OperationList, target, "")
+
+	return o.Done(v, m.list(ctx, target, v, prm, opts...), ErrorKind)
+}
+
+// list is List's implementation. See List.
+func (m *MongoDB) list(ctx context.Context, target string, v any, prm *list.List, opts ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -608,6 +699,8 @@ func (m *MongoDB) List(ctx context.Context, target string, v any, prm *list.List
 		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(m.database(o.Database, trgt))
+
 	//////
 	// Query.
 	//////
@@ -618,6 +711,8 @@ func (m *MongoDB) List(ctx context.Context, target string, v any, prm *list.List
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: statement(filter)})
+
 	cursor, err := m.
 		Client.
 		Database(o.Database).
@@ -643,6 +738,8 @@ func (m *MongoDB) List(ctx context.Context, target string, v any, prm *list.List
 		)
 	}
 
+	span.SetRows(shared.Rows(v))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, "", trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterListedFailed())
@@ -674,9 +771,22 @@ func (m *MongoDB) List(ctx context.Context, target string, v any, prm *list.List
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set.
 //
+// NOTE: It's insert-only, failing with `storage.ErrAlreadyExists` on a
+// duplicated `_id`. Use `storage.WithOverwrite` to replace (upsert) the
+// document with `id` instead.
+//
 // WARN: MongoDB relies on the model (`v`) `_id` field to be set, otherwise it
 // will generate a new one. IT'S UP TO THE DEVELOPER TO SET THE `_ID` FIELD.
-func (m *MongoDB) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+func (m *MongoDB) Create(ctx context.Context, id, target string, v any, prm *create.Create, opts ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, m, storage.OperationCreate, target, id)
+
+	createdID, err := m.create(ctx, id, target, v, prm, opts...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+func (m *MongoDB) create(ctx context.Context, id, target string, v any, prm *create.Create, opts ...storage.Func[*create.Create]) (string, error) {
 	if id == "" {
 		return "", customapm.TraceError(
 			ctx,
@@ -711,12 +821,16 @@ func (m *MongoDB) Create(ctx context.Context, id, target string, v any, prm *cre
 	o.Database = m.Database
 
 	// Iterate over the options and apply them against params.
-	for _, option := range options {
+	for _, option := range opts {
 		if err := option(o); err != nil {
 			return "", customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCreatedFailed())
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return "", customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCreatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -739,6 +853,8 @@ func (m *MongoDB) Create(ctx context.Context, id, target string, v any, prm *cre
 		return "", customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(m.database(o.Database, trgt))
+
 	//////
 	// Create.
 	//////
@@ -749,11 +865,16 @@ func (m *MongoDB) Create(ctx context.Context, id, target string, v any, prm *cre
 		}
 	}
 
-	if _, err := m.
-		Client.
-		Database(o.Database).
-		Collection(trgt).
-		InsertOne(ctx, v); err != nil {
+	collection := m.Client.Database(o.Database).Collection(trgt)
+
+	// Insert-only, unless asked to overwrite.
+	if o.Overwrite {
+		_, err = collection.ReplaceOne(ctx, bson.M{"_id": id}, v, options.Replace().SetUpsert(true))
+	} else {
+		_, err = collection.InsertOne(ctx, v)
+	}
+
+	if err != nil {
 		return "", customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(storage.OperationCreate.String(), customerror.WithError(err)),
@@ -762,6 +883,8 @@ func (m *MongoDB) Create(ctx context.Context, id, target string, v any, prm *cre
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, id, trgt, v, finalParam); err != nil {
 			return "", customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCreatedFailed())
@@ -790,6 +913,13 @@ func (m *MongoDB) Create(ctx context.Context, id, target string, v any, prm *cre
 
 // Update data.
 func (m *MongoDB) Update(ctx context.Context, id, target string, v any, prm *update.Update, opts ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, m, storage.OperationUpdate, target, id)
+
+	return o.Done(v, m.update(ctx, id, target, v, prm, opts...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (m *MongoDB) update(ctx context.Context, id, target string, v any, prm *update.Update, opts ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -830,6 +960,10 @@ func (m *MongoDB) Update(ctx context.Context, id, target string, v any, prm *upd
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterUpdatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -852,6 +986,8 @@ func (m *MongoDB) Update(ctx context.Context, id, target string, v any, prm *upd
 		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(m.database(o.Database, trgt))
+
 	//////
 	// Update.
 	//////
@@ -876,11 +1012,15 @@ func (m *MongoDB) Update(ctx context.Context, id, target string, v any, prm *upd
 		"$set": updateFields,
 	}
 
+	filter := bson.M{"_id": id}
+
+	span.SetDatabase(tracing.Database{Statement: statement(bson.M{"filter": filter, "update": update})})
+
 	updateResult, err := m.
 		Client.
 		Database(o.Database).
 		Collection(trgt).
-		UpdateOne(ctx, bson.M{"_id": id}, update)
+		UpdateOne(ctx, filter, update)
 	if err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -890,6 +1030,8 @@ func (m *MongoDB) Update(ctx context.Context, id, target string, v any, prm *upd
 		)
 	}
 
+	span.SetRows(updateResult.ModifiedCount)
+
 	// Surface updates that matched nothing as 404, consistent with Retrieve.
 	if updateResult.MatchedCount == 0 && updateResult.UpsertedCount == 0 {
 		return customapm.TraceError(
@@ -931,16 +1073,116 @@ func (m *MongoDB) GetClient() any {
 	return m.Client
 }
 
+// Watch streams the changes to the `target` collection using change streams,
+// which require a replica set, or a sharded cluster. Event IDs are
+// `documentKey._id`, and documents are relaxed Extended JSON. Resume tokens
+// are supported.
+func (m *MongoDB) Watch(ctx context.Context, target string, opts ...storage.WatchFunc) <-chan storage.ChangeEvent {
+	o, err := storage.NewWatchOptions(opts...)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	trgt, err := shared.TargetName(target, m.Target)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	streamOptions := options.ChangeStream()
+
+	if o.FullDocument {
+		streamOptions.SetFullDocument(options.UpdateLookup)
+	}
+
+	if o.ResumeToken != "" {
+		streamOptions.SetResumeAfter(bson.M{"_data": o.ResumeToken})
+	}
+
+	stream, err := m.Client.Database(m.Database).Collection(trgt).Watch(ctx, mongo.Pipeline{}, streamOptions)
+	if err != nil {
+		return storage.FailedWatch(target, customerror.NewFailedToError("watch "+trgt, customerror.WithError(err)))
+	}
+
+	ch := make(chan storage.ChangeEvent, o.Buffer)
+
+	go func() {
+		defer close(ch)
+
+		// The stream is closed with its own context, `ctx` may be done.
+		defer stream.Close(context.Background())
+
+		for stream.Next(ctx) {
+			var change struct {
+				OperationType string              `bson:"operationType"`
+				DocumentKey   bson.M              `bson:"documentKey"`
+				FullDocument  bson.Raw            `bson:"fullDocument"`
+				ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+			}
+
+			if err := stream.Decode(&change); err != nil {
+				if !storage.SendChangeEvent(ctx, ch, storage.ChangeEvent{Target: target, Timestamp: time.Now(), Err: err}) {
+					return
+				}
+
+				continue
+			}
+
+			event := storage.ChangeEvent{
+				ID:        documentID(change.DocumentKey["_id"]),
+				Target:    target,
+				Timestamp: time.Unix(int64(change.ClusterTime.T), 0),
+			}
+
+			switch change.OperationType {
+			case "insert":
+				event.Operation = storage.OperationCreate
+			case "update", "replace":
+				event.Operation = storage.OperationUpdate
+			case "delete":
+				event.Operation = storage.OperationDelete
+			default:
+				continue
+			}
+
+			if token, ok := stream.ResumeToken().Lookup("_data").StringValueOK(); ok {
+				event.ResumeToken = token
+			}
+
+			if o.FullDocument && len(change.FullDocument) > 0 {
+				if b, err := bson.MarshalExtJSON(change.FullDocument, false, false); err == nil {
+					event.Document = b
+				}
+			}
+
+			if !storage.SendChangeEvent(ctx, ch, event) {
+				return
+			}
+		}
+
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			storage.SendChangeEvent(ctx, ch, storage.ChangeEvent{
+				Target:    target,
+				Timestamp: time.Now(),
+				Err:       customerror.NewFailedToError("watch "+trgt, customerror.WithError(err)),
+			})
+		}
+	}()
+
+	return ch
+}
+
 //////
 // Factory.
 //////
 
 // New creates a new MongoDB storage.
-func New(ctx context.Context, db string, cfg *Config) (*MongoDB, error) {
+func New(ctx context.Context, db string, cfg *Config, options ...storage.ConfigFunc) (*MongoDB, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*MongoDB)(nil)
 
-	s, err := storage.New(ctx, Name)
+	var _ storage.IWatcher = (*MongoDB)(nil)
+
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,74 @@
+package mongodb
+
+import (
+	"errors"
+
+	"github.com/thalesfsp/dal/v2/storage"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+//////
+// Exported functionalities.
+//////
+
+// IsTransientError reports whether `err` is worth retrying: network errors,
+// timeouts, errors labelled by the server as `RetryableWriteError`, or
+// `TransientTransactionError`, and whatever `storage.IsTransientError`
+// classifies.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err) {
+		return false
+	}
+
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
+	}
+
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) &&
+		(labeled.HasErrorLabel("RetryableWriteError") || labeled.HasErrorLabel("TransientTransactionError")) {
+		return true
+	}
+
+	return storage.IsTransientError(err)
+}
+
+// MongoDB server error codes.
+//
+// SEE https://www.mongodb.com/docs/manual/reference/error-codes/
+const (
+	errCodeUnauthorized  = 13
+	errCodeWriteConflict = 112
+)
+
+// ErrorKind maps `err` to a storage error kind: no documents to
+// `storage.ErrNotFound`, duplicate keys to `storage.ErrAlreadyExists`, write
+// conflicts to `storage.ErrConflict`, unauthorized to
+// `storage.ErrPermissionDenied`, timeouts to `storage.ErrTimeout`, and
+// transient errors to `storage.ErrUnavailable`.
+func ErrorKind(err error) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return storage.ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return storage.ErrAlreadyExists
+	case mongo.IsTimeout(err):
+		return storage.ErrTimeout
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		switch {
+		case serverErr.HasErrorCode(errCodeWriteConflict):
+			return storage.ErrConflict
+		case serverErr.HasErrorCode(errCodeUnauthorized):
+			return storage.ErrPermissionDenied
+		}
+	}
+
+	if IsTransientError(err) {
+		return storage.ErrUnavailable
+	}
+
+	return nil
+}
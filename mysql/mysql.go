@@ -16,13 +16,15 @@ import (
 	"github.com/eapache/go-resiliency/retrier"
 
 	// Import the mysql driver.
-	_ "github.com/go-sql-driver/mysql"
+	gomysql "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/thalesfsp/customerror"
 	"github.com/thalesfsp/dal/v2/internal/customapm"
 	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/internal/outbox"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/customsort"
@@ -57,6 +59,25 @@ var (
 // it.
 var driverName = Name
 
+// outboxDialect describes the outbox table. See `WithOutbox`.
+var outboxDialect = outbox.Dialect{
+	Name: Name,
+	Columns: `
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		operation VARCHAR(16) NOT NULL,
+		target VARCHAR(255) NOT NULL,
+		document_id VARCHAR(255) NOT NULL,
+		payload LONGTEXT NOT NULL,
+		created_at BIGINT NOT NULL,
+		available_at BIGINT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error VARCHAR(1024) NOT NULL DEFAULT '',
+		processed_at BIGINT,
+		failed_at BIGINT
+	`,
+	SkipLocked: true,
+}
+
 // Config is the MySQL configuration.
 type Config struct {
 	DataSourceName string `json:"dataSourceName" validate:"required"`
@@ -107,6 +128,23 @@ func ToSQLString(s customsort.Sort) (string, error) {
 	return sqlFormatted, nil
 }
 
+// database returns the database context of the spans of `trgt`: the database,
+// and the user are parsed from the data source name.
+func (m *MySQL) database(trgt string) tracing.Database {
+	db := tracing.Database{Collection: trgt}
+
+	if m.Config == nil {
+		return db
+	}
+
+	if cfg, err := gomysql.ParseDSN(m.Config.DataSourceName); err == nil {
+		db.Name = cfg.DBName
+		db.User = cfg.User
+	}
+
+	return db
+}
+
 func (m *MySQL) createDB(ctx context.Context, name string) error {
 	if _, err := m.
 		Client.
@@ -156,6 +194,15 @@ func (m *MySQL) createTable(ctx context.Context, name, description string) error
 
 // Count returns the number of items in the storage.
 func (m *MySQL) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, m, storage.OperationCount, target, "")
+
+	c, err := m.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (m *MySQL) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -193,6 +240,8 @@ func (m *MySQL) Count(ctx context.Context, target string, prm *count.Count, opti
 		return 0, customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(m.database(trgt))
+
 	//////
 	// Params initialization.
 	//////
@@ -229,6 +278,8 @@ func (m *MySQL) Count(ctx context.Context, target string, prm *count.Count, opti
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: finalParam.Search})
+
 	var count int64
 	if err = m.Client.QueryRowContext(ctx, finalParam.Search).Scan(&count); err != nil {
 		return 0, customapm.TraceError(
@@ -267,6 +318,13 @@ func (m *MySQL) Count(ctx context.Context, target string, prm *count.Count, opti
 
 // Delete removes data.
 func (m *MySQL) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, m, storage.OperationDelete, target, id)
+
+	return o.Done(nil, m.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (m *MySQL) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -326,6 +384,8 @@ func (m *MySQL) Delete(ctx context.Context, id, target string, prm *delete.Delet
 		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(m.database(trgt))
+
 	//////
 	// Delete.
 	//////
@@ -349,7 +409,17 @@ func (m *MySQL) Delete(ctx context.Context, id, target string, prm *delete.Delet
 		)
 	}
 
-	if _, err := m.Client.ExecContext(ctx, selectSQL, args...); err != nil {
+	span.SetDatabase(tracing.Database{Statement: selectSQL})
+
+	db, tx, err := outbox.Begin(ctx, m.Client, o.OutboxTable)
+	if err != nil {
+		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterDeletedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
+	res, err := db.ExecContext(ctx, selectSQL, args...)
+	if err != nil {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(storage.OperationDelete.String(), customerror.WithError(err)),
@@ -358,6 +428,19 @@ func (m *MySQL) Delete(ctx context.Context, id, target string, prm *delete.Delet
 		)
 	}
 
+	rowsAffected, err := res.RowsAffected()
+	if err == nil {
+		span.SetRows(rowsAffected)
+	}
+
+	// Deleting nothing isn't a mutation: no event, and the transaction - if
+	// any - is rolled back.
+	if err != nil || rowsAffected > 0 {
+		if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationDelete, trgt, id, nil); err != nil {
+			return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterDeletedFailed())
+		}
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, id, trgt, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterDeletedFailed())
@@ -386,6 +469,13 @@ func (m *MySQL) Delete(ctx context.Context, id, target string, prm *delete.Delet
 
 // Retrieve data.
 func (m *MySQL) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, m, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, m.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (m *MySQL) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -445,6 +535,8 @@ func (m *MySQL) Retrieve(ctx context.Context, id, target string, v any, prm *ret
 		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(m.database(trgt))
+
 	//////
 	// Retrieve.
 	//////
@@ -469,6 +561,8 @@ func (m *MySQL) Retrieve(ctx context.Context, id, target string, v any, prm *ret
 		)
 	}
 
+	span.SetDatabase(tracing.Database{Statement: selectSQL})
+
 	// Execute the query.
 	if err := m.Client.GetContext(ctx, v, selectSQL, args...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -488,6 +582,8 @@ func (m *MySQL) Retrieve(ctx context.Context, id, target string, v any, prm *ret
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterRetrievedFailed())
@@ -518,6 +614,13 @@ func (m *MySQL) Retrieve(ctx context.Context, id, target string, v any, prm *ret
 //
 // NOTE: It uses param.List.Search to query the data.
 func (m *MySQL) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, m, storage.OperationList, target, "")
+
+	return o.Done(v, m.list(ctx, target, v, prm, options...), ErrorKind)
+}
+
+// list is List's implementation. See List.
+func (m *MySQL) list(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -555,6 +658,8 @@ func (m *MySQL) List(ctx context.Context, target string, v any, prm *list.List,
 		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(m.database(trgt))
+
 	//////
 	// Params initialization.
 	//////
@@ -591,6 +696,8 @@ func (m *MySQL) List(ctx context.Context, target string, v any, prm *list.List,
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: finalParam.Search})
+
 	if err := m.Client.SelectContext(ctx, v, finalParam.Search); err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -600,6 +707,8 @@ func (m *MySQL) List(ctx context.Context, target string, v any, prm *list.List,
 		)
 	}
 
+	span.SetRows(shared.Rows(v))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, "", trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterListedFailed())
@@ -628,11 +737,23 @@ func (m *MySQL) List(ctx context.Context, target string, v any, prm *list.List,
 
 // Create data.
 //
+// NOTE: It's insert-only, failing with `storage.ErrAlreadyExists` on a
+// duplicated key. Use `storage.WithOverwrite` to replace the row instead.
+//
 // NOTE: MySQL does not support RETURNING clause. This method uses
 // LastInsertId() to retrieve the auto-generated ID. If you use non-numeric
 // IDs (e.g., UUIDs), set the ID yourself before calling Create and it will
 // be returned as-is.
 func (m *MySQL) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, m, storage.OperationCreate, target, id)
+
+	createdID, err := m.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+func (m *MySQL) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -683,6 +804,8 @@ func (m *MySQL) Create(ctx context.Context, id, target string, v any, prm *creat
 		return "", customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(m.database(trgt))
+
 	//////
 	// Create.
 	//////
@@ -710,8 +833,24 @@ func (m *MySQL) Create(ctx context.Context, id, target string, v any, prm *creat
 		)
 	}
 
+	// Insert-only, unless asked to overwrite. goqu renders upserts as
+	// `INSERT IGNORE ... ON DUPLICATE KEY UPDATE`, which downgrades any other
+	// error to a warning, so `REPLACE` is used instead.
+	if o.Overwrite {
+		insertSQL = "REPLACE" + strings.TrimPrefix(insertSQL, "INSERT")
+	}
+
+	span.SetDatabase(tracing.Database{Statement: insertSQL})
+
+	db, tx, err := outbox.Begin(ctx, m.Client, o.OutboxTable)
+	if err != nil {
+		return "", customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCreatedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
 	// Execute the query.
-	result, err := m.Client.ExecContext(ctx, insertSQL, args...)
+	result, err := db.ExecContext(ctx, insertSQL, args...)
 	if err != nil {
 		return "", customapm.TraceError(
 			ctx,
@@ -721,6 +860,10 @@ func (m *MySQL) Create(ctx context.Context, id, target string, v any, prm *creat
 		)
 	}
 
+	if rowsAffected, err := result.RowsAffected(); err == nil {
+		span.SetRows(rowsAffected)
+	}
+
 	// If the caller provided an ID, return it as-is (non-auto-increment case).
 	// Otherwise, retrieve the auto-generated ID.
 	returnedID := id
@@ -738,6 +881,10 @@ func (m *MySQL) Create(ctx context.Context, id, target string, v any, prm *creat
 		returnedID = strconv.FormatInt(lastID, 10)
 	}
 
+	if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationCreate, trgt, returnedID, v); err != nil {
+		return "", customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterCreatedFailed())
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, id, trgt, v, finalParam); err != nil {
 			return "", err
@@ -766,6 +913,13 @@ func (m *MySQL) Create(ctx context.Context, id, target string, v any, prm *creat
 
 // Update data.
 func (m *MySQL) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, m, storage.OperationUpdate, target, id)
+
+	return o.Done(v, m.update(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (m *MySQL) update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -825,6 +979,8 @@ func (m *MySQL) Update(ctx context.Context, id, target string, v any, prm *updat
 		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(m.database(trgt))
+
 	//////
 	// Update.
 	//////
@@ -849,7 +1005,16 @@ func (m *MySQL) Update(ctx context.Context, id, target string, v any, prm *updat
 		)
 	}
 
-	res, err := m.Client.ExecContext(ctx, updateSQL, args...)
+	span.SetDatabase(tracing.Database{Statement: updateSQL})
+
+	db, tx, err := outbox.Begin(ctx, m.Client, o.OutboxTable)
+	if err != nil {
+		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterUpdatedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
+	res, err := db.ExecContext(ctx, updateSQL, args...)
 	if err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -859,8 +1024,13 @@ func (m *MySQL) Update(ctx context.Context, id, target string, v any, prm *updat
 		)
 	}
 
+	rowsAffected, err := res.RowsAffected()
+	if err == nil {
+		span.SetRows(rowsAffected)
+	}
+
 	// Surface updates that matched nothing as 404, consistent with Retrieve.
-	if rowsAffected, err := res.RowsAffected(); err == nil && rowsAffected == 0 {
+	if err == nil && rowsAffected == 0 {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewHTTPError(http.StatusNotFound),
@@ -869,6 +1039,10 @@ func (m *MySQL) Update(ctx context.Context, id, target string, v any, prm *updat
 		)
 	}
 
+	if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationUpdate, trgt, id, v); err != nil {
+		return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterUpdatedFailed())
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, m, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, m.GetLogger(), m.GetCounterUpdatedFailed())
@@ -900,6 +1074,20 @@ func (m *MySQL) GetClient() any {
 	return m.Client
 }
 
+// CreateOutbox creates the outbox `table`, if it doesn't exist. See
+// `storage.WithOutbox`.
+func (m *MySQL) CreateOutbox(ctx context.Context, table string) error {
+	return outbox.CreateTable(ctx, m.Client, outboxDialect, table)
+}
+
+// RelayOutbox claims, and delivers pending events of the outbox `table`. See
+// `storage.Relay`.
+//
+// NOTE: Claiming with `SKIP LOCKED` requires MySQL 8.0+.
+func (m *MySQL) RelayOutbox(ctx context.Context, table string, limit int, deliver storage.OutboxDeliverFunc) (int, error) {
+	return outbox.Relay(ctx, m.Client, outboxDialect, table, limit, deliver)
+}
+
 //////
 // Factory.
 //////
@@ -913,11 +1101,13 @@ func (m *MySQL) GetClient() any {
 // For MariaDB on AWS RDS, this would be:
 //
 //	admin:password@tcp(mydb.csrubqc9tdmh.us-east-1.rds.amazonaws.com:3306)/ringboost?parseTime=true
-func New(ctx context.Context, dataSource string) (*MySQL, error) {
+func New(ctx context.Context, dataSource string, options ...storage.ConfigFunc) (*MySQL, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*MySQL)(nil)
 
-	s, err := storage.New(ctx, Name)
+	var _ storage.IOutbox = (*MySQL)(nil)
+
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
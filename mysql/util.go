@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// MySQL server error numbers.
+//
+// SEE https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	errDBAccessDenied     = 1044
+	errAccessDenied       = 1045
+	errBadNull            = 1048
+	errDupEntry           = 1062
+	errNoSuchTable        = 1146
+	errTableAccessDenied  = 1142
+	errDataTooLong        = 1406
+	errRowIsReferenced    = 1451
+	errNoReferencedRow    = 1452
+	errTooManyConnections = 1040
+	errServerShutdown     = 1053
+	errLockWaitTimeout    = 1205
+	errLockDeadlock       = 1213
+	errConnectionKilled   = 1927
+)
+
+//////
+// Exported functionalities.
+//////
+
+// IsTransientError reports whether `err` is worth retrying: deadlocks, lock
+// wait timeouts, too many connections, server shutdown, killed or invalid
+// connections, and whatever `storage.IsTransientError` classifies.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, gomysql.ErrInvalidConn) {
+		return true
+	}
+
+	var mysqlErr *gomysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errTooManyConnections,
+			errServerShutdown,
+			errLockWaitTimeout,
+			errLockDeadlock,
+			errConnectionKilled:
+			return true
+		}
+
+		return false
+	}
+
+	return storage.IsTransientError(err)
+}
+
+// ErrorKind maps `err` to a storage error kind: duplicate entries to
+// `storage.ErrAlreadyExists`, deadlocks to `storage.ErrConflict`, lock wait
+// timeouts to `storage.ErrTimeout`, null, too long, and foreign key violations
+// to `storage.ErrInvalidArgument`, access denied to
+// `storage.ErrPermissionDenied`, missing tables, and no rows to
+// `storage.ErrNotFound`, and transient errors to `storage.ErrUnavailable`.
+func ErrorKind(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+
+	var mysqlErr *gomysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errDupEntry:
+			return storage.ErrAlreadyExists
+		case errLockDeadlock:
+			return storage.ErrConflict
+		case errLockWaitTimeout:
+			return storage.ErrTimeout
+		case errBadNull, errDataTooLong, errRowIsReferenced, errNoReferencedRow:
+			return storage.ErrInvalidArgument
+		case errDBAccessDenied, errAccessDenied, errTableAccessDenied:
+			return storage.ErrPermissionDenied
+		case errNoSuchTable:
+			return storage.ErrNotFound
+		}
+	}
+
+	if IsTransientError(err) {
+		return storage.ErrUnavailable
+	}
+
+	return nil
+}
@@ -3,10 +3,14 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
+	"time"
 
 	// Import the postgres driver.
 	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
@@ -18,8 +22,10 @@ import (
 	"github.com/thalesfsp/customerror"
 	"github.com/thalesfsp/dal/v2/internal/customapm"
 	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/internal/outbox"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/customsort"
@@ -54,6 +60,59 @@ var (
 // it.
 var driverName = Name
 
+// outboxDialect describes the outbox table. See `WithOutbox`.
+var outboxDialect = outbox.Dialect{
+	Name: Name,
+	Columns: `
+		id BIGSERIAL PRIMARY KEY,
+		operation VARCHAR(16) NOT NULL,
+		target VARCHAR(255) NOT NULL,
+		document_id VARCHAR(255) NOT NULL,
+		payload TEXT NOT NULL,
+		created_at BIGINT NOT NULL,
+		available_at BIGINT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error VARCHAR(1024) NOT NULL DEFAULT '',
+		processed_at BIGINT,
+		failed_at BIGINT
+	`,
+	SkipLocked: true,
+}
+
+// notifyFunction is the trigger function `Watch` installs. It notifies the
+// `dal_<table>` channel of every change, with the row, unless the payload
+// would exceed the NOTIFY limit (8000 bytes).
+const notifyFunction = `
+CREATE OR REPLACE FUNCTION dal_notify() RETURNS trigger AS $$
+DECLARE
+	rec record;
+	payload text;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		rec := OLD;
+	ELSE
+		rec := NEW;
+	END IF;
+
+	payload := json_build_object('operation', lower(TG_OP), 'id', rec.id::text, 'document', row_to_json(rec))::text;
+
+	IF octet_length(payload) > 7900 THEN
+		payload := json_build_object('operation', lower(TG_OP), 'id', rec.id::text)::text;
+	END IF;
+
+	PERFORM pg_notify('dal_' || TG_TABLE_NAME, payload);
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`
+
+// notification is the payload sent by `notifyFunction`.
+type notification struct {
+	Operation string          `json:"operation"`
+	ID        string          `json:"id"`
+	Document  json.RawMessage `json:"document"`
+}
+
 // Config is the postgres configuration.
 type Config struct {
 	DataSourceName string `json:"dataSourceName" validate:"required"`
@@ -84,6 +143,13 @@ type Postgres struct {
 // Helpers.
 //////
 
+// notifyChannel returns the channel `notifyFunction` notifies the changes of
+// the `table` to: `TG_TABLE_NAME` is unqualified, and - the table being
+// unquoted - lowercase.
+func notifyChannel(table string) string {
+	return "dal_" + strings.ToLower(table[strings.LastIndex(table, ".")+1:])
+}
+
 // ToSQLString converts the `s` to a format that can be used in a SQL query.
 //
 // NOTE: Keywords in SQL are case-insensitive for the most popular DBMSs.
@@ -98,6 +164,39 @@ func ToSQLString(s customsort.Sort) (string, error) {
 	return sqlFormatted, nil
 }
 
+// database returns the database context of the spans of `trgt`: the database,
+// and the user are parsed from the data source name, either an URL, or
+// key/value pairs.
+func (p *Postgres) database(trgt string) tracing.Database {
+	db := tracing.Database{Collection: trgt}
+
+	if p.Config == nil {
+		return db
+	}
+
+	dsn := p.Config.DataSourceName
+
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		db.Name = strings.TrimPrefix(u.Path, "/")
+		db.User = u.User.Username()
+
+		return db
+	}
+
+	for _, pair := range strings.Fields(dsn) {
+		key, value, _ := strings.Cut(pair, "=")
+
+		switch key {
+		case "dbname":
+			db.Name = strings.Trim(value, "'")
+		case "user":
+			db.User = strings.Trim(value, "'")
+		}
+	}
+
+	return db
+}
+
 func (p *Postgres) createDB(ctx context.Context, name string) error {
 	if _, err := p.
 		Client.
@@ -147,6 +246,15 @@ func (p *Postgres) createTable(ctx context.Context, name, description string) er
 
 // Count returns the number of items in the storage.
 func (p *Postgres) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, p, storage.OperationCount, target, "")
+
+	c, err := p.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (p *Postgres) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -184,6 +292,8 @@ func (p *Postgres) Count(ctx context.Context, target string, prm *count.Count, o
 		return 0, customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Params initialization.
 	//////
@@ -220,6 +330,8 @@ func (p *Postgres) Count(ctx context.Context, target string, prm *count.Count, o
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: finalParam.Search})
+
 	var count int64
 	if err = p.Client.QueryRowContext(ctx, finalParam.Search).Scan(&count); err != nil {
 		return 0, customapm.TraceError(
@@ -258,6 +370,13 @@ func (p *Postgres) Count(ctx context.Context, target string, prm *count.Count, o
 
 // Delete removes data.
 func (p *Postgres) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, p, storage.OperationDelete, target, id)
+
+	return o.Done(nil, p.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (p *Postgres) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -317,6 +436,8 @@ func (p *Postgres) Delete(ctx context.Context, id, target string, prm *delete.De
 		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Delete.
 	//////
@@ -340,7 +461,17 @@ func (p *Postgres) Delete(ctx context.Context, id, target string, prm *delete.De
 		)
 	}
 
-	if _, err := p.Client.ExecContext(ctx, selectSQL, args...); err != nil {
+	span.SetDatabase(tracing.Database{Statement: selectSQL})
+
+	db, tx, err := outbox.Begin(ctx, p.Client, o.OutboxTable)
+	if err != nil {
+		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterDeletedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
+	res, err := db.ExecContext(ctx, selectSQL, args...)
+	if err != nil {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(storage.OperationDelete.String(), customerror.WithError(err)),
@@ -349,6 +480,19 @@ func (p *Postgres) Delete(ctx context.Context, id, target string, prm *delete.De
 		)
 	}
 
+	rowsAffected, err := res.RowsAffected()
+	if err == nil {
+		span.SetRows(rowsAffected)
+	}
+
+	// Deleting nothing isn't a mutation: no event, and the transaction - if
+	// any - is rolled back.
+	if err != nil || rowsAffected > 0 {
+		if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationDelete, trgt, id, nil); err != nil {
+			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterDeletedFailed())
+		}
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, id, trgt, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterDeletedFailed())
@@ -377,6 +521,13 @@ func (p *Postgres) Delete(ctx context.Context, id, target string, prm *delete.De
 
 // Retrieve data.
 func (p *Postgres) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, p, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, p.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (p *Postgres) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -436,6 +587,8 @@ func (p *Postgres) Retrieve(ctx context.Context, id, target string, v any, prm *
 		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Retrieve.
 	//////
@@ -460,6 +613,8 @@ func (p *Postgres) Retrieve(ctx context.Context, id, target string, v any, prm *
 		)
 	}
 
+	span.SetDatabase(tracing.Database{Statement: selectSQL})
+
 	// Execute the query.
 	if err := p.Client.GetContext(ctx, v, selectSQL, args...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -479,6 +634,8 @@ func (p *Postgres) Retrieve(ctx context.Context, id, target string, v any, prm *
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterRetrievedFailed())
@@ -509,6 +666,13 @@ func (p *Postgres) Retrieve(ctx context.Context, id, target string, v any, prm *
 //
 // NOTE: It uses param.List.Search to query the data.
 func (p *Postgres) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, p, storage.OperationList, target, "")
+
+	return o.Done(v, p.list(ctx, target, v, prm, options...), ErrorKind)
+}
+
+// list is List's implementation. See List.
+func (p *Postgres) list(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -546,6 +710,8 @@ func (p *Postgres) List(ctx context.Context, target string, v any, prm *list.Lis
 		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Params initialization.
 	//////
@@ -582,6 +748,8 @@ func (p *Postgres) List(ctx context.Context, target string, v any, prm *list.Lis
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: finalParam.Search})
+
 	if err := p.Client.SelectContext(ctx, v, finalParam.Search); err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -591,6 +759,8 @@ func (p *Postgres) List(ctx context.Context, target string, v any, prm *list.Lis
 		)
 	}
 
+	span.SetRows(shared.Rows(v))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, "", trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterListedFailed())
@@ -619,9 +789,21 @@ func (p *Postgres) List(ctx context.Context, target string, v any, prm *list.Lis
 
 // Create data.
 //
+// NOTE: It's insert-only, failing with `storage.ErrAlreadyExists` on a
+// duplicated key. Use `storage.WithOverwrite` to upsert instead.
+//
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set. You are better off setting the ID yourself.
 func (p *Postgres) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, p, storage.OperationCreate, target, id)
+
+	createdID, err := p.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+func (p *Postgres) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -672,6 +854,8 @@ func (p *Postgres) Create(ctx context.Context, id, target string, v any, prm *cr
 		return "", customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Create.
 	//////
@@ -688,6 +872,11 @@ func (p *Postgres) Create(ctx context.Context, id, target string, v any, prm *cr
 		Rows(v).
 		Returning("id")
 
+	// Insert-only, unless asked to overwrite.
+	if o.Overwrite {
+		ds = ds.OnConflict(goqu.DoUpdate("id", v))
+	}
+
 	// Convert the query to SQL, and arguments.
 	insertSQL, args, err := ds.ToSQL()
 	if err != nil {
@@ -699,9 +888,18 @@ func (p *Postgres) Create(ctx context.Context, id, target string, v any, prm *cr
 		)
 	}
 
+	span.SetDatabase(tracing.Database{Statement: insertSQL})
+
 	// Execute the query.
 	var returnedID string
-	if err := p.Client.QueryRowContext(ctx, insertSQL, args...).Scan(&returnedID); err != nil {
+	db, tx, err := outbox.Begin(ctx, p.Client, o.OutboxTable)
+	if err != nil {
+		return "", customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterCreatedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
+	if err := db.QueryRowxContext(ctx, insertSQL, args...).Scan(&returnedID); err != nil {
 		return "", customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(storage.OperationCreate.String(), customerror.WithError(err)),
@@ -710,6 +908,12 @@ func (p *Postgres) Create(ctx context.Context, id, target string, v any, prm *cr
 		)
 	}
 
+	span.SetRows(1)
+
+	if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationCreate, trgt, returnedID, v); err != nil {
+		return "", customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterCreatedFailed())
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, id, trgt, v, finalParam); err != nil {
 			return "", err
@@ -738,6 +942,13 @@ func (p *Postgres) Create(ctx context.Context, id, target string, v any, prm *cr
 
 // Update data.
 func (p *Postgres) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, p, storage.OperationUpdate, target, id)
+
+	return o.Done(v, p.update(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (p *Postgres) update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -797,6 +1008,8 @@ func (p *Postgres) Update(ctx context.Context, id, target string, v any, prm *up
 		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Update.
 	//////
@@ -821,7 +1034,16 @@ func (p *Postgres) Update(ctx context.Context, id, target string, v any, prm *up
 		)
 	}
 
-	res, err := p.Client.ExecContext(ctx, updateSQL, args...)
+	span.SetDatabase(tracing.Database{Statement: updateSQL})
+
+	db, tx, err := outbox.Begin(ctx, p.Client, o.OutboxTable)
+	if err != nil {
+		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterUpdatedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
+	res, err := db.ExecContext(ctx, updateSQL, args...)
 	if err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -831,8 +1053,13 @@ func (p *Postgres) Update(ctx context.Context, id, target string, v any, prm *up
 		)
 	}
 
+	rowsAffected, err := res.RowsAffected()
+	if err == nil {
+		span.SetRows(rowsAffected)
+	}
+
 	// Surface updates that matched nothing as 404, consistent with Retrieve.
-	if rowsAffected, err := res.RowsAffected(); err == nil && rowsAffected == 0 {
+	if err == nil && rowsAffected == 0 {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewHTTPError(http.StatusNotFound),
@@ -841,6 +1068,10 @@ func (p *Postgres) Update(ctx context.Context, id, target string, v any, prm *up
 		)
 	}
 
+	if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationUpdate, trgt, id, v); err != nil {
+		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterUpdatedFailed())
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterUpdatedFailed())
@@ -872,16 +1103,170 @@ func (p *Postgres) GetClient() any {
 	return p.Client
 }
 
+// CreateOutbox creates the outbox `table`, if it doesn't exist. See
+// `storage.WithOutbox`.
+func (p *Postgres) CreateOutbox(ctx context.Context, table string) error {
+	return outbox.CreateTable(ctx, p.Client, outboxDialect, table)
+}
+
+// RelayOutbox claims, and delivers pending events of the outbox `table`. See
+// `storage.Relay`.
+func (p *Postgres) RelayOutbox(ctx context.Context, table string, limit int, deliver storage.OutboxDeliverFunc) (int, error) {
+	return outbox.Relay(ctx, p.Client, outboxDialect, table, limit, deliver)
+}
+
+// installNotifyTrigger installs `notifyFunction`, and the `dal_notify`
+// trigger on the `table`, in a transaction holding an advisory lock, so
+// concurrent watchers don't race.
+func (p *Postgres) installNotifyTrigger(ctx context.Context, table string) error {
+	tx, err := p.Client.BeginTxx(ctx, nil)
+	if err != nil {
+		return customerror.NewFailedToError("begin notify trigger transaction", customerror.WithError(err))
+	}
+
+	// No-op once committed.
+	defer func() { _ = tx.Rollback() }()
+
+	for _, statement := range []string{
+		"SELECT pg_advisory_xact_lock(hashtext('dal_notify'))",
+		notifyFunction,
+		fmt.Sprintf("DROP TRIGGER IF EXISTS dal_notify ON %s", table),
+		fmt.Sprintf(
+			"CREATE TRIGGER dal_notify AFTER INSERT OR UPDATE OR DELETE ON %s "+
+				"FOR EACH ROW EXECUTE FUNCTION dal_notify()",
+			table,
+		),
+	} {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return customerror.NewFailedToError("install notify trigger", customerror.WithError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return customerror.NewFailedToError("commit notify trigger", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// Watch streams the changes to the `target` table using `LISTEN/NOTIFY`. It
+// installs the `dal_notify` trigger on the table - requiring the privileges
+// to - which notifies the `dal_<table>` channel, the table name unqualified,
+// and lowercased as Postgres folds it. Event IDs are the `id` column.
+//
+// NOTE: Notifications are fire-and-forget, so events are lost while
+// disconnected, and resume tokens aren't supported. Documents over ~8KB are
+// omitted.
+func (p *Postgres) Watch(ctx context.Context, target string, options ...storage.WatchFunc) <-chan storage.ChangeEvent {
+	o, err := storage.NewWatchOptions(options...)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	if o.ResumeToken != "" {
+		return storage.FailedWatch(target, customerror.NewFailedToError(
+			"resume postgres watch",
+			customerror.WithError(storage.ErrUnsupported),
+		))
+	}
+
+	trgt, err := shared.TargetName(target, p.Target)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	// trgt is interpolated into the statements — reject anything that isn't a
+	// plain identifier (SQL injection guard).
+	if err := shared.ValidateSQLIdentifier(trgt); err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	if err := p.installNotifyTrigger(ctx, trgt); err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	channel := notifyChannel(trgt)
+
+	listener := pq.NewListener(p.Config.DataSourceName, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			p.GetLogger().Warnln("watch", trgt, "listener:", err)
+		}
+	})
+
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+
+		return storage.FailedWatch(target, customerror.NewFailedToError("listen "+trgt, customerror.WithError(err)))
+	}
+
+	ch := make(chan storage.ChangeEvent, o.Buffer)
+
+	go func() {
+		defer close(ch)
+		defer listener.Close()
+
+		for {
+			var n *pq.Notification
+
+			select {
+			case <-ctx.Done():
+				return
+			case n = <-listener.Notify:
+			}
+
+			// Reconnected, notifications sent meanwhile are lost.
+			if n == nil {
+				continue
+			}
+
+			var payload notification
+
+			if err := shared.Unmarshal([]byte(n.Extra), &payload); err != nil {
+				p.GetLogger().Warnln("watch", trgt, "invalid notification:", err)
+
+				continue
+			}
+
+			event := storage.ChangeEvent{ID: payload.ID, Target: target, Timestamp: time.Now()}
+
+			switch payload.Operation {
+			case "insert":
+				event.Operation = storage.OperationCreate
+			case "update":
+				event.Operation = storage.OperationUpdate
+			case "delete":
+				event.Operation = storage.OperationDelete
+			default:
+				continue
+			}
+
+			if o.FullDocument && event.Operation != storage.OperationDelete {
+				event.Document = payload.Document
+			}
+
+			if !storage.SendChangeEvent(ctx, ch, event) {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
 //////
 // Factory.
 //////
 
 // New creates a new postgres storage.
-func New(ctx context.Context, dataSource string) (*Postgres, error) {
+func New(ctx context.Context, dataSource string, options ...storage.ConfigFunc) (*Postgres, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*Postgres)(nil)
 
-	s, err := storage.New(ctx, Name)
+	var _ storage.IOutbox = (*Postgres)(nil)
+
+	var _ storage.IWatcher = (*Postgres)(nil)
+
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/lib/pq/pqerror"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+//////
+// Exported functionalities.
+//////
+
+// IsTransientError reports whether `err` is worth retrying: serialization
+// failures, deadlocks, and other transaction rollbacks (class 40), connection
+// exceptions (class 08), too many connections, lock not available, server
+// shutting down or starting up, and whatever `storage.IsTransientError`
+// classifies.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case pqerror.ClassTransactionRollback, pqerror.ClassConnectionException:
+			return true
+		}
+
+		switch pqErr.Code {
+		case pqerror.TooManyConnections,
+			pqerror.LockNotAvailable,
+			pqerror.AdminShutdown,
+			pqerror.CrashShutdown,
+			pqerror.CannotConnectNow:
+			return true
+		}
+
+		return false
+	}
+
+	return storage.IsTransientError(err)
+}
+
+// ErrorKind maps `err` to a storage error kind: unique violations to
+// `storage.ErrAlreadyExists`, other integrity, and data exceptions to
+// `storage.ErrInvalidArgument`, transaction rollbacks to `storage.ErrConflict`,
+// undefined tables, and no rows to `storage.ErrNotFound`, insufficient
+// privileges, and authorization failures to `storage.ErrPermissionDenied`,
+// cancelled queries to `storage.ErrTimeout`, unsupported features to
+// `storage.ErrUnsupported`, and transient errors to `storage.ErrUnavailable`.
+func ErrorKind(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return nil
+	}
+
+	switch pqErr.Code {
+	case pqerror.UniqueViolation:
+		return storage.ErrAlreadyExists
+	case pqerror.UndefinedTable:
+		return storage.ErrNotFound
+	case pqerror.InsufficientPrivilege:
+		return storage.ErrPermissionDenied
+	case pqerror.QueryCanceled:
+		return storage.ErrTimeout
+	case pqerror.FeatureNotSupported:
+		return storage.ErrUnsupported
+	}
+
+	switch pqErr.Code.Class() {
+	case pqerror.ClassIntegrityConstraintViolation, pqerror.ClassDataException:
+		return storage.ErrInvalidArgument
+	case pqerror.ClassTransactionRollback:
+		return storage.ErrConflict
+	case pqerror.ClassInvalidAuthorizationSpecification:
+		return storage.ErrPermissionDenied
+	}
+
+	if IsTransientError(err) {
+		return storage.ErrUnavailable
+	}
+
+	return nil
+}
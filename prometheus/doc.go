@@ -0,0 +1,4 @@
+// Package prometheus implements a Prometheus/OpenMetrics metrics sink for
+// storages. Set it with `storage.SetMetrics`, and serve `Handler` on
+// `/metrics`. The expvar counters remain the default, and keep working.
+package prometheus
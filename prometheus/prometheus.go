@@ -0,0 +1,318 @@
+package prometheus
+
+import (
+	"context"
+	"expvar"
+	"net/http"
+	"os"
+	"strings"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// Name of the sink.
+	Name = "prometheus"
+
+	// DefaultNamespace is the metrics namespace.
+	DefaultNamespace = "dal"
+)
+
+// Labels.
+const (
+	LabelOperation = "operation"
+	LabelOutcome   = "outcome"
+	LabelStorage   = "storage"
+	LabelTarget    = "target"
+)
+
+var (
+	// DefaultDurationBuckets are the operation duration histogram buckets, in
+	// seconds: 1ms to ~16s.
+	DefaultDurationBuckets = promclient.ExponentialBuckets(0.001, 2, 15)
+
+	// DefaultPayloadBuckets are the payload size histogram buckets, in bytes:
+	// 64B to 16MiB.
+	DefaultPayloadBuckets = promclient.ExponentialBuckets(64, 4, 10)
+)
+
+// TargetFunc maps the target of an operation to its label value. Use it to
+// bound the cardinality, e.g.: file paths.
+type TargetFunc func(storageName, target string) string
+
+// Func allows to set options.
+type Func func(p *Prometheus) error
+
+// Prometheus is a metrics sink which publishes the operations of every
+// storage as Prometheus metrics:
+//
+//   - `dal_storage_operations_total`: counter by storage, operation, target,
+//     and outcome. Same as the `GetCounter*` expvar counters, e.g.:
+//     `created`, and `created.failed`
+//   - `dal_storage_operation_duration_seconds`: histogram by storage,
+//     operation, target, and outcome
+//   - `dal_storage_operation_payload_bytes`: histogram by storage, operation,
+//     and target, of the documents written, and read
+//   - `dal_storage_ping_failures_total`, and
+//     `dal_storage_instantiation_failures_total`: counters by storage, read
+//     from the expvar counters, as they happen before any operation.
+type Prometheus struct {
+	// Namespace of the metrics.
+	Namespace string `json:"namespace" validate:"required,gte=1"`
+
+	// DurationBuckets of the duration histogram, in seconds.
+	DurationBuckets []float64 `json:"durationBuckets" validate:"required,gt=0"`
+
+	// PayloadBuckets of the payload histogram, in bytes.
+	PayloadBuckets []float64 `json:"payloadBuckets" validate:"required,gt=0"`
+
+	// TargetFunc maps targets to labels. Default is the target as-is.
+	TargetFunc TargetFunc `json:"-"`
+
+	registerer promclient.Registerer
+	gatherer   promclient.Gatherer
+
+	operations *promclient.CounterVec
+	duration   *promclient.HistogramVec
+	payload    *promclient.HistogramVec
+
+	pingFailures          *promclient.Desc
+	instantiationFailures *promclient.Desc
+}
+
+//////
+// Helpers.
+//////
+
+// storageCounter parses the name of an expvar counter created by
+// `storage.New`, e.g.: `storage.postgres.ping.failed.counter`, returning the
+// storage name.
+func storageCounter(varName, suffix string) (string, bool) {
+	if prefix := os.Getenv("DAL_METRICS_PREFIX"); prefix != "" {
+		var ok bool
+
+		if varName, ok = strings.CutPrefix(varName, prefix+"."); !ok {
+			return "", false
+		}
+	}
+
+	name, ok := strings.CutPrefix(varName, storage.Type+".")
+	if !ok {
+		return "", false
+	}
+
+	name, ok = strings.CutSuffix(name, "."+suffix+"."+storage.DefaultMetricCounterLabel)
+	if !ok || name == "" || strings.Contains(name, ".") {
+		return "", false
+	}
+
+	return name, true
+}
+
+//////
+// Implements the storage.IMetrics interface.
+//////
+
+// ObserveOperation records the operation.
+func (p *Prometheus) ObserveOperation(_ context.Context, m *storage.OperationMetric) {
+	target := m.Target
+	if p.TargetFunc != nil {
+		target = p.TargetFunc(m.Storage, m.Target)
+	}
+
+	op := m.Operation.String()
+
+	p.operations.WithLabelValues(m.Storage, op, target, string(m.Outcome)).Inc()
+	p.duration.WithLabelValues(m.Storage, op, target, string(m.Outcome)).Observe(m.Duration.Seconds())
+
+	if m.Payload != nil {
+		p.payload.WithLabelValues(m.Storage, op, target).Observe(float64(m.PayloadBytes()))
+	}
+}
+
+//////
+// Implements the prometheus.Collector interface.
+//////
+
+// Describe implements the prometheus.Collector interface.
+func (p *Prometheus) Describe(ch chan<- *promclient.Desc) {
+	p.operations.Describe(ch)
+	p.duration.Describe(ch)
+	p.payload.Describe(ch)
+
+	ch <- p.pingFailures
+	ch <- p.instantiationFailures
+}
+
+// Collect implements the prometheus.Collector interface.
+func (p *Prometheus) Collect(ch chan<- promclient.Metric) {
+	p.operations.Collect(ch)
+	p.duration.Collect(ch)
+	p.payload.Collect(ch)
+
+	expvar.Do(func(kv expvar.KeyValue) {
+		counter, ok := kv.Value.(*expvar.Int)
+		if !ok {
+			return
+		}
+
+		if name, ok := storageCounter(kv.Key, "ping.failed"); ok {
+			ch <- promclient.MustNewConstMetric(p.pingFailures, promclient.CounterValue, float64(counter.Value()), name)
+		}
+
+		if name, ok := storageCounter(kv.Key, "instantiation.failed"); ok {
+			ch <- promclient.MustNewConstMetric(p.instantiationFailures, promclient.CounterValue, float64(counter.Value()), name)
+		}
+	})
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Handler serves the metrics, in the Prometheus text format, or OpenMetrics
+// if negotiated.
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithNamespace sets the metrics namespace. Default is `DefaultNamespace`.
+func WithNamespace(namespace string) Func {
+	return func(p *Prometheus) error {
+		p.Namespace = namespace
+
+		return nil
+	}
+}
+
+// WithDurationBuckets sets the duration histogram buckets, in seconds.
+func WithDurationBuckets(buckets ...float64) Func {
+	return func(p *Prometheus) error {
+		p.DurationBuckets = buckets
+
+		return nil
+	}
+}
+
+// WithPayloadBuckets sets the payload histogram buckets, in bytes.
+func WithPayloadBuckets(buckets ...float64) Func {
+	return func(p *Prometheus) error {
+		p.PayloadBuckets = buckets
+
+		return nil
+	}
+}
+
+// WithTargetFunc maps targets to labels, e.g.: to bound the cardinality.
+func WithTargetFunc(fn TargetFunc) Func {
+	return func(p *Prometheus) error {
+		if fn == nil {
+			return customerror.NewRequiredError("target func")
+		}
+
+		p.TargetFunc = fn
+
+		return nil
+	}
+}
+
+// WithRegistry registers the metrics with `registerer`, and serves those from
+// `gatherer` in `Handler`, e.g.: `prometheus.DefaultRegisterer`, and
+// `prometheus.DefaultGatherer` to expose them along the Go runtime ones.
+// Default is a new registry.
+func WithRegistry(registerer promclient.Registerer, gatherer promclient.Gatherer) Func {
+	return func(p *Prometheus) error {
+		if registerer == nil || gatherer == nil {
+			return customerror.NewRequiredError("registerer, and gatherer")
+		}
+
+		p.registerer = registerer
+		p.gatherer = gatherer
+
+		return nil
+	}
+}
+
+//////
+// Factory.
+//////
+
+// New creates the Prometheus sink, and registers its metrics. Set it with
+// `storage.SetMetrics`.
+func New(opts ...Func) (*Prometheus, error) {
+	registry := promclient.NewRegistry()
+
+	p := &Prometheus{
+		Namespace:       DefaultNamespace,
+		DurationBuckets: DefaultDurationBuckets,
+		PayloadBuckets:  DefaultPayloadBuckets,
+
+		registerer: registry,
+		gatherer:   registry,
+	}
+
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validation.Validate(p); err != nil {
+		return nil, err
+	}
+
+	p.operations = promclient.NewCounterVec(promclient.CounterOpts{
+		Namespace: p.Namespace,
+		Subsystem: storage.Type,
+		Name:      "operations_total",
+		Help:      "Number of storage operations.",
+	}, []string{LabelStorage, LabelOperation, LabelTarget, LabelOutcome})
+
+	p.duration = promclient.NewHistogramVec(promclient.HistogramOpts{
+		Namespace: p.Namespace,
+		Subsystem: storage.Type,
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of storage operations.",
+		Buckets:   p.DurationBuckets,
+	}, []string{LabelStorage, LabelOperation, LabelTarget, LabelOutcome})
+
+	p.payload = promclient.NewHistogramVec(promclient.HistogramOpts{
+		Namespace: p.Namespace,
+		Subsystem: storage.Type,
+		Name:      "operation_payload_bytes",
+		Help:      "Size of the documents written, and read by storage operations.",
+		Buckets:   p.PayloadBuckets,
+	}, []string{LabelStorage, LabelOperation, LabelTarget})
+
+	p.pingFailures = promclient.NewDesc(
+		promclient.BuildFQName(p.Namespace, storage.Type, "ping_failures_total"),
+		"Number of failed storage pings.",
+		[]string{LabelStorage},
+		nil,
+	)
+
+	p.instantiationFailures = promclient.NewDesc(
+		promclient.BuildFQName(p.Namespace, storage.Type, "instantiation_failures_total"),
+		"Number of failed storage instantiations.",
+		[]string{LabelStorage},
+		nil,
+	)
+
+	if err := p.registerer.Register(p); err != nil {
+		return nil, customerror.NewFailedToError("register metrics", customerror.WithError(err))
+	}
+
+	return p, nil
+}
@@ -0,0 +1,103 @@
+package prometheus
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/dal/v2/memory"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+func TestPrometheus(t *testing.T) {
+	p, err := New()
+	require.NoError(t, err)
+
+	storage.SetMetrics(p)
+	t.Cleanup(func() { storage.SetMetrics() })
+
+	ctx := t.Context()
+
+	str, err := memory.New(ctx)
+	require.NoError(t, err)
+
+	_, err = str.Create(ctx, "prom-1", "users", shared.TestData, nil)
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, str.Delete(ctx, "prom-1", "users", nil))
+	}()
+
+	_, err = str.Create(ctx, "prom-1", "users", shared.TestData, nil)
+	require.ErrorIs(t, err, storage.ErrAlreadyExists)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(p.operations.WithLabelValues(memory.Name, "create", "users", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(p.operations.WithLabelValues(memory.Name, "create", "users", "failed")))
+
+	// Only the successful create has a payload.
+	assert.Equal(t, 1, testutil.CollectAndCount(p.payload))
+
+	rec := httptest.NewRecorder()
+	p.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	for _, metric := range []string{
+		`dal_storage_operations_total{operation="create",outcome="success",storage="memory",target="users"} 1`,
+		`dal_storage_operation_duration_seconds_count{operation="create",outcome="failed",storage="memory",target="users"} 1`,
+		`dal_storage_operation_payload_bytes_count{operation="create",storage="memory",target="users"} 1`,
+		`dal_storage_ping_failures_total{storage="memory"} 0`,
+	} {
+		assert.Contains(t, string(body), metric)
+	}
+}
+
+func TestPrometheus_TargetFunc(t *testing.T) {
+	p, err := New(WithNamespace("test"), WithTargetFunc(func(_, target string) string {
+		return strings.SplitN(target, "/", 2)[0]
+	}))
+	require.NoError(t, err)
+
+	p.ObserveOperation(t.Context(), &storage.OperationMetric{
+		Storage:   "file",
+		Operation: storage.OperationRetrieve,
+		Target:    "tenant/a/b.json",
+		Outcome:   storage.OutcomeSuccess,
+	})
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(p.operations.WithLabelValues("file", "retrieve", "tenant", "success")))
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	_, err := New(WithNamespace(""))
+	assert.Error(t, err)
+
+	_, err = New(WithDurationBuckets())
+	assert.Error(t, err)
+
+	_, err = New(WithTargetFunc(nil))
+	assert.Error(t, err)
+
+	_, err = New(WithRegistry(nil, nil))
+	assert.Error(t, err)
+}
+
+func TestStorageCounter(t *testing.T) {
+	name, ok := storageCounter("storage.postgres.ping.failed.counter", "ping.failed")
+	assert.True(t, ok)
+	assert.Equal(t, "postgres", name)
+
+	_, ok = storageCounter("storage.resilient.postgres.ping.failed.counter", "ping.failed")
+	assert.False(t, ok)
+
+	_, ok = storageCounter("storage.postgres.created.counter", "ping.failed")
+	assert.False(t, ok)
+}
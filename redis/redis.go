@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/eapache/go-resiliency/retrier"
 	"github.com/redis/go-redis/v9"
@@ -14,6 +17,7 @@ import (
 	"github.com/thalesfsp/dal/v2/internal/logging"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/delete"
@@ -31,8 +35,21 @@ import (
 // Const, vars, and types.
 //////
 
-// Name of the storage.
-const Name = "redis"
+const (
+	// Name of the storage.
+	Name = "redis"
+
+	// keyspaceEventsParameter is the parameter of the keyspace notifications.
+	keyspaceEventsParameter = "notify-keyspace-events"
+
+	// keyspaceEvents are the keyspace notifications needed by Watch: keyspace
+	// (K), generic (g, e.g.: del), string ($, e.g.: set), expired (x), and
+	// evicted (e) events.
+	keyspaceEvents = "Kg$xe"
+
+	// keyspaceEventsAll are the classes aliased by `A`.
+	keyspaceEventsAll = "g$lshzxetd"
+)
 
 // Singleton.
 var (
@@ -63,12 +80,65 @@ type Redis struct {
 	Target string `json:"-" validate:"omitempty,gt=0"`
 }
 
+//////
+// Helpers.
+//////
+
+// mergeKeyspaceEvents returns the `current` keyspace notifications, plus the
+// ones needed by Watch, and whether any was missing.
+func mergeKeyspaceEvents(current string) (string, bool) {
+	merged := current
+
+	for _, class := range keyspaceEvents {
+		if strings.ContainsRune(merged, class) ||
+			(strings.ContainsRune(merged, 'A') && strings.ContainsRune(keyspaceEventsAll, class)) {
+			continue
+		}
+
+		merged += string(class)
+	}
+
+	return merged, merged != current
+}
+
+// database returns the database context of the spans of the command `args`,
+// e.g.: `GET key`.
+func (r *Redis) database(args ...any) tracing.Database {
+	parts := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		if b, ok := arg.([]byte); ok {
+			arg = string(b)
+		}
+
+		parts = append(parts, fmt.Sprint(arg))
+	}
+
+	db := tracing.Database{Statement: strings.Join(parts, " ")}
+
+	if r.Config != nil {
+		db.Name = strconv.Itoa(r.Config.DB)
+		db.User = r.Config.Username
+	}
+
+	return db
+}
+
 //////
 // Implements the IStorage interface.
 //////
 
 // Count returns the number of items in the storage.
 func (r *Redis) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, r, storage.OperationCount, target, "")
+
+	c, err := r.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (r *Redis) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -132,6 +202,8 @@ func (r *Redis) Count(ctx context.Context, target string, prm *count.Count, opti
 	// block the server on large keyspaces.
 	var count int64
 
+	span.SetDatabase(r.database("SCAN", 0, "MATCH", pattern))
+
 	iter := r.Client.Scan(ctx, 0, pattern, 0).Iterator()
 
 	for iter.Next(ctx) {
@@ -178,6 +250,13 @@ func (r *Redis) Count(ctx context.Context, target string, prm *count.Count, opti
 
 // Delete removes data.
 func (r *Redis) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, r, storage.OperationDelete, target, id)
+
+	return o.Done(nil, r.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (r *Redis) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -215,6 +294,10 @@ func (r *Redis) Delete(ctx context.Context, id, target string, prm *delete.Delet
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterDeletedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -239,7 +322,10 @@ func (r *Redis) Delete(ctx context.Context, id, target string, prm *delete.Delet
 		}
 	}
 
-	if err := r.Client.Del(ctx, id).Err(); err != nil {
+	span.SetDatabase(r.database("DEL", id))
+
+	deleted, err := r.Client.Del(ctx, id).Result()
+	if err != nil {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(
@@ -250,6 +336,8 @@ func (r *Redis) Delete(ctx context.Context, id, target string, prm *delete.Delet
 			r.GetCounterDeletedFailed())
 	}
 
+	span.SetRows(deleted)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, r, id, target, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterDeletedFailed())
@@ -278,6 +366,13 @@ func (r *Redis) Delete(ctx context.Context, id, target string, prm *delete.Delet
 
 // Retrieve data.
 func (r *Redis) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, r, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, r.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (r *Redis) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -338,6 +433,8 @@ func (r *Redis) Retrieve(ctx context.Context, id, target string, v any, prm *ret
 		}
 	}
 
+	span.SetDatabase(r.database("GET", id))
+
 	objStr, err := r.Client.Get(ctx, id).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
@@ -365,6 +462,8 @@ func (r *Redis) Retrieve(ctx context.Context, id, target string, v any, prm *ret
 		}
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, r, id, target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterRetrievedFailed())
@@ -398,6 +497,13 @@ func (r *Redis) Retrieve(ctx context.Context, id, target string, v any, prm *ret
 // NOTE: Redis does not support the concept of "offset" and "limit" in the same
 // way that a traditional SQL database does.
 func (r *Redis) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, r, storage.OperationList, target, "")
+
+	return o.Done(v, r.list(ctx, target, v, prm, options...), ErrorKind)
+}
+
+// list is List's implementation. See List.
+func (r *Redis) list(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -452,6 +558,8 @@ func (r *Redis) List(ctx context.Context, target string, v any, prm *list.List,
 		}
 	}
 
+	span.SetDatabase(r.database("SCAN", 0, "MATCH", finalParam.Search))
+
 	iter := r.Client.Scan(ctx, 0, finalParam.Search, 0).Iterator()
 
 	keys := ResponseListKeys{[]string{}}
@@ -474,6 +582,8 @@ func (r *Redis) List(ctx context.Context, target string, v any, prm *list.List,
 		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterListedFailed())
 	}
 
+	span.SetRows(int64(len(keys.Keys)))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, r, "", target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterListedFailed())
@@ -502,9 +612,21 @@ func (r *Redis) List(ctx context.Context, target string, v any, prm *list.List,
 
 // Create data.
 //
+// NOTE: It's insert-only (`SET NX`), failing with `storage.ErrAlreadyExists`
+// if the key exists. Use `storage.WithOverwrite` to replace it.
+//
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set. You are better off setting the ID yourself.
 func (r *Redis) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, r, storage.OperationCreate, target, id)
+
+	createdID, err := r.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+func (r *Redis) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	// The id is the storage key — an empty one would create a record that
 	// Retrieve/Delete/Update (which reject empty ids) could never address.
 	if id == "" {
@@ -544,6 +666,10 @@ func (r *Redis) Create(ctx context.Context, id, target string, v any, prm *creat
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return "", customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCreatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -574,7 +700,20 @@ func (r *Redis) Create(ctx context.Context, id, target string, v any, prm *creat
 		return "", customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCreatedFailed())
 	}
 
-	if err := r.Client.Set(ctx, id, b, finalParam.TTL).Err(); err != nil {
+	// Insert-only, unless asked to overwrite.
+	created := true
+
+	if o.Overwrite {
+		span.SetDatabase(r.database("SET", id, b))
+
+		err = r.Client.Set(ctx, id, b, finalParam.TTL).Err()
+	} else {
+		span.SetDatabase(r.database("SET", id, b, "NX"))
+
+		created, err = r.Client.SetNX(ctx, id, b, finalParam.TTL).Result()
+	}
+
+	if err != nil {
 		return "", customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(
@@ -586,6 +725,20 @@ func (r *Redis) Create(ctx context.Context, id, target string, v any, prm *creat
 		)
 	}
 
+	if !created {
+		return "", customapm.TraceError(
+			ctx,
+			customerror.NewFailedToError(
+				storage.OperationCreate.String(),
+				customerror.WithError(storage.ErrAlreadyExists),
+			),
+			r.GetLogger(),
+			r.GetCounterCreatedFailed(),
+		)
+	}
+
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, r, id, target, v, finalParam); err != nil {
 			return "", customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCreatedFailed())
@@ -616,6 +769,13 @@ func (r *Redis) Create(ctx context.Context, id, target string, v any, prm *creat
 //
 // NOTE: Not truly an update, it's an insert.
 func (r *Redis) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, r, storage.OperationUpdate, target, id)
+
+	return o.Done(v, r.update(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (r *Redis) update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -653,6 +813,10 @@ func (r *Redis) Update(ctx context.Context, id, target string, v any, prm *updat
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterUpdatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -683,6 +847,8 @@ func (r *Redis) Update(ctx context.Context, id, target string, v any, prm *updat
 		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(r.database("SET", id, b))
+
 	if err := r.Client.Set(ctx, id, b, finalParam.TTL).Err(); err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -694,6 +860,8 @@ func (r *Redis) Update(ctx context.Context, id, target string, v any, prm *updat
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, r, id, target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterUpdatedFailed())
@@ -725,16 +893,131 @@ func (r *Redis) GetClient() any {
 	return r.Client
 }
 
+// enableKeyspaceEvents enables the keyspace notifications needed by Watch,
+// merged into the server's current ones, which are otherwise kept.
+//
+// NOTE: Best-effort: managed Redis often forbids `CONFIG`, in which case
+// notifications must be enabled by the operator.
+func (r *Redis) enableKeyspaceEvents(ctx context.Context) {
+	current, err := r.Client.ConfigGet(ctx, keyspaceEventsParameter).Result()
+	if err != nil {
+		r.GetLogger().Warnln("failed to read keyspace notifications:", err)
+
+		return
+	}
+
+	merged, changed := mergeKeyspaceEvents(current[keyspaceEventsParameter])
+	if !changed {
+		return
+	}
+
+	if err := r.Client.ConfigSet(ctx, keyspaceEventsParameter, merged).Err(); err != nil {
+		r.GetLogger().Warnln("failed to enable keyspace notifications:", err)
+	}
+}
+
+// Watch streams the changes to the keys matching the `target` glob pattern -
+// all keys if empty - using keyspace notifications, which it tries to enable
+// (`notify-keyspace-events KA`). Event IDs are keys.
+//
+// NOTE: Redis doesn't tell creations from updates, both are `update`s. Expired,
+// and evicted keys are `delete`s. Notifications are fire-and-forget, so events
+// are lost while disconnected, and resume tokens aren't supported.
+func (r *Redis) Watch(ctx context.Context, target string, options ...storage.WatchFunc) <-chan storage.ChangeEvent {
+	o, err := storage.NewWatchOptions(options...)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	if o.ResumeToken != "" {
+		return storage.FailedWatch(target, customerror.NewFailedToError(
+			"resume redis watch",
+			customerror.WithError(storage.ErrUnsupported),
+		))
+	}
+
+	pattern := target
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	r.enableKeyspaceEvents(ctx)
+
+	prefix := fmt.Sprintf("__keyspace@%d__:", r.Client.Options().DB)
+
+	pubsub := r.Client.PSubscribe(ctx, prefix+pattern)
+
+	// Waits for the subscription confirmation, surfacing connection errors.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+
+		return storage.FailedWatch(target, customerror.NewFailedToError("watch "+pattern, customerror.WithError(err)))
+	}
+
+	ch := make(chan storage.ChangeEvent, o.Buffer)
+
+	go func() {
+		defer close(ch)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+
+		for {
+			var msg *redis.Message
+
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				msg = m
+			}
+
+			event := storage.ChangeEvent{
+				ID:        strings.TrimPrefix(msg.Channel, prefix),
+				Target:    target,
+				Timestamp: time.Now(),
+			}
+
+			switch msg.Payload {
+			case "set":
+				event.Operation = storage.OperationUpdate
+			case "del", "expired", "evicted":
+				event.Operation = storage.OperationDelete
+			default:
+				continue
+			}
+
+			if o.FullDocument && event.Operation == storage.OperationUpdate {
+				if b, err := r.Client.Get(ctx, event.ID).Bytes(); err == nil {
+					event.Document = b
+				}
+			}
+
+			if !storage.SendChangeEvent(ctx, ch, event) {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
 //////
 // Factory.
 //////
 
 // New creates a new Redis storage.
-func New(ctx context.Context, cfg *Config) (*Redis, error) {
+func New(ctx context.Context, cfg *Config, options ...storage.ConfigFunc) (*Redis, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*Redis)(nil)
 
-	s, err := storage.New(ctx, Name)
+	var _ storage.IWatcher = (*Redis)(nil)
+
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -201,3 +201,26 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeKeyspaceEvents(t *testing.T) {
+	tests := []struct {
+		current string
+		want    string
+		changed bool
+	}{
+		{current: "", want: "Kg$xe", changed: true},
+		{current: "KA", want: "KA", changed: false},
+		{current: "Ex", want: "ExKg$e", changed: true},
+		{current: "EA", want: "EAK", changed: true},
+		{current: "Kg$xe", want: "Kg$xe", changed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			got, changed := mergeKeyspaceEvents(tt.current)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.changed, changed)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"errors"
+	"io"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+//////
+// Exported functionalities.
+//////
+
+// IsTransientError reports whether `err` is worth retrying: dropped
+// connections, pool timeouts, a server which is loading, read-only (failover),
+// or whose cluster is down, TRYAGAIN, MASTERDOWN, max clients reached, and
+// whatever `storage.IsTransientError` classifies.
+//
+// NOTE: `redis.Nil` (key not found), and a closed client aren't transient.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, redis.ErrClosed) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) ||
+		errors.Is(err, redis.ErrPoolTimeout) ||
+		errors.Is(err, redis.ErrPoolExhausted) ||
+		redis.IsLoadingError(err) ||
+		redis.IsReadOnlyError(err) ||
+		redis.IsClusterDownError(err) ||
+		redis.IsTryAgainError(err) ||
+		redis.IsMasterDownError(err) ||
+		redis.IsMaxClientsError(err) {
+		return true
+	}
+
+	return storage.IsTransientError(err)
+}
+
+// ErrorKind maps `err` to a storage error kind: `redis.Nil` to
+// `storage.ErrNotFound`, authentication, and ACL errors to
+// `storage.ErrPermissionDenied`, WRONGTYPE to `storage.ErrInvalidArgument`, and
+// a closed client, and transient errors to `storage.ErrUnavailable`.
+func ErrorKind(err error) error {
+	switch {
+	case errors.Is(err, redis.Nil):
+		return storage.ErrNotFound
+	case redis.IsAuthError(err), redis.IsPermissionError(err):
+		return storage.ErrPermissionDenied
+	case redis.HasErrorPrefix(err, "WRONGTYPE"):
+		return storage.ErrInvalidArgument
+	case errors.Is(err, redis.ErrClosed), IsTransientError(err):
+		return storage.ErrUnavailable
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -21,6 +22,7 @@ import (
 	"github.com/thalesfsp/dal/v2/internal/logging"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/delete"
@@ -76,12 +78,38 @@ type S3 struct {
 	uploader *s3manager.Uploader
 }
 
+//////
+// Helpers.
+//////
+
+// ifNoneMatch makes the object write conditional to the key's absence.
+func ifNoneMatch(r *request.Request) {
+	switch r.Operation.Name {
+	case "PutObject", "CompleteMultipartUpload":
+		r.HTTPRequest.Header.Set("If-None-Match", "*")
+	}
+}
+
+// database returns the database context of the spans of `trgt`.
+func (s *S3) database(trgt string) tracing.Database {
+	return tracing.Database{Name: s.Bucket, Collection: trgt}
+}
+
 //////
 // Implements the IStorage interface.
 //////
 
 // Count returns the number of items in the storage.
 func (s *S3) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, s, storage.OperationCount, target, "")
+
+	c, err := s.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (s *S3) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -136,6 +164,8 @@ func (s *S3) Count(ctx context.Context, target string, prm *count.Count, options
 		}
 	}
 
+	span.SetDatabase(s.database(""))
+
 	input := &s3.ListObjectsV2Input{
 		Bucket: aws.String(s.Bucket),
 	}
@@ -181,6 +211,13 @@ func (s *S3) Count(ctx context.Context, target string, prm *count.Count, options
 
 // Delete removes data.
 func (s *S3) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationDelete, target, id)
+
+	return o.Done(nil, s.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (s *S3) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -218,6 +255,10 @@ func (s *S3) Delete(ctx context.Context, id, target string, prm *delete.Delete,
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -241,6 +282,8 @@ func (s *S3) Delete(ctx context.Context, id, target string, prm *delete.Delete,
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(s.database(trgt))
+
 	//////
 	// Delete.
 	//////
@@ -267,6 +310,8 @@ func (s *S3) Delete(ctx context.Context, id, target string, prm *delete.Delete,
 			s.GetCounterDeletedFailed())
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
@@ -295,6 +340,13 @@ func (s *S3) Delete(ctx context.Context, id, target string, prm *delete.Delete,
 
 // Retrieve data.
 func (s *S3) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, s.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (s *S3) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -354,6 +406,8 @@ func (s *S3) Retrieve(ctx context.Context, id, target string, v any, prm *retrie
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(s.database(trgt))
+
 	//////
 	// Retrieve.
 	//////
@@ -410,6 +464,8 @@ func (s *S3) Retrieve(ctx context.Context, id, target string, v any, prm *retrie
 			s.GetCounterRetrievedFailed())
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
@@ -443,6 +499,13 @@ func (s *S3) Retrieve(ctx context.Context, id, target string, v any, prm *retrie
 // NOTE: S3 does not support the concept of "offset" and "limit" in the same
 // way that a traditional SQL database does.
 func (s *S3) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationList, target, "")
+
+	return o.Done(v, s.list(ctx, target, v, prm, options...), ErrorKind)
+}
+
+// list is List's implementation. See List.
+func (s *S3) list(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -497,6 +560,8 @@ func (s *S3) List(ctx context.Context, target string, v any, prm *list.List, opt
 		}
 	}
 
+	span.SetDatabase(s.database(""))
+
 	input := &s3.ListObjectsV2Input{
 		Bucket: aws.String(s.Bucket),
 	}
@@ -523,6 +588,8 @@ func (s *S3) List(ctx context.Context, target string, v any, prm *list.List, opt
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
 	}
 
+	span.SetRows(int64(len(keys.Keys)))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, "", target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
@@ -553,9 +620,22 @@ func (s *S3) List(ctx context.Context, target string, v any, prm *list.List, opt
 //
 // NOTE: `v` can be a file, a string, or an struct.
 //
+// NOTE: It's insert-only (`If-None-Match: *`), failing with
+// `storage.ErrAlreadyExists` if the object exists. Use `storage.WithOverwrite`
+// to replace it.
+//
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set. You are better off setting the ID yourself.
 func (s *S3) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, s, storage.OperationCreate, target, id)
+
+	createdID, err := s.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+func (s *S3) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -584,6 +664,10 @@ func (s *S3) Create(ctx context.Context, id, target string, v any, prm *create.C
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -608,6 +692,8 @@ func (s *S3) Create(ctx context.Context, id, target string, v any, prm *create.C
 		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(s.database(trgt))
+
 	//////
 	// Create.
 	//////
@@ -657,9 +743,23 @@ func (s *S3) Create(ctx context.Context, id, target string, v any, prm *create.C
 		uploadInput.ContentType = aws.String("application/json")
 	}
 
+	// Insert-only, unless asked to overwrite: the object is only written if
+	// the key doesn't exist (`If-None-Match: *`). For multipart uploads, the
+	// condition is checked when completing it.
+	var uploadOptions []func(*s3manager.Uploader)
+
+	if !o.Overwrite {
+		uploadOptions = append(uploadOptions, s3manager.WithUploaderRequestOptions(ifNoneMatch))
+	}
+
 	// Perform the S3 upload request.
-	uO, err := s.uploader.UploadWithContext(ctx, uploadInput)
+	uO, err := s.uploader.UploadWithContext(ctx, uploadInput, uploadOptions...)
 	if err != nil {
+		var awsErr awserr.Error
+		if !o.Overwrite && errors.As(err, &awsErr) && awsErr.Code() == "PreconditionFailed" {
+			err = fmt.Errorf("%w: %w", storage.ErrAlreadyExists, err)
+		}
+
 		// If an error occurred, log it and return.
 		return "", customapm.TraceError(
 			ctx,
@@ -672,6 +772,8 @@ func (s *S3) Create(ctx context.Context, id, target string, v any, prm *create.C
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
 			return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
@@ -702,6 +804,13 @@ func (s *S3) Create(ctx context.Context, id, target string, v any, prm *create.C
 //
 // NOTE: Not truly an update, it's an insert.
 func (s *S3) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationUpdate, target, id)
+
+	return o.Done(v, s.update(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (s *S3) update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -739,6 +848,10 @@ func (s *S3) Update(ctx context.Context, id, target string, v any, prm *update.U
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -763,6 +876,8 @@ func (s *S3) Update(ctx context.Context, id, target string, v any, prm *update.U
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(s.database(trgt))
+
 	//////
 	// Create.
 	//////
@@ -826,6 +941,8 @@ func (s *S3) Update(ctx context.Context, id, target string, v any, prm *update.U
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, target, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
@@ -862,7 +979,7 @@ func (s *S3) GetClient() any {
 //////
 
 // New creates a new S3 storage.
-func New(ctx context.Context, bucket string, cfg *Config) (*S3, error) {
+func New(ctx context.Context, bucket string, cfg *Config, options ...storage.ConfigFunc) (*S3, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*S3)(nil)
 
@@ -870,7 +987,7 @@ func New(ctx context.Context, bucket string, cfg *Config) (*S3, error) {
 	// Storage.
 	//////
 
-	s, err := storage.New(ctx, Name)
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,72 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+//////
+// Exported functionalities.
+//////
+
+// IsTransientError reports whether `err` is worth retrying: throttling
+// (SlowDown), internal errors, request timeouts, 5xx responses, and whatever
+// `storage.IsTransientError` classifies.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case "SlowDown", "InternalError", "ServiceUnavailable", "RequestTimeout":
+			return true
+		}
+
+		if request.IsErrorThrottle(awsErr) {
+			return true
+		}
+
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() >= http.StatusInternalServerError {
+			return true
+		}
+	}
+
+	return storage.IsTransientError(err)
+}
+
+// ErrorKind maps `err` to a storage error kind: missing keys, and buckets to
+// `storage.ErrNotFound`, access denied to `storage.ErrPermissionDenied`,
+// failed preconditions to `storage.ErrConflict`, not implemented to
+// `storage.ErrUnsupported`, invalid requests to `storage.ErrInvalidArgument`,
+// and transient errors to `storage.ErrUnavailable`.
+func ErrorKind(err error) error {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case s3.ErrCodeNoSuchKey, s3.ErrCodeNoSuchBucket, "NotFound":
+			return storage.ErrNotFound
+		case "AccessDenied", "Forbidden":
+			return storage.ErrPermissionDenied
+		case "PreconditionFailed", "ConditionalRequestConflict":
+			return storage.ErrConflict
+		case "NotImplemented":
+			return storage.ErrUnsupported
+		case "InvalidArgument", "InvalidRequest", "KeyTooLongError", "EntityTooLarge":
+			return storage.ErrInvalidArgument
+		}
+	}
+
+	if IsTransientError(err) {
+		return storage.ErrUnavailable
+	}
+
+	return nil
+}
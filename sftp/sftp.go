@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path"
@@ -16,6 +17,7 @@ import (
 	"github.com/thalesfsp/dal/v2/internal/logging"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/delete"
@@ -73,6 +75,15 @@ type SFTP struct {
 
 // Count returns the number of items in the storage.
 func (s *SFTP) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, s, storage.OperationCount, target, "")
+
+	c, err := s.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (s *SFTP) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -137,6 +148,8 @@ func (s *SFTP) Count(ctx context.Context, target string, prm *count.Count, optio
 		return 0, customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Count.
 	//////
@@ -207,6 +220,13 @@ func (s *SFTP) Count(ctx context.Context, target string, prm *count.Count, optio
 
 // Delete removes data.
 func (s *SFTP) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationDelete, target, id)
+
+	return o.Done(nil, s.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (s *SFTP) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -244,6 +264,10 @@ func (s *SFTP) Delete(ctx context.Context, id, target string, prm *delete.Delete
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -266,6 +290,8 @@ func (s *SFTP) Delete(ctx context.Context, id, target string, prm *delete.Delete
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Delete.
 	//////
@@ -290,6 +316,8 @@ func (s *SFTP) Delete(ctx context.Context, id, target string, prm *delete.Delete
 		}
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, trgt, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
@@ -318,6 +346,13 @@ func (s *SFTP) Delete(ctx context.Context, id, target string, prm *delete.Delete
 
 // Retrieve data.
 func (s *SFTP) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, s.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (s *SFTP) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -377,6 +412,8 @@ func (s *SFTP) Retrieve(ctx context.Context, id, target string, v any, prm *retr
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Retrieve.
 	//////
@@ -414,6 +451,8 @@ func (s *SFTP) Retrieve(ctx context.Context, id, target string, v any, prm *retr
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
@@ -448,6 +487,13 @@ func (s *SFTP) Retrieve(ctx context.Context, id, target string, v any, prm *retr
 //
 // NOTE: It uses param.List.Search to query the data.
 func (s *SFTP) List(ctx context.Context, target string, v any, prm *list.List, opts ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationList, target, "")
+
+	return o.Done(v, s.list(ctx, target, v, prm, opts...), ErrorKind)
+}
+
+// list is List's implementation. See List.
+func (s *SFTP) list(ctx context.Context, target string, v any, prm *list.List, opts ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -501,6 +547,8 @@ func (s *SFTP) List(ctx context.Context, target string, v any, prm *list.List, o
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Query.
 	//////
@@ -533,6 +581,8 @@ func (s *SFTP) List(ctx context.Context, target string, v any, prm *list.List, o
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
 	}
 
+	span.SetRows(int64(len(keys.Keys)))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, "", trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
@@ -561,9 +611,21 @@ func (s *SFTP) List(ctx context.Context, target string, v any, prm *list.List, o
 
 // Create data.
 //
+// NOTE: It's insert-only (`O_EXCL`), failing with `storage.ErrAlreadyExists`
+// if the file exists. Use `storage.WithOverwrite` to replace it.
+//
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set. You are better off setting the ID yourself.
 func (s *SFTP) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, s, storage.OperationCreate, target, id)
+
+	createdID, err := s.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+func (s *SFTP) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -592,6 +654,10 @@ func (s *SFTP) Create(ctx context.Context, id, target string, v any, prm *create
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -614,6 +680,8 @@ func (s *SFTP) Create(ctx context.Context, id, target string, v any, prm *create
 		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Create.
 	//////
@@ -629,8 +697,22 @@ func (s *SFTP) Create(ctx context.Context, id, target string, v any, prm *create
 		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
 	}
 
-	dstFile, err := s.Client.Create(trgt)
+	// Insert-only (`O_EXCL`), unless asked to overwrite.
+	flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if o.Overwrite {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	dstFile, err := s.Client.OpenFile(trgt, flag)
 	if err != nil {
+		// Servers speaking SFTP v3 (e.g.: OpenSSH) report an existing file as
+		// a generic failure.
+		if !o.Overwrite {
+			if _, statErr := s.Client.Stat(trgt); statErr == nil {
+				err = fmt.Errorf("%w: %w", storage.ErrAlreadyExists, err)
+			}
+		}
+
 		if !os.IsNotExist(err) {
 			return "", customapm.TraceError(
 				ctx,
@@ -661,6 +743,8 @@ func (s *SFTP) Create(ctx context.Context, id, target string, v any, prm *create
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, trgt, v, finalParam); err != nil {
 			return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
@@ -689,6 +773,13 @@ func (s *SFTP) Create(ctx context.Context, id, target string, v any, prm *create
 
 // Update data.
 func (s *SFTP) Update(ctx context.Context, id, target string, v any, prm *update.Update, opts ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, s, storage.OperationUpdate, target, id)
+
+	return o.Done(v, s.update(ctx, id, target, v, prm, opts...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (s *SFTP) update(ctx context.Context, id, target string, v any, prm *update.Update, opts ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -726,6 +817,10 @@ func (s *SFTP) Update(ctx context.Context, id, target string, v any, prm *update
 		}
 	}
 
+	if err := storage.NoOutbox(o); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
+	}
+
 	//////
 	// Params initialization.
 	//////
@@ -748,6 +843,8 @@ func (s *SFTP) Update(ctx context.Context, id, target string, v any, prm *update
 		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(tracing.Database{Collection: trgt})
+
 	//////
 	// Update.
 	//////
@@ -795,6 +892,8 @@ func (s *SFTP) Update(ctx context.Context, id, target string, v any, prm *update
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, s, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
@@ -826,6 +925,54 @@ func (s *SFTP) GetClient() any {
 	return s.Client
 }
 
+// Watch streams the changes to the files of the `target` remote directory,
+// detected by polling their modification time, and size every
+// `WithPollInterval`. Event IDs are file names, as listed by `List`.
+//
+// NOTE: Resume tokens aren't supported. SFTP modification times have a
+// 1-second resolution.
+func (s *SFTP) Watch(ctx context.Context, target string, options ...storage.WatchFunc) <-chan storage.ChangeEvent {
+	o, err := storage.NewWatchOptions(options...)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	trgt, err := shared.TargetName(target, s.Target)
+	if err != nil {
+		return storage.FailedWatch(target, err)
+	}
+
+	snapshot := func(_ context.Context) (map[string]storage.PollEntry, error) {
+		entries, err := s.Client.ReadDir(trgt)
+		if err != nil {
+			return nil, customerror.NewFailedToError("watch "+trgt, customerror.WithError(err))
+		}
+
+		files := make(map[string]storage.PollEntry, len(entries))
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files[entry.Name()] = storage.PollEntry{ModTime: entry.ModTime(), Size: entry.Size()}
+			}
+		}
+
+		return files, nil
+	}
+
+	read := func(_ context.Context, id string) ([]byte, error) {
+		f, err := s.Client.Open(path.Join(trgt, id))
+		if err != nil {
+			return nil, err
+		}
+
+		defer f.Close()
+
+		return io.ReadAll(f)
+	}
+
+	return storage.PollWatch(ctx, target, o, snapshot, read)
+}
+
 //////
 // Factory.
 //////
@@ -834,10 +981,24 @@ func (s *SFTP) GetClient() any {
 //
 // NOTE: addr format is: host:port.
 func New(ctx context.Context, addr string, cfg *Config, options ...Option) (*SFTP, error) {
+	return NewWithStorageOptions(ctx, addr, cfg, nil, options...)
+}
+
+// NewWithStorageOptions is like New, but also takes the storage options,
+// e.g.: its logger (`storage.WithLogger`).
+func NewWithStorageOptions(
+	ctx context.Context,
+	addr string,
+	cfg *Config,
+	storageOptions []storage.ConfigFunc,
+	options ...Option,
+) (*SFTP, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*SFTP)(nil)
 
-	s, err := storage.New(ctx, Name)
+	var _ storage.IWatcher = (*SFTP)(nil)
+
+	s, err := storage.New(ctx, Name, storageOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,55 @@
+package sftp
+
+import (
+	"errors"
+
+	"github.com/pkg/sftp"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+//////
+// Exported functionalities.
+//////
+
+// IsTransientError reports whether `err` is worth retrying: lost, or missing
+// SSH connections, and whatever `storage.IsTransientError` classifies.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, sftp.ErrSSHFxConnectionLost) || errors.Is(err, sftp.ErrSSHFxNoConnection) {
+		return true
+	}
+
+	return storage.IsTransientError(err)
+}
+
+// SSH_FX_FILE_ALREADY_EXISTS isn't exported by the sftp package.
+const sshFxFileAlreadyExists = 11
+
+// ErrorKind maps `err` to a storage error kind: no such file to
+// `storage.ErrNotFound`, file already exists to `storage.ErrAlreadyExists`,
+// permission denied to `storage.ErrPermissionDenied`, unsupported operations to
+// `storage.ErrUnsupported`, and transient errors to `storage.ErrUnavailable`.
+func ErrorKind(err error) error {
+	var statusErr *sftp.StatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.FxCode() == sftp.ErrSSHFxNoSuchFile:
+			return storage.ErrNotFound
+		case statusErr.FxCode() == sftp.ErrSSHFxPermissionDenied:
+			return storage.ErrPermissionDenied
+		case statusErr.FxCode() == sftp.ErrSSHFxOpUnsupported:
+			return storage.ErrUnsupported
+		case statusErr.Code == sshFxFileAlreadyExists:
+			return storage.ErrAlreadyExists
+		}
+	}
+
+	if IsTransientError(err) {
+		return storage.ErrUnavailable
+	}
+
+	return nil
+}
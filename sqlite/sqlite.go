@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/doug-martin/goqu/v9"
@@ -18,8 +19,10 @@ import (
 	"github.com/thalesfsp/customerror"
 	"github.com/thalesfsp/dal/v2/internal/customapm"
 	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/internal/outbox"
 	"github.com/thalesfsp/dal/v2/internal/shared"
 	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/customsort"
@@ -54,6 +57,25 @@ var (
 // it.
 var driverName = Name
 
+// outboxDialect describes the outbox table. See `WithOutbox`.
+var outboxDialect = outbox.Dialect{
+	Name: Name,
+	Columns: `
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		operation VARCHAR(16) NOT NULL,
+		target VARCHAR(255) NOT NULL,
+		document_id VARCHAR(255) NOT NULL,
+		payload TEXT NOT NULL,
+		created_at BIGINT NOT NULL,
+		available_at BIGINT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error VARCHAR(1024) NOT NULL DEFAULT '',
+		processed_at BIGINT,
+		failed_at BIGINT
+	`,
+	SkipLocked: false,
+}
+
 // Config is the postgres configuration.
 type Config struct {
 	DataSourceName string `json:"dataSourceName" validate:"required"`
@@ -98,6 +120,20 @@ func ToSQLString(s customsort.Sort) (string, error) {
 	return sqlFormatted, nil
 }
 
+// database returns the database context of the spans of `trgt`: the database
+// is the file of the data source name.
+func (p *SQLite) database(trgt string) tracing.Database {
+	db := tracing.Database{Collection: trgt}
+
+	if p.Config != nil {
+		file, _, _ := strings.Cut(p.Config.DataSourceName, "?")
+
+		db.Name = strings.TrimPrefix(file, "file:")
+	}
+
+	return db
+}
+
 func (p *SQLite) createDB(ctx context.Context, name string) error {
 	if _, err := p.
 		Client.
@@ -147,6 +183,15 @@ func (p *SQLite) createTable(ctx context.Context, name, description string) erro
 
 // Count returns the number of items in the storage.
 func (p *SQLite) Count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
+	ctx, o := storage.Observe(ctx, p, storage.OperationCount, target, "")
+
+	c, err := p.count(ctx, target, prm, options...)
+
+	return c, o.Done(nil, err, ErrorKind)
+}
+
+// count is Count's implementation. See Count.
+func (p *SQLite) count(ctx context.Context, target string, prm *count.Count, options ...storage.Func[*count.Count]) (int64, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -184,6 +229,8 @@ func (p *SQLite) Count(ctx context.Context, target string, prm *count.Count, opt
 		return 0, customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterCountedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Params initialization.
 	//////
@@ -220,6 +267,8 @@ func (p *SQLite) Count(ctx context.Context, target string, prm *count.Count, opt
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: finalParam.Search})
+
 	var count int64
 	if err = p.Client.QueryRowContext(ctx, finalParam.Search).Scan(&count); err != nil {
 		return 0, customapm.TraceError(
@@ -258,6 +307,13 @@ func (p *SQLite) Count(ctx context.Context, target string, prm *count.Count, opt
 
 // Delete removes data.
 func (p *SQLite) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
+	ctx, o := storage.Observe(ctx, p, storage.OperationDelete, target, id)
+
+	return o.Done(nil, p.delete(ctx, id, target, prm, options...), ErrorKind)
+}
+
+// delete is Delete's implementation. See Delete.
+func (p *SQLite) delete(ctx context.Context, id, target string, prm *delete.Delete, options ...storage.Func[*delete.Delete]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -317,6 +373,8 @@ func (p *SQLite) Delete(ctx context.Context, id, target string, prm *delete.Dele
 		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterDeletedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Delete.
 	//////
@@ -340,7 +398,17 @@ func (p *SQLite) Delete(ctx context.Context, id, target string, prm *delete.Dele
 		)
 	}
 
-	if _, err := p.Client.ExecContext(ctx, selectSQL, args...); err != nil {
+	span.SetDatabase(tracing.Database{Statement: selectSQL})
+
+	db, tx, err := outbox.Begin(ctx, p.Client, o.OutboxTable)
+	if err != nil {
+		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterDeletedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
+	res, err := db.ExecContext(ctx, selectSQL, args...)
+	if err != nil {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(storage.OperationDelete.String(), customerror.WithError(err)),
@@ -349,6 +417,19 @@ func (p *SQLite) Delete(ctx context.Context, id, target string, prm *delete.Dele
 		)
 	}
 
+	rowsAffected, err := res.RowsAffected()
+	if err == nil {
+		span.SetRows(rowsAffected)
+	}
+
+	// Deleting nothing isn't a mutation: no event, and the transaction - if
+	// any - is rolled back.
+	if err != nil || rowsAffected > 0 {
+		if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationDelete, trgt, id, nil); err != nil {
+			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterDeletedFailed())
+		}
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, id, trgt, nil, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterDeletedFailed())
@@ -377,6 +458,13 @@ func (p *SQLite) Delete(ctx context.Context, id, target string, prm *delete.Dele
 
 // Retrieve data.
 func (p *SQLite) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
+	ctx, o := storage.Observe(ctx, p, storage.OperationRetrieve, target, id)
+
+	return o.Done(v, p.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// retrieve is Retrieve's implementation. See Retrieve.
+func (p *SQLite) retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...storage.Func[*retrieve.Retrieve]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -436,6 +524,8 @@ func (p *SQLite) Retrieve(ctx context.Context, id, target string, v any, prm *re
 		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterRetrievedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Retrieve.
 	//////
@@ -460,6 +550,8 @@ func (p *SQLite) Retrieve(ctx context.Context, id, target string, v any, prm *re
 		)
 	}
 
+	span.SetDatabase(tracing.Database{Statement: selectSQL})
+
 	// Execute the query.
 	if err := p.Client.GetContext(ctx, v, selectSQL, args...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -479,6 +571,8 @@ func (p *SQLite) Retrieve(ctx context.Context, id, target string, v any, prm *re
 		)
 	}
 
+	span.SetRows(1)
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterRetrievedFailed())
@@ -509,6 +603,13 @@ func (p *SQLite) Retrieve(ctx context.Context, id, target string, v any, prm *re
 //
 // NOTE: It uses param.List.Search to query the data.
 func (p *SQLite) List(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
+	ctx, o := storage.Observe(ctx, p, storage.OperationList, target, "")
+
+	return o.Done(v, p.list(ctx, target, v, prm, options...), ErrorKind)
+}
+
+// list is List's implementation. See List.
+func (p *SQLite) list(ctx context.Context, target string, v any, prm *list.List, options ...storage.Func[*list.List]) error {
 	//////
 	// APM Tracing.
 	//////
@@ -546,6 +647,8 @@ func (p *SQLite) List(ctx context.Context, target string, v any, prm *list.List,
 		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterListedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Params initialization.
 	//////
@@ -582,6 +685,8 @@ func (p *SQLite) List(ctx context.Context, target string, v any, prm *list.List,
 		}
 	}
 
+	span.SetDatabase(tracing.Database{Statement: finalParam.Search})
+
 	if err := p.Client.SelectContext(ctx, v, finalParam.Search); err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -591,6 +696,8 @@ func (p *SQLite) List(ctx context.Context, target string, v any, prm *list.List,
 		)
 	}
 
+	span.SetRows(shared.Rows(v))
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, "", trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterListedFailed())
@@ -619,9 +726,21 @@ func (p *SQLite) List(ctx context.Context, target string, v any, prm *list.List,
 
 // Create data.
 //
+// NOTE: It's insert-only, failing with `storage.ErrAlreadyExists` on a
+// duplicated key. Use `storage.WithOverwrite` to upsert instead.
+//
 // NOTE: Not all storages returns the ID, neither all storages requires `id` to
 // be set. You are better off setting the ID yourself.
 func (p *SQLite) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
+	ctx, o := storage.Observe(ctx, p, storage.OperationCreate, target, id)
+
+	createdID, err := p.create(ctx, id, target, v, prm, options...)
+
+	return createdID, o.Done(v, err, ErrorKind)
+}
+
+// create is Create's implementation. See Create.
+func (p *SQLite) create(ctx context.Context, id, target string, v any, prm *create.Create, options ...storage.Func[*create.Create]) (string, error) {
 	//////
 	// APM Tracing.
 	//////
@@ -672,6 +791,8 @@ func (p *SQLite) Create(ctx context.Context, id, target string, v any, prm *crea
 		return "", customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterCreatedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Create.
 	//////
@@ -688,6 +809,11 @@ func (p *SQLite) Create(ctx context.Context, id, target string, v any, prm *crea
 		Rows(v).
 		Returning("id")
 
+	// Insert-only, unless asked to overwrite.
+	if o.Overwrite {
+		ds = ds.OnConflict(goqu.DoUpdate("id", v))
+	}
+
 	// Convert the query to SQL, and arguments.
 	insertSQL, args, err := ds.ToSQL()
 	if err != nil {
@@ -699,9 +825,18 @@ func (p *SQLite) Create(ctx context.Context, id, target string, v any, prm *crea
 		)
 	}
 
+	span.SetDatabase(tracing.Database{Statement: insertSQL})
+
 	// Execute the query.
 	var returnedID string
-	if err := p.Client.QueryRowContext(ctx, insertSQL, args...).Scan(&returnedID); err != nil {
+	db, tx, err := outbox.Begin(ctx, p.Client, o.OutboxTable)
+	if err != nil {
+		return "", customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterCreatedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
+	if err := db.QueryRowxContext(ctx, insertSQL, args...).Scan(&returnedID); err != nil {
 		return "", customapm.TraceError(
 			ctx,
 			customerror.NewFailedToError(storage.OperationCreate.String(), customerror.WithError(err)),
@@ -710,6 +845,12 @@ func (p *SQLite) Create(ctx context.Context, id, target string, v any, prm *crea
 		)
 	}
 
+	span.SetRows(1)
+
+	if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationCreate, trgt, returnedID, v); err != nil {
+		return "", customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterCreatedFailed())
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, id, trgt, v, finalParam); err != nil {
 			return "", err
@@ -738,6 +879,13 @@ func (p *SQLite) Create(ctx context.Context, id, target string, v any, prm *crea
 
 // Update data.
 func (p *SQLite) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
+	ctx, o := storage.Observe(ctx, p, storage.OperationUpdate, target, id)
+
+	return o.Done(v, p.update(ctx, id, target, v, prm, options...), ErrorKind)
+}
+
+// update is Update's implementation. See Update.
+func (p *SQLite) update(ctx context.Context, id, target string, v any, prm *update.Update, options ...storage.Func[*update.Update]) error {
 	if id == "" {
 		return customapm.TraceError(
 			ctx,
@@ -797,6 +945,8 @@ func (p *SQLite) Update(ctx context.Context, id, target string, v any, prm *upda
 		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterUpdatedFailed())
 	}
 
+	span.SetDatabase(p.database(trgt))
+
 	//////
 	// Update.
 	//////
@@ -821,7 +971,16 @@ func (p *SQLite) Update(ctx context.Context, id, target string, v any, prm *upda
 		)
 	}
 
-	res, err := p.Client.ExecContext(ctx, updateSQL, args...)
+	span.SetDatabase(tracing.Database{Statement: updateSQL})
+
+	db, tx, err := outbox.Begin(ctx, p.Client, o.OutboxTable)
+	if err != nil {
+		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterUpdatedFailed())
+	}
+
+	defer outbox.Rollback(tx)
+
+	res, err := db.ExecContext(ctx, updateSQL, args...)
 	if err != nil {
 		return customapm.TraceError(
 			ctx,
@@ -831,8 +990,13 @@ func (p *SQLite) Update(ctx context.Context, id, target string, v any, prm *upda
 		)
 	}
 
+	rowsAffected, err := res.RowsAffected()
+	if err == nil {
+		span.SetRows(rowsAffected)
+	}
+
 	// Surface updates that matched nothing as 404, consistent with Retrieve.
-	if rowsAffected, err := res.RowsAffected(); err == nil && rowsAffected == 0 {
+	if err == nil && rowsAffected == 0 {
 		return customapm.TraceError(
 			ctx,
 			customerror.NewHTTPError(http.StatusNotFound),
@@ -841,6 +1005,10 @@ func (p *SQLite) Update(ctx context.Context, id, target string, v any, prm *upda
 		)
 	}
 
+	if err := outbox.Commit(ctx, tx, outboxDialect, o.OutboxTable, storage.OperationUpdate, trgt, id, v); err != nil {
+		return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterUpdatedFailed())
+	}
+
 	if o.PostHookFunc != nil {
 		if err := o.PostHookFunc(ctx, p, id, trgt, v, finalParam); err != nil {
 			return customapm.TraceError(ctx, err, p.GetLogger(), p.GetCounterUpdatedFailed())
@@ -872,16 +1040,33 @@ func (p *SQLite) GetClient() any {
 	return p.Client
 }
 
+// CreateOutbox creates the outbox `table`, if it doesn't exist. See
+// `storage.WithOutbox`.
+func (p *SQLite) CreateOutbox(ctx context.Context, table string) error {
+	return outbox.CreateTable(ctx, p.Client, outboxDialect, table)
+}
+
+// RelayOutbox claims, and delivers pending events of the outbox `table`. See
+// `storage.Relay`.
+//
+// NOTE: SQLite has no row locks: the claiming transaction holds the database
+// write lock while delivering, so run a single relay, and keep publishers fast.
+func (p *SQLite) RelayOutbox(ctx context.Context, table string, limit int, deliver storage.OutboxDeliverFunc) (int, error) {
+	return outbox.Relay(ctx, p.Client, outboxDialect, table, limit, deliver)
+}
+
 //////
 // Factory.
 //////
 
 // New creates a new postgres storage.
-func New(ctx context.Context, dataSource string) (*SQLite, error) {
+func New(ctx context.Context, dataSource string, options ...storage.ConfigFunc) (*SQLite, error) {
 	// Enforces IStorage interface implementation.
 	var _ storage.IStorage = (*SQLite)(nil)
 
-	s, err := storage.New(ctx, Name)
+	var _ storage.IOutbox = (*SQLite)(nil)
+
+	s, err := storage.New(ctx, Name, options...)
 	if err != nil {
 		return nil, err
 	}
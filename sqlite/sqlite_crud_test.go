@@ -7,12 +7,17 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/thalesfsp/customerror"
 	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/dal/v2/tracing"
 	"github.com/thalesfsp/params/v2/count"
 	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/params/v2/delete"
 	"github.com/thalesfsp/params/v2/list"
 	"github.com/thalesfsp/params/v2/retrieve"
 	"github.com/thalesfsp/params/v2/update"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 //////
@@ -35,6 +40,10 @@ func TestSQLite_CRUDRoundTrip(t *testing.T) {
 		assert.NoError(t, str.Delete(ctx, doc.ID, shared.TableName, &delete.Delete{}))
 	}()
 
+	// Duplicated primary key.
+	_, err = str.Create(ctx, doc.ID, shared.TableName, doc, &create.Create{})
+	assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+
 	var got shared.TestDataWithIDS
 	require.NoError(t, str.Retrieve(ctx, doc.ID, shared.TableName, &got, &retrieve.Retrieve{}))
 	assert.Equal(t, *doc, got)
@@ -45,6 +54,17 @@ func TestSQLite_CRUDRoundTrip(t *testing.T) {
 	var afterUpdate shared.TestDataWithIDS
 	require.NoError(t, str.Retrieve(ctx, doc.ID, shared.TableName, &afterUpdate, &retrieve.Retrieve{}))
 	assert.Equal(t, "gamma-2", afterUpdate.Name)
+
+	// Explicit overwrite upserts.
+	replaced := &shared.TestDataWithIDS{ID: doc.ID, Name: "gamma-3", Version: "1.2.5"}
+
+	id, err = str.Create(ctx, doc.ID, shared.TableName, replaced, &create.Create{}, storage.WithOverwrite())
+	require.NoError(t, err)
+	assert.Equal(t, doc.ID, id)
+
+	var afterOverwrite shared.TestDataWithIDS
+	require.NoError(t, str.Retrieve(ctx, doc.ID, shared.TableName, &afterOverwrite, &retrieve.Retrieve{}))
+	assert.Equal(t, *replaced, afterOverwrite)
 }
 
 // Regression: updating a nonexistent row must be a 404, not silent success.
@@ -77,6 +97,14 @@ func TestSQLite_RetrieveMissingRowIs404(t *testing.T) {
 	cE, ok := customerror.To(err)
 	require.True(t, ok)
 	assert.Equal(t, 404, cE.StatusCode)
+
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	var sErr *storage.Error
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, storage.OperationRetrieve, sErr.Operation)
+	assert.Equal(t, "does-not-exist", sErr.ID)
+	assert.Equal(t, shared.TableName, sErr.Target)
 }
 
 // SQL-injection guard: a malicious target must be rejected on the default
@@ -108,3 +136,27 @@ func TestSQLite_InjectionGuardOnTarget(t *testing.T) {
 	require.NoError(t, str.List(ctx, shared.TableName, &rows, nil))
 	assert.NotEmpty(t, rows)
 }
+
+// Spans carry the database context: instance, table, statement, and rows.
+func TestSQLite_SpanDatabase(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	tracing.Set(tracing.NewOTel(tracing.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))))
+	t.Cleanup(func() { tracing.Set(nil) })
+
+	ctx := t.Context()
+	str := getTestStorage(ctx, t)
+
+	var got shared.TestDataWithIDS
+	require.NoError(t, str.Retrieve(ctx, "id-1", shared.TableName, &got, &retrieve.Retrieve{}))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	attrs := spans[0].Attributes()
+	assert.Contains(t, attrs, attribute.String("db.system", "sqlite"))
+	assert.Contains(t, attrs, attribute.String("db.name", str.Config.DataSourceName))
+	assert.Contains(t, attrs, attribute.String("db.sql.table", shared.TableName))
+	assert.Contains(t, attrs, attribute.String("db.statement", `SELECT * FROM "`+shared.TableName+`" WHERE ("id" = 'id-1')`))
+	assert.Contains(t, attrs, attribute.Int64(tracing.RowsAttribute, 1))
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/dal/v2/storage"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/update"
+)
+
+// Mutations with an outbox write their event in the same transaction, and the
+// relay delivers them once.
+func TestSQLite_Outbox(t *testing.T) {
+	ctx := t.Context()
+
+	// Own database: the shared one lives in another test's temp dir.
+	str, err := New(ctx, filepath.Join(t.TempDir(), "dal-outbox-test.db"))
+	require.NoError(t, err)
+
+	defer str.Client.Close()
+
+	str.Client.SetMaxOpenConns(1)
+
+	require.NoError(t, str.createTable(ctx, shared.TableName, `
+		id varchar(255) PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		version varchar(255) NOT NULL
+	`))
+
+	const table = "outbox_test"
+
+	require.NoError(t, str.CreateOutbox(ctx, table))
+	require.NoError(t, str.CreateOutbox(ctx, table))
+
+	doc := &shared.TestDataWithIDS{ID: "outbox-1", Name: "delta", Version: "1.0.0"}
+
+	_, err = str.Create(ctx, doc.ID, shared.TableName, doc, &create.Create{}, storage.WithOutbox[*create.Create](table))
+	require.NoError(t, err)
+
+	// Failed mutations write no event.
+	_, err = str.Create(ctx, doc.ID, shared.TableName, doc, &create.Create{}, storage.WithOutbox[*create.Create](table))
+	require.ErrorIs(t, err, storage.ErrAlreadyExists)
+
+	err = str.Update(ctx, "outbox-missing", shared.TableName, doc, &update.Update{}, storage.WithOutbox[*update.Update](table))
+	require.Error(t, err)
+
+	// Deleting nothing writes no event.
+	require.NoError(t, str.Delete(ctx, "outbox-missing", shared.TableName, &delete.Delete{}, storage.WithOutbox[*delete.Delete](table)))
+
+	doc.Version = "1.0.1"
+
+	require.NoError(t, str.Update(ctx, doc.ID, shared.TableName, doc, &update.Update{}, storage.WithOutbox[*update.Update](table)))
+	require.NoError(t, str.Delete(ctx, doc.ID, shared.TableName, &delete.Delete{}, storage.WithOutbox[*delete.Delete](table)))
+
+	fail := true
+	events := []*storage.OutboxEvent{}
+
+	r, err := storage.NewRelay(str, storage.PublisherFunc(func(_ context.Context, event *storage.OutboxEvent) error {
+		// The first delivery of the update fails, and is retried.
+		if event.Operation == storage.OperationUpdate && fail {
+			fail = false
+
+			return errors.New("broker unavailable")
+		}
+
+		events = append(events, event)
+
+		return nil
+	}), storage.WithRelayTable(table), storage.WithRelayRetry(3, 0, 0))
+	require.NoError(t, err)
+
+	n, err := r.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	n, err = r.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = r.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	require.Len(t, events, 3)
+
+	assert.Equal(t, storage.OperationCreate, events[0].Operation)
+	assert.Equal(t, shared.TableName, events[0].Target)
+	assert.Equal(t, doc.ID, events[0].DocumentID)
+	assert.JSONEq(t, `{"id":"outbox-1","name":"delta","version":"1.0.0"}`, string(events[0].Payload))
+
+	assert.Equal(t, storage.OperationDelete, events[1].Operation)
+	assert.Empty(t, events[1].Payload)
+
+	assert.Equal(t, storage.OperationUpdate, events[2].Operation)
+	assert.Equal(t, 1, events[2].Attempts)
+	assert.Equal(t, "broker unavailable", events[2].LastError)
+
+	// Invalid table.
+	require.Error(t, str.CreateOutbox(ctx, "outbox; DROP TABLE x"))
+}
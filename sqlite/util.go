@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/thalesfsp/dal/v2/storage"
+)
+
+//////
+// Exported functionalities.
+//////
+
+// IsTransientError reports whether `err` is worth retrying: the database, or
+// a table is locked (SQLITE_BUSY, SQLITE_LOCKED), and whatever
+// `storage.IsTransientError` classifies.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+
+	return storage.IsTransientError(err)
+}
+
+// ErrorKind maps `err` to a storage error kind: unique, and primary key
+// violations to `storage.ErrAlreadyExists`, other constraint violations, type
+// mismatches, and too big values to `storage.ErrInvalidArgument`, read-only
+// databases, and authorization failures to `storage.ErrPermissionDenied`, busy
+// or locked databases to `storage.ErrUnavailable`, and no rows to
+// `storage.ErrNotFound`.
+func ErrorKind(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return nil
+	}
+
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		return storage.ErrAlreadyExists
+	}
+
+	switch sqliteErr.Code {
+	case sqlite3.ErrConstraint, sqlite3.ErrMismatch, sqlite3.ErrTooBig:
+		return storage.ErrInvalidArgument
+	case sqlite3.ErrPerm, sqlite3.ErrReadonly, sqlite3.ErrAuth:
+		return storage.ErrPermissionDenied
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		return storage.ErrUnavailable
+	}
+
+	return nil
+}
@@ -0,0 +1,593 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/customapm"
+	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/count"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/list"
+	"github.com/thalesfsp/params/v2/retrieve"
+	"github.com/thalesfsp/params/v2/update"
+	"github.com/thalesfsp/status"
+	"github.com/thalesfsp/sypl/v2"
+	"github.com/thalesfsp/sypl/v2/fields"
+	"github.com/thalesfsp/sypl/v2/level"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// AuditName is the name of the audit storage.
+	AuditName = "audit"
+
+	// DefaultAuditTarget is the default target (table, index, collection,
+	// directory) audit entries are written to.
+	DefaultAuditTarget = "audit"
+
+	// DefaultAuditHistoryPageSize is the default number of entries listed per
+	// page by AuditHistory.
+	DefaultAuditHistoryPageSize = 100
+)
+
+// actorKey is the context key of the actor.
+type actorKey struct{}
+
+// ActorFunc returns the actor - user, service - performing the operation in
+// `ctx`.
+type ActorFunc func(ctx context.Context) string
+
+// AuditFunc allows to set audit options.
+type AuditFunc func(a *Audit) error
+
+// AuditEntry is the record of a mutation.
+type AuditEntry struct {
+	// ID of the entry.
+	ID string `json:"id" db:"id" bson:"_id"`
+
+	// Actor who performed the mutation, if known.
+	Actor string `json:"actor,omitempty" db:"actor" bson:"actor,omitempty"`
+
+	// Timestamp of the mutation, in UTC.
+	Timestamp time.Time `json:"timestamp" db:"timestamp" bson:"timestamp"`
+
+	// Storage mutated, e.g.: `postgres`.
+	Storage string `json:"storage" db:"storage" bson:"storage"`
+
+	// Operation is one of `create`, `update`, or `delete`.
+	Operation Operation `json:"operation" db:"operation" bson:"operation"`
+
+	// Target of the mutation.
+	Target string `json:"target" db:"target" bson:"target"`
+
+	// DocumentID is the ID of the mutated document.
+	DocumentID string `json:"documentId" db:"document_id" bson:"documentId"`
+
+	// Before is the document before the mutation, normalized to JSON. Only set
+	// if captured, and the document existed.
+	Before json.RawMessage `json:"before,omitempty" db:"before" bson:"before,omitempty"`
+
+	// After is the document after the mutation, normalized to JSON. Empty for
+	// deletions.
+	After json.RawMessage `json:"after,omitempty" db:"after" bson:"after,omitempty"`
+
+	// TraceID correlates the mutation with its trace, if any.
+	TraceID string `json:"traceId,omitempty" db:"trace_id" bson:"traceId,omitempty"`
+}
+
+// AuditEntries is a list of audit entries. It decodes from a JSON array, or
+// from an `{"items": [...]}` object (e.g.: the memory storage), so it can be
+// used as the `List` destination of any storage.
+type AuditEntries []AuditEntry
+
+// Audit is a storage which wraps another one (`Backend`), and records every
+// mutation (create, update, delete) to the `Trail` storage: who (`ActorFunc`),
+// when, what (storage, target, id), the before and after documents, and the
+// trace ID. Reads pass through.
+//
+// Entries are created with a unique ID, and `Create` is insert-only, so the
+// trail is append-only. Use `AuditHistory` to rebuild the history of a
+// document.
+//
+// NOTE: An entry is only written if the mutation succeeds. Failing to write
+// it is logged, and counted but, unless `WithStrictAudit`, not surfaced.
+//
+// NOTE: Capturing the before (`WithCaptureBefore`), and the after
+// (`WithCaptureAfter`) states costs one extra retrieve each.
+type Audit struct {
+	*Storage
+
+	// Backend is the audited storage.
+	Backend IStorage `json:"-" validate:"required"`
+
+	// Trail is the storage entries are written to, e.g.: an append-only file,
+	// elasticsearch, or postgres.
+	Trail IStorage `json:"-" validate:"required"`
+
+	// Target is where, in the trail, entries are written to.
+	Target string `json:"target" validate:"required"`
+
+	// ActorFunc returns the actor. Default is `ActorFromContext`.
+	ActorFunc ActorFunc `json:"-" validate:"required"`
+
+	// NewFunc returns a new destination to retrieve documents into, when the
+	// operation carries none (delete). Default is a generic map.
+	NewFunc func() any `json:"-" validate:"required"`
+
+	// CaptureBefore retrieves the document before mutating it.
+	CaptureBefore bool `json:"captureBefore"`
+
+	// CaptureAfter retrieves the document after mutating it, recording the
+	// stored state instead of the one written (e.g.: partial updates).
+	CaptureAfter bool `json:"captureAfter"`
+
+	// Strict fails the operation if its entry can't be written.
+	Strict bool `json:"strict"`
+
+	// Metrics.
+	counterAudited     *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterAuditFailed *expvar.Int `json:"-" validate:"required,gte=0"`
+}
+
+//////
+// Methods.
+//////
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (e *AuditEntries) UnmarshalJSON(data []byte) error {
+	var entries []AuditEntry
+
+	if err := json.Unmarshal(data, &entries); err == nil {
+		*e = entries
+
+		return nil
+	}
+
+	var wrapped struct {
+		Items []AuditEntry `json:"items"`
+	}
+
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return err
+	}
+
+	*e = wrapped.Items
+
+	return nil
+}
+
+// At returns the document as it was at `t`, i.e.: the after state of the last
+// entry up to `t`. False if it didn't exist then. Entries must be sorted, as
+// returned by `AuditHistory`.
+func (e AuditEntries) At(t time.Time) (json.RawMessage, bool) {
+	var state json.RawMessage
+
+	for _, entry := range e {
+		if entry.Timestamp.After(t) {
+			break
+		}
+
+		state = entry.After
+	}
+
+	return state, len(state) > 0
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithAuditTarget sets where, in the trail, entries are written to. Default
+// is `DefaultAuditTarget`.
+func WithAuditTarget(target string) AuditFunc {
+	return func(a *Audit) error {
+		if target == "" {
+			return customerror.NewRequiredError("audit target")
+		}
+
+		a.Target = target
+
+		return nil
+	}
+}
+
+// WithActorFunc sets how the actor is extracted from the context. Default is
+// `ActorFromContext`.
+func WithActorFunc(fn ActorFunc) AuditFunc {
+	return func(a *Audit) error {
+		if fn == nil {
+			return customerror.NewRequiredError("actor func")
+		}
+
+		a.ActorFunc = fn
+
+		return nil
+	}
+}
+
+// WithCaptureBefore retrieves documents before mutating them. `newFunc`, if
+// not nil, returns the destination deleted documents are retrieved into, e.g.:
+// `func() any { return &User{} }`, required by storages which can't decode
+// into a generic map (SQL).
+func WithCaptureBefore(newFunc func() any) AuditFunc {
+	return func(a *Audit) error {
+		a.CaptureBefore = true
+
+		if newFunc != nil {
+			a.NewFunc = newFunc
+		}
+
+		return nil
+	}
+}
+
+// WithCaptureAfter retrieves documents after mutating them.
+func WithCaptureAfter() AuditFunc {
+	return func(a *Audit) error {
+		a.CaptureAfter = true
+
+		return nil
+	}
+}
+
+// WithStrictAudit fails mutations whose entry can't be written.
+//
+// NOTE: The mutation was applied, only its entry is missing.
+func WithStrictAudit() AuditFunc {
+	return func(a *Audit) error {
+		a.Strict = true
+
+		return nil
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// retrieveState retrieves the document `id` into `v`, normalized to JSON. Nil
+// if it can't be retrieved, e.g.: it doesn't exist.
+func (a *Audit) retrieveState(ctx context.Context, id, target string, v any) json.RawMessage {
+	if err := a.Backend.Retrieve(ctx, id, target, v, nil); err != nil {
+		return nil
+	}
+
+	b, err := normalizeJSON(v)
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+// before returns the before state, if captured.
+func (a *Audit) before(ctx context.Context, id, target string, v any) json.RawMessage {
+	if !a.CaptureBefore || id == "" {
+		return nil
+	}
+
+	if v == nil {
+		return a.retrieveState(ctx, id, target, a.NewFunc())
+	}
+
+	return a.retrieveState(ctx, id, target, newLike(v))
+}
+
+// after returns the after state: the stored one if captured, otherwise `v`.
+func (a *Audit) after(ctx context.Context, id, target string, v any) json.RawMessage {
+	if a.CaptureAfter {
+		return a.retrieveState(ctx, id, target, newLike(v))
+	}
+
+	b, err := normalizeJSON(v)
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+// record writes the entry of a mutation to the trail.
+func (a *Audit) record(ctx context.Context, op Operation, id, target string, before, after json.RawMessage) error {
+	entry := &AuditEntry{
+		ID:         shared.GenerateUUID(),
+		Actor:      a.ActorFunc(ctx),
+		Timestamp:  time.Now().UTC(),
+		Storage:    a.Backend.GetName(),
+		Operation:  op,
+		Target:     target,
+		DocumentID: id,
+		Before:     before,
+		After:      after,
+	}
+
+	if traceID, ok := logging.ToAPM(ctx, fields.Fields{})["trace.id"].(string); ok {
+		entry.TraceID = traceID
+	}
+
+	if _, err := a.Trail.Create(ctx, entry.ID, a.Target, entry, nil); err != nil {
+		a.counterAuditFailed.Add(1)
+
+		a.GetLogger().PrintlnWithOptions(
+			level.Warn,
+			customerror.NewFailedToError("audit "+op.String(), customerror.WithError(err)).Error(),
+			sypl.WithFields(logging.ToAPM(ctx, fields.Fields{
+				"operation": op.String(),
+				"id":        id,
+				"target":    target,
+			})),
+		)
+
+		if a.Strict {
+			return err
+		}
+
+		return nil
+	}
+
+	a.counterAudited.Add(1)
+
+	return nil
+}
+
+//////
+// Implements the IStorage interface.
+//////
+
+// Count counts from the backend.
+func (a *Audit) Count(ctx context.Context, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
+	ctx, span := customapm.Trace(ctx, a.GetType(), AuditName, status.Counted.String())
+	defer span.End()
+
+	c, err := a.Backend.Count(ctx, target, prm, options...)
+	if err != nil {
+		return 0, customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterCountedFailed())
+	}
+
+	a.GetCounterCounted().Add(1)
+
+	return c, nil
+}
+
+// Delete deletes from the backend, and records it.
+func (a *Audit) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+	ctx, span := customapm.Trace(ctx, a.GetType(), AuditName, status.Deleted.String())
+	defer span.End()
+
+	before := a.before(ctx, id, target, nil)
+
+	if err := a.Backend.Delete(ctx, id, target, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterDeletedFailed())
+	}
+
+	if err := a.record(ctx, OperationDelete, id, target, before, nil); err != nil {
+		return customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterDeletedFailed())
+	}
+
+	a.GetCounterDeleted().Add(1)
+
+	return nil
+}
+
+// Retrieve retrieves from the backend.
+func (a *Audit) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+	ctx, span := customapm.Trace(ctx, a.GetType(), AuditName, status.Retrieved.String())
+	defer span.End()
+
+	if err := a.Backend.Retrieve(ctx, id, target, v, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterRetrievedFailed())
+	}
+
+	a.GetCounterRetrieved().Add(1)
+
+	return nil
+}
+
+// List lists from the backend.
+func (a *Audit) List(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error {
+	ctx, span := customapm.Trace(ctx, a.GetType(), AuditName, status.Listed.String())
+	defer span.End()
+
+	if err := a.Backend.List(ctx, target, v, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterListedFailed())
+	}
+
+	a.GetCounterListed().Add(1)
+
+	return nil
+}
+
+// Create creates into the backend, and records it.
+func (a *Audit) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+	ctx, span := customapm.Trace(ctx, a.GetType(), AuditName, status.Created.String())
+	defer span.End()
+
+	// Create is insert-only, unless overwriting, when the document may exist.
+	before := a.before(ctx, id, target, v)
+
+	returnedID, err := a.Backend.Create(ctx, id, target, v, prm, options...)
+	if err != nil {
+		return "", customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterCreatedFailed())
+	}
+
+	documentID := returnedID
+	if documentID == "" {
+		documentID = id
+	}
+
+	if err := a.record(ctx, OperationCreate, documentID, target, before, a.after(ctx, documentID, target, v)); err != nil {
+		return returnedID, customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterCreatedFailed())
+	}
+
+	a.GetCounterCreated().Add(1)
+
+	return returnedID, nil
+}
+
+// Update updates the backend, and records it.
+func (a *Audit) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...Func[*update.Update]) error {
+	ctx, span := customapm.Trace(ctx, a.GetType(), AuditName, status.Updated.String())
+	defer span.End()
+
+	before := a.before(ctx, id, target, v)
+
+	if err := a.Backend.Update(ctx, id, target, v, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterUpdatedFailed())
+	}
+
+	if err := a.record(ctx, OperationUpdate, id, target, before, a.after(ctx, id, target, v)); err != nil {
+		return customapm.TraceError(ctx, err, a.GetLogger(), a.GetCounterUpdatedFailed())
+	}
+
+	a.GetCounterUpdated().Add(1)
+
+	return nil
+}
+
+// GetClient returns the backend's client.
+func (a *Audit) GetClient() any {
+	return a.Backend.GetClient()
+}
+
+// GetCounterAudited returns the metric.
+func (a *Audit) GetCounterAudited() *expvar.Int {
+	return a.counterAudited
+}
+
+// GetCounterAuditFailed returns the metric.
+func (a *Audit) GetCounterAuditFailed() *expvar.Int {
+	return a.counterAuditFailed
+}
+
+//////
+// Exported functionalities.
+//////
+
+// WithActor returns a copy of `ctx` carrying the actor performing operations.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with `WithActor`, if any.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+
+	return actor
+}
+
+// AuditHistory lists the audit entries of the document `id` of `target` in
+// the `storageName` storage, oldest first. The trail is listed page by page,
+// `prm.Limit` entries at a time (`DefaultAuditHistoryPageSize` if unset),
+// until exhausted. `prm` is passed to the trail's `List`, use it to narrow the
+// search natively (e.g.: by `document_id`).
+func AuditHistory(
+	ctx context.Context,
+	trail IStorage,
+	auditTarget string,
+	prm *list.List,
+	storageName, target, id string,
+) (AuditEntries, error) {
+	page := list.List{}
+
+	if prm != nil {
+		page = *prm
+	}
+
+	if page.Limit <= 0 {
+		page.Limit = DefaultAuditHistoryPageSize
+	}
+
+	var history AuditEntries
+
+	for {
+		var entries AuditEntries
+
+		if err := trail.List(ctx, auditTarget, &entries, &page); err != nil {
+			return nil, err
+		}
+
+		listed := len(entries)
+
+		history = append(history, slices.DeleteFunc(entries, func(e AuditEntry) bool {
+			return e.Storage != storageName || e.Target != target || e.DocumentID != id
+		})...)
+
+		// Fewer is the last page, more means the trail doesn't paginate.
+		if listed != page.Limit {
+			break
+		}
+
+		page.Offset += page.Limit
+	}
+
+	slices.SortStableFunc(history, func(a, b AuditEntry) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	return history, nil
+}
+
+//////
+// Factory.
+//////
+
+// NewAudit returns a new Audit storage recording the mutations of `backend`
+// to `trail`.
+func NewAudit(ctx context.Context, backend, trail IStorage, options ...AuditFunc) (*Audit, error) {
+	// Enforces IStorage interface implementation.
+	var _ IStorage = (*Audit)(nil)
+
+	s, err := New(ctx, AuditName)
+	if err != nil {
+		return nil, err
+	}
+
+	if backend == nil || trail == nil {
+		return nil, customapm.TraceError(
+			ctx,
+			customerror.NewRequiredError("backend and trail storages"),
+			s.GetLogger(),
+			s.counterInstantiationFailed,
+		)
+	}
+
+	prefix := fmt.Sprintf("%s.%s.%s", Type, AuditName, backend.GetName())
+
+	a := &Audit{
+		Storage: s,
+
+		Backend:   backend,
+		Trail:     trail,
+		Target:    DefaultAuditTarget,
+		ActorFunc: ActorFromContext,
+		NewFunc:   func() any { return new(map[string]any) },
+
+		counterAudited:     metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "audited", DefaultMetricCounterLabel)),
+		counterAuditFailed: metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, status.Failed, DefaultMetricCounterLabel)),
+	}
+
+	for _, option := range options {
+		if err := option(a); err != nil {
+			return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+		}
+	}
+
+	if err := validation.Validate(a); err != nil {
+		return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+	}
+
+	return a, nil
+}
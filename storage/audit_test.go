@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/list"
+)
+
+// newTrailMock builds an append-only Mock trail, listing, and paginating, like
+// the memory storage does.
+func newTrailMock(name string) (*Mock, func() []AuditEntry) {
+	var (
+		mu      sync.Mutex
+		entries []AuditEntry
+	)
+
+	return &Mock{
+		MockCreate: func(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			entries = append(entries, *v.(*AuditEntry))
+
+			return id, nil
+		},
+		MockList: func(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			page := entries
+
+			if prm != nil {
+				page = page[min(prm.Offset, len(page)):]
+
+				if prm.Limit > 0 {
+					page = page[:min(prm.Limit, len(page))]
+				}
+			}
+
+			return ParseToStruct(map[string]any{"items": page}, v)
+		},
+		MockGetName: func() string { return name },
+	}, func() []AuditEntry {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]AuditEntry(nil), entries...)
+	}
+}
+
+func TestAudit_RecordsMutations(t *testing.T) {
+	ctx := WithActor(t.Context(), "alice")
+
+	backend, _ := newKVMock("auditb1")
+	trail, recorded := newTrailMock("auditt1")
+
+	a, err := NewAudit(ctx, backend, trail, WithCaptureBefore(func() any { return &TestDataS{} }))
+	require.NoError(t, err)
+
+	audited := a.GetCounterAudited().Value()
+
+	_, err = a.Create(ctx, "1", "users", &TestDataS{K: "v1"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, a.Update(ctx, "1", "users", &TestDataS{K: "v2"}, nil))
+	require.NoError(t, a.Delete(ctx, "1", "users", nil))
+
+	// Reads aren't recorded.
+	var got TestDataS
+	require.Error(t, a.Retrieve(ctx, "1", "users", &got, nil))
+
+	entries := recorded()
+	require.Len(t, entries, 3)
+
+	for _, e := range entries {
+		assert.NotEmpty(t, e.ID)
+		assert.Equal(t, "alice", e.Actor)
+		assert.Equal(t, "auditb1", e.Storage)
+		assert.Equal(t, "users", e.Target)
+		assert.Equal(t, "1", e.DocumentID)
+		assert.False(t, e.Timestamp.IsZero())
+	}
+
+	assert.Equal(t, OperationCreate, entries[0].Operation)
+	assert.Empty(t, entries[0].Before)
+	assert.JSONEq(t, `{"k":"v1"}`, string(entries[0].After))
+
+	assert.Equal(t, OperationUpdate, entries[1].Operation)
+	assert.JSONEq(t, `{"k":"v1"}`, string(entries[1].Before))
+	assert.JSONEq(t, `{"k":"v2"}`, string(entries[1].After))
+
+	assert.Equal(t, OperationDelete, entries[2].Operation)
+	assert.JSONEq(t, `{"k":"v2"}`, string(entries[2].Before))
+	assert.Empty(t, entries[2].After)
+
+	assert.Equal(t, audited+3, a.GetCounterAudited().Value())
+}
+
+func TestAudit_HistoryAndAt(t *testing.T) {
+	ctx := t.Context()
+
+	backend, _ := newKVMock("auditb2")
+	trail, _ := newTrailMock("auditt2")
+
+	a, err := NewAudit(ctx, backend, trail)
+	require.NoError(t, err)
+
+	_, err = a.Create(ctx, "1", "users", &TestDataS{K: "v1"}, nil)
+	require.NoError(t, err)
+
+	_, err = a.Create(ctx, "2", "users", &TestDataS{K: "other"}, nil)
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+	between := time.Now()
+	time.Sleep(time.Millisecond)
+
+	require.NoError(t, a.Update(ctx, "1", "users", &TestDataS{K: "v2"}, nil))
+	require.NoError(t, a.Delete(ctx, "1", "users", nil))
+
+	history, err := AuditHistory(ctx, trail, DefaultAuditTarget, nil, "auditb2", "users", "1")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	state, ok := history.At(between)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"k":"v1"}`, string(state))
+
+	_, ok = history.At(time.Now())
+	assert.False(t, ok, "deleted")
+
+	_, ok = history.At(time.Time{})
+	assert.False(t, ok, "not created yet")
+}
+
+// The whole trail is searched, not only the first page.
+func TestAudit_HistoryPaginates(t *testing.T) {
+	ctx := t.Context()
+
+	backend, _ := newKVMock("auditb5")
+	trail, _ := newTrailMock("auditt5")
+
+	a, err := NewAudit(ctx, backend, trail)
+	require.NoError(t, err)
+
+	_, err = a.Create(ctx, "1", "users", &TestDataS{K: "v0"}, nil)
+	require.NoError(t, err)
+
+	for range 5 {
+		_, err = a.Create(ctx, "2", "users", &TestDataS{K: "other"}, nil)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, a.Update(ctx, "1", "users", &TestDataS{K: "v1"}, nil))
+
+	history, err := AuditHistory(ctx, trail, DefaultAuditTarget, &list.List{Limit: 2}, "auditb5", "users", "1")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, OperationCreate, history[0].Operation)
+	assert.Equal(t, OperationUpdate, history[1].Operation)
+}
+
+func TestAudit_TrailFailure(t *testing.T) {
+	ctx := t.Context()
+
+	backend, data := newKVMock("auditb3")
+
+	trail := &Mock{
+		MockCreate: func(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+			return "", errors.New("trail down")
+		},
+		MockGetName: func() string { return "auditt3" },
+	}
+
+	a, err := NewAudit(ctx, backend, trail)
+	require.NoError(t, err)
+
+	failed := a.GetCounterAuditFailed().Value()
+
+	// Lenient: the mutation succeeds.
+	_, err = a.Create(ctx, "1", "users", &TestDataS{K: "v1"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, failed+1, a.GetCounterAuditFailed().Value())
+
+	// Strict: the mutation is applied, but fails.
+	strict, err := NewAudit(ctx, backend, trail, WithStrictAudit())
+	require.NoError(t, err)
+
+	require.Error(t, strict.Update(ctx, "1", "users", &TestDataS{K: "v2"}, nil))
+	assert.Equal(t, "v2", data["1"].K)
+	assert.Equal(t, failed+2, strict.GetCounterAuditFailed().Value())
+}
+
+func TestNewAudit_Invalid(t *testing.T) {
+	ctx := t.Context()
+
+	backend, _ := newKVMock("auditb4")
+
+	_, err := NewAudit(ctx, backend, nil)
+	require.Error(t, err)
+
+	_, err = NewAudit(ctx, backend, backend, WithAuditTarget(""))
+	require.Error(t, err)
+}
@@ -0,0 +1,542 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/concurrentloop"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/retrieve"
+	"github.com/thalesfsp/params/v2/update"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// BulkName is the name used by the bulk metrics.
+	BulkName = "bulk"
+
+	// DefaultMetricGaugeLabel is the label of metrics which go up and down.
+	DefaultMetricGaugeLabel = "gauge"
+)
+
+// ErrorClassifierFunc reports whether `err` belongs to a class, e.g.:
+// throttling, or transient errors.
+type ErrorClassifierFunc func(err error) bool
+
+// BulkFunc allows to set bulk options.
+type BulkFunc func(b *Bulk) error
+
+// Bulk bounds the concurrency, and the rate at which the N:1 helpers
+// (CreateManyWith, RetrieveManyWith, UpdateManyWith, DeleteManyWith) hit a
+// storage. A Bulk is safe for concurrent use, share one across calls to bound
+// them all together - e.g. one per connection pool.
+//
+// In adaptive mode, concurrency follows AIMD (additive increase,
+// multiplicative decrease): it's halved when the storage returns a throttling
+// or timeout error, and grows back by one after a window of successes.
+type Bulk struct {
+	// Name identifies the metrics.
+	Name string `json:"name" validate:"required,lowercase,gte=1"`
+
+	// Concurrency is the maximum number of in-flight operations. Zero means
+	// unbounded.
+	Concurrency int `json:"concurrency" validate:"gte=0"`
+
+	// Adaptive enables AIMD. `MinConcurrency` is the floor.
+	Adaptive       bool `json:"adaptive"`
+	MinConcurrency int  `json:"minConcurrency" validate:"gte=0"`
+
+	// IsThrottled classifies errors which make adaptive mode back off.
+	IsThrottled ErrorClassifierFunc `json:"-"`
+
+	// Token bucket.
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	rateMu sync.Mutex
+
+	// Concurrency state.
+	mu        sync.Mutex
+	limit     int
+	inflight  int
+	successes int
+	completed int
+	window    int
+	changed   chan struct{}
+
+	// Metrics.
+	gaugeInFlight    *expvar.Int `json:"-" validate:"required"`
+	gaugeQueued      *expvar.Int `json:"-" validate:"required"`
+	gaugeLimit       *expvar.Int `json:"-" validate:"required"`
+	counterThrottled *expvar.Int `json:"-" validate:"required,gte=0"`
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithConcurrency bounds the number of in-flight operations to `n`.
+func WithConcurrency(n int) BulkFunc {
+	return func(b *Bulk) error {
+		if n <= 0 {
+			return customerror.NewInvalidError("concurrency, must be greater than 0")
+		}
+
+		b.Concurrency = n
+
+		return nil
+	}
+}
+
+// WithRateLimit limits operations to `rps` per second, allowing bursts of up
+// to `burst` operations (token bucket).
+func WithRateLimit(rps float64, burst int) BulkFunc {
+	return func(b *Bulk) error {
+		if rps <= 0 || burst <= 0 {
+			return customerror.NewInvalidError("rate limit, rps and burst must be greater than 0")
+		}
+
+		b.rate = rps
+		b.burst = float64(burst)
+		b.tokens = float64(burst)
+
+		return nil
+	}
+}
+
+// WithAdaptive enables AIMD concurrency between `minConcurrency`, and
+// `maxConcurrency`. `isThrottled` extends the built-in classification (timeouts,
+// HTTP 429, and 503), e.g.: `dynamodb.IsProvisionedThroughputExceededError`.
+func WithAdaptive(minConcurrency, maxConcurrency int, isThrottled ErrorClassifierFunc) BulkFunc {
+	return func(b *Bulk) error {
+		if minConcurrency <= 0 || maxConcurrency < minConcurrency {
+			return customerror.NewInvalidError("adaptive concurrency, must be 0 < min <= max")
+		}
+
+		b.Adaptive = true
+		b.MinConcurrency = minConcurrency
+		b.Concurrency = maxConcurrency
+		b.IsThrottled = isThrottled
+
+		return nil
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// IsTimeoutOrThrottled is the built-in throttling classifier. It matches
+// context deadlines, network timeouts, and HTTP 429, and 503 errors.
+func IsTimeoutOrThrottled(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return HasStatusCode(err, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+}
+
+// throttled reports whether `err` should make adaptive mode back off.
+func (b *Bulk) throttled(err error) bool {
+	if IsTimeoutOrThrottled(err) {
+		return true
+	}
+
+	return b.IsThrottled != nil && b.IsThrottled(err)
+}
+
+// waitToken blocks until the token bucket allows one more operation.
+func (b *Bulk) waitToken(ctx context.Context) error {
+	if b.rate == 0 {
+		return nil
+	}
+
+	b.rateMu.Lock()
+
+	now := time.Now()
+
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+
+	b.last = now
+
+	// Reserve a token, possibly going negative - the deficit is how long the
+	// caller has to wait.
+	b.tokens--
+	deficit := -b.tokens
+
+	b.rateMu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(time.Duration(deficit / b.rate * float64(time.Second)))
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Give the reservation back.
+		b.rateMu.Lock()
+		b.tokens++
+		b.rateMu.Unlock()
+
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// acquire blocks until there's a free slot, and a token.
+func (b *Bulk) acquire(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+
+		if b.limit == 0 || b.inflight < b.limit {
+			b.inflight++
+			b.mu.Unlock()
+
+			b.gaugeInFlight.Add(1)
+
+			break
+		}
+
+		changed := b.changed
+
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+
+	if err := b.waitToken(ctx); err != nil {
+		b.releaseWith(err, false)
+
+		return err
+	}
+
+	return nil
+}
+
+// release frees a slot, adjusting the limit according to `err` in adaptive
+// mode.
+func (b *Bulk) release(err error) {
+	b.releaseWith(err, true)
+}
+
+// releaseWith frees a slot. The limit is only adjusted if `adjust` is true.
+func (b *Bulk) releaseWith(err error, adjust bool) {
+	b.mu.Lock()
+
+	b.inflight--
+
+	if b.Adaptive && adjust {
+		b.completed++
+
+		switch {
+		case b.throttled(err):
+			b.counterThrottled.Add(1)
+
+			// Back off at most once per window: operations already in flight
+			// when the limit was decreased were dispatched under the old limit,
+			// so their errors must not collapse the limit to the floor.
+			if b.completed > b.window {
+				b.limit = max(b.MinConcurrency, b.limit/2)
+				b.window = b.inflight
+				b.completed = 0
+				b.successes = 0
+			}
+		case err == nil:
+			b.successes++
+
+			if b.successes >= b.limit && b.limit < b.Concurrency {
+				b.limit++
+				b.successes = 0
+			}
+		}
+
+		b.gaugeLimit.Set(int64(b.limit))
+	}
+
+	close(b.changed)
+	b.changed = make(chan struct{})
+
+	b.mu.Unlock()
+
+	b.gaugeInFlight.Add(-1)
+}
+
+// do runs `fn` within a slot.
+func (b *Bulk) do(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := b.acquire(ctx)
+
+	b.gaugeQueued.Add(-1)
+
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+
+	b.release(err)
+
+	return err
+}
+
+// batchSize is the number of goroutines concurrentloop should run. Bounding
+// it avoids parking one goroutine per item when `Concurrency` is set.
+func (b *Bulk) batchSize() []concurrentloop.Func {
+	if b.Concurrency == 0 {
+		return nil
+	}
+
+	return []concurrentloop.Func{concurrentloop.WithBatchSize(b.Concurrency)}
+}
+
+// GetGaugeInFlight returns the metric.
+func (b *Bulk) GetGaugeInFlight() *expvar.Int {
+	return b.gaugeInFlight
+}
+
+// GetGaugeQueued returns the metric.
+func (b *Bulk) GetGaugeQueued() *expvar.Int {
+	return b.gaugeQueued
+}
+
+// GetGaugeLimit returns the metric.
+func (b *Bulk) GetGaugeLimit() *expvar.Int {
+	return b.gaugeLimit
+}
+
+// GetCounterThrottled returns the metric.
+func (b *Bulk) GetCounterThrottled() *expvar.Int {
+	return b.counterThrottled
+}
+
+//////
+// Factory.
+//////
+
+// NewBulk returns a new Bulk. `name` identifies its metrics.
+func NewBulk(name string, options ...BulkFunc) (*Bulk, error) {
+	prefix := fmt.Sprintf("%s.%s.%s", Type, BulkName, name)
+
+	b := &Bulk{
+		Name: name,
+
+		changed: make(chan struct{}),
+
+		gaugeInFlight:    metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "inflight", DefaultMetricGaugeLabel)),
+		gaugeQueued:      metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "queued", DefaultMetricGaugeLabel)),
+		gaugeLimit:       metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "limit", DefaultMetricGaugeLabel)),
+		counterThrottled: metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "throttled", DefaultMetricCounterLabel)),
+	}
+
+	for _, option := range options {
+		if err := option(b); err != nil {
+			return nil, err
+		}
+	}
+
+	b.limit = b.Concurrency
+	b.gaugeLimit.Set(int64(b.limit))
+
+	if err := validation.Validate(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+//////
+// N:1 Operations.
+//////
+
+// bulkMap is concurrentloop.Map bounded by `b`. A nil `b` is unbounded.
+func bulkMap[T any, R any](ctx context.Context, b *Bulk, items []T, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	if b == nil {
+		r, errs := concurrentloop.Map(ctx, items, fn, concurrentloop.WithRemoveZeroValues(false))
+		if len(errs) > 0 {
+			return nil, errs
+		}
+
+		return r, nil
+	}
+
+	// Items not yet dispatched are queued. Whatever is left - because of
+	// cancellation - is given back on return. concurrentloop may return
+	// before every goroutine started, late ones must not touch the gauge.
+	var (
+		mu      sync.Mutex
+		started int64
+		done    bool
+	)
+
+	b.gaugeQueued.Add(int64(len(items)))
+
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		done = true
+
+		b.gaugeQueued.Add(started - int64(len(items)))
+	}()
+
+	r, errs := concurrentloop.Map(ctx, items, func(ctx context.Context, item T) (R, error) {
+		var result R
+
+		mu.Lock()
+
+		if done {
+			mu.Unlock()
+
+			return result, ctx.Err()
+		}
+
+		started++
+
+		mu.Unlock()
+
+		err := b.do(ctx, func(ctx context.Context) error {
+			var err error
+
+			result, err = fn(ctx, item)
+
+			return err
+		})
+
+		return result, err
+	}, append(b.batchSize(), concurrentloop.WithRemoveZeroValues(false))...)
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return r, nil
+}
+
+// keyed is a map entry.
+type keyed[T any] struct {
+	key  string
+	item T
+}
+
+// bulkMapM is like bulkMap, but for maps.
+func bulkMapM[T any, R any](ctx context.Context, b *Bulk, items map[string]T, fn func(ctx context.Context, key string, item T) (R, error)) ([]R, error) {
+	if b == nil {
+		r, errs := concurrentloop.MapM(ctx, items, fn, concurrentloop.WithRemoveZeroValues(false))
+		if len(errs) > 0 {
+			return nil, errs
+		}
+
+		return r, nil
+	}
+
+	entries := make([]keyed[T], 0, len(items))
+
+	for k, v := range items {
+		entries = append(entries, keyed[T]{key: k, item: v})
+	}
+
+	return bulkMap(ctx, b, entries, func(ctx context.Context, e keyed[T]) (R, error) {
+		return fn(ctx, e.key, e.item)
+	})
+}
+
+// CreateManyWith is like CreateMany, bounded by `b`.
+func CreateManyWith[T any](
+	ctx context.Context,
+	b *Bulk,
+	str IStorage,
+	target string,
+	prm *create.Create,
+	itemsMap map[string]T,
+) ([]string, error) {
+	return bulkMapM(ctx, b, itemsMap, func(ctx context.Context, key string, item T) (string, error) {
+		id, err := Create(ctx, str, key, target, item, prm)
+		if err != nil {
+			return "", err
+		}
+
+		return id, nil
+	})
+}
+
+// DeleteManyWith is like DeleteMany, bounded by `b`.
+func DeleteManyWith(
+	ctx context.Context,
+	b *Bulk,
+	str IStorage,
+	target string,
+	prm *delete.Delete,
+	ids ...string,
+) ([]bool, error) {
+	return bulkMap(ctx, b, ids, func(ctx context.Context, id string) (bool, error) {
+		if err := Delete(ctx, str, id, target, prm); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	})
+}
+
+// RetrieveManyWith is like RetrieveMany, bounded by `b`.
+func RetrieveManyWith[T any](
+	ctx context.Context,
+	b *Bulk,
+	str IStorage,
+	target string,
+	prm *retrieve.Retrieve,
+	ids ...string,
+) ([]T, error) {
+	return bulkMap(ctx, b, ids, func(ctx context.Context, id string) (T, error) {
+		return Retrieve[T](ctx, str, id, target, prm)
+	})
+}
+
+// UpdateManyWith is like UpdateMany, bounded by `b`.
+func UpdateManyWith[T any](
+	ctx context.Context,
+	b *Bulk,
+	str IStorage,
+	target string,
+	prm *update.Update,
+	itemsMap map[string]T,
+) ([]bool, error) {
+	return bulkMapM(ctx, b, itemsMap, func(ctx context.Context, key string, item T) (bool, error) {
+		if err := Update(ctx, str, key, target, item, prm); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	})
+}
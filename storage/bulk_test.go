@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/retrieve"
+)
+
+// newSlowMock builds a Mock whose Retrieve, and Delete take `d` and record
+// the maximum number of concurrent calls. `fail` decides the returned error.
+func newSlowMock(d time.Duration, peak *atomic.Int64, fail func(n int64) error) *Mock {
+	var current, calls atomic.Int64
+
+	op := func() error {
+		n := current.Add(1)
+		defer current.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		time.Sleep(d)
+
+		if fail != nil {
+			return fail(calls.Add(1))
+		}
+
+		return nil
+	}
+
+	return &Mock{
+		MockRetrieve: func(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+			return op()
+		},
+		MockDelete: func(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+			return op()
+		},
+		MockGetName: func() string { return "slow" },
+	}
+}
+
+func bulkIDs(n int) []string {
+	ids := make([]string, 0, n)
+
+	for i := range n {
+		ids = append(ids, fmt.Sprintf("id-%d", i))
+	}
+
+	return ids
+}
+
+func TestBulk_Concurrency(t *testing.T) {
+	var peak atomic.Int64
+
+	b, err := NewBulk("test-concurrency", WithConcurrency(3))
+	require.NoError(t, err)
+
+	r, err := DeleteManyWith(t.Context(), b, newSlowMock(5*time.Millisecond, &peak, nil), "t", nil, bulkIDs(30)...)
+	require.NoError(t, err)
+	assert.Len(t, r, 30)
+
+	assert.LessOrEqual(t, peak.Load(), int64(3))
+	assert.Equal(t, int64(0), b.GetGaugeInFlight().Value())
+	assert.Equal(t, int64(0), b.GetGaugeQueued().Value())
+}
+
+func TestBulk_RateLimit(t *testing.T) {
+	var peak atomic.Int64
+
+	b, err := NewBulk("test-rate", WithRateLimit(200, 1))
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	_, err = RetrieveManyWith[TestDataS](t.Context(), b, newSlowMock(0, &peak, nil), "t", nil, bulkIDs(11)...)
+	require.NoError(t, err)
+
+	// One token upfront, 10 more at 200/s: at least ~50ms.
+	assert.GreaterOrEqual(t, time.Since(now), 45*time.Millisecond)
+}
+
+func TestBulk_AdaptiveBacksOff(t *testing.T) {
+	var peak atomic.Int64
+
+	b, err := NewBulk("test-adaptive", WithAdaptive(1, 8, nil), WithConcurrency(8))
+	require.NoError(t, err)
+
+	// Metrics are process-wide, so compare against the current value.
+	before := b.GetCounterThrottled().Value()
+
+	// Every call is throttled: the limit must go down to the floor.
+	throttled := newSlowMock(time.Millisecond, &peak, func(n int64) error {
+		return customerror.NewHTTPError(http.StatusTooManyRequests)
+	})
+
+	_, err = DeleteManyWith(t.Context(), b, throttled, "t", nil, bulkIDs(40)...)
+	require.Error(t, err)
+
+	assert.Equal(t, int64(1), b.GetGaugeLimit().Value())
+	assert.Equal(t, before+40, b.GetCounterThrottled().Value())
+
+	// Successes grow it back.
+	_, err = DeleteManyWith(t.Context(), b, newSlowMock(0, &peak, nil), "t", nil, bulkIDs(40)...)
+	require.NoError(t, err)
+
+	assert.Greater(t, b.GetGaugeLimit().Value(), int64(1))
+}
+
+func TestBulk_CustomThrottleClassifier(t *testing.T) {
+	errThrottled := customerror.NewFailedToError("provisioned throughput exceeded")
+
+	b, err := NewBulk("test-classifier", WithAdaptive(2, 4, func(err error) bool {
+		return err == errThrottled
+	}))
+	require.NoError(t, err)
+
+	var peak atomic.Int64
+
+	_, _ = DeleteManyWith(t.Context(), b, newSlowMock(0, &peak, func(n int64) error {
+		return errThrottled
+	}), "t", nil, bulkIDs(4)...)
+
+	assert.Equal(t, int64(2), b.GetGaugeLimit().Value())
+}
+
+func TestBulk_InvalidOptions(t *testing.T) {
+	_, err := NewBulk("test-invalid", WithConcurrency(0))
+	assert.Error(t, err)
+
+	_, err = NewBulk("test-invalid", WithRateLimit(0, 1))
+	assert.Error(t, err)
+
+	_, err = NewBulk("test-invalid", WithAdaptive(4, 2, nil))
+	assert.Error(t, err)
+
+	_, err = NewBulk("")
+	assert.Error(t, err)
+}
+
+// Canceling the context stops dispatching, and leaves no dangling gauges.
+func TestBulk_ContextCanceled(t *testing.T) {
+	var peak atomic.Int64
+
+	b, err := NewBulk("test-canceled", WithConcurrency(1))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = DeleteManyWith(ctx, b, newSlowMock(10*time.Millisecond, &peak, nil), "t", nil, bulkIDs(50)...)
+	require.Error(t, err)
+
+	assert.Eventually(t, func() bool {
+		return b.GetGaugeInFlight().Value() == 0 && b.GetGaugeQueued().Value() == 0
+	}, time.Second, 10*time.Millisecond)
+}
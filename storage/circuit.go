@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/eapache/go-resiliency/breaker"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// verdict is how an outcome affects the circuit.
+type verdict int
+
+const (
+	// verdictSuccess counts towards closing a half-open circuit.
+	verdictSuccess verdict = iota
+
+	// verdictFailure counts towards opening the circuit.
+	verdictFailure
+
+	// verdictNeutral doesn't affect the circuit, e.g.: a permanent error
+	// means the backend is reachable, but not that it's healthy.
+	verdictNeutral
+)
+
+// circuit is a circuit breaker with the semantics of `breaker.Breaker`: from
+// closed, it opens if `errorThreshold` failures are seen without a
+// failure-free `timeout`. From open, it half-opens after `timeout`. From
+// half-open, it closes after `successThreshold` consecutive successes, or
+// opens on a single failure. Unlike `breaker.Breaker`, outcomes can be
+// neutral.
+type circuit struct {
+	errorThreshold, successThreshold int
+	timeout                          time.Duration
+
+	mu                sync.Mutex
+	state             breaker.State
+	errors, successes int
+	lastError         time.Time
+	openedAt          time.Time
+}
+
+//////
+// Methods.
+//////
+
+// setState changes the state, resetting the counts. Must be called with `mu`
+// held.
+func (c *circuit) setState(state breaker.State) {
+	c.state = state
+	c.errors = 0
+	c.successes = 0
+
+	if state == breaker.Open {
+		c.openedAt = time.Now()
+	}
+}
+
+// currentState returns the state, half-opening the circuit if it's due. Must
+// be called with `mu` held.
+func (c *circuit) currentState() breaker.State {
+	if c.state == breaker.Open && time.Since(c.openedAt) >= c.timeout {
+		c.setState(breaker.HalfOpen)
+	}
+
+	return c.state
+}
+
+// GetState returns the state.
+func (c *circuit) GetState() breaker.State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.currentState()
+}
+
+// Run runs `work` unless the circuit is open, returning
+// `breaker.ErrBreakerOpen`. `judge` tells how `work`'s error affects the
+// circuit.
+func (c *circuit) Run(work func() error, judge func(err error) verdict) error {
+	if c.GetState() == breaker.Open {
+		return breaker.ErrBreakerOpen
+	}
+
+	err := work()
+
+	c.record(judge(err))
+
+	return err
+}
+
+// record updates the circuit with the verdict of an outcome.
+func (c *circuit) record(v verdict) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.currentState()
+
+	switch v {
+	case verdictSuccess:
+		if state == breaker.HalfOpen {
+			c.successes++
+
+			if c.successes == c.successThreshold {
+				c.setState(breaker.Closed)
+			}
+		}
+	case verdictFailure:
+		if c.errors > 0 && time.Since(c.lastError) > c.timeout {
+			c.errors = 0
+		}
+
+		switch state {
+		case breaker.Closed:
+			c.errors++
+
+			if c.errors == c.errorThreshold {
+				c.setState(breaker.Open)
+			} else {
+				c.lastError = time.Now()
+			}
+		case breaker.HalfOpen:
+			c.setState(breaker.Open)
+		}
+	}
+}
+
+//////
+// Factory.
+//////
+
+// newCircuit returns a new, closed, circuit.
+func newCircuit(errorThreshold, successThreshold int, timeout time.Duration) *circuit {
+	return &circuit{
+		errorThreshold:   errorThreshold,
+		successThreshold: successThreshold,
+		timeout:          timeout,
+	}
+}
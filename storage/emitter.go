@@ -0,0 +1,373 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/sypl/v2"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// EmitterName is the name of the event emitter.
+	EmitterName = "emitter"
+
+	// CloudEventsSpecVersion is the CloudEvents version of emitted events.
+	CloudEventsSpecVersion = "1.0"
+
+	// CloudEventTypePrefix prefixes the type of emitted events, e.g.:
+	// `dal.create`.
+	CloudEventTypePrefix = "dal."
+
+	// DefaultEmitterBuffer is the default number of events waiting for
+	// delivery to a sink before new ones are dropped.
+	DefaultEmitterBuffer = 1024
+
+	// DefaultEmitterTimeout is the default time a sink has to accept an event.
+	DefaultEmitterTimeout = 30 * time.Second
+)
+
+// CloudEvent is a CloudEvents-shaped mutation event.
+//
+// SEE https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/spec.md
+type CloudEvent struct {
+	// SpecVersion is `CloudEventsSpecVersion`.
+	SpecVersion string `json:"specversion" db:"specversion" bson:"specversion"`
+
+	// ID of the event.
+	ID string `json:"id" db:"id" bson:"_id"`
+
+	// Source is the name of the storage.
+	Source string `json:"source" db:"source" bson:"source"`
+
+	// Type is `dal.<operation>`, e.g.: `dal.update`.
+	Type string `json:"type" db:"type" bson:"type"`
+
+	// Subject is `<target>/<id>`, or the target if there's no ID, e.g.: when
+	// created with a generated one.
+	Subject string `json:"subject,omitempty" db:"subject" bson:"subject,omitempty"`
+
+	// Time of the mutation.
+	Time time.Time `json:"time" db:"time" bson:"time"`
+
+	// DataContentType is `application/json`, if there's data.
+	DataContentType string `json:"datacontenttype,omitempty" db:"datacontenttype" bson:"datacontenttype,omitempty"`
+
+	// Data is the written document, normalized to JSON. Empty for deletions.
+	Data json.RawMessage `json:"data,omitempty" db:"data" bson:"data,omitempty"`
+}
+
+// ISink receives emitted events.
+type ISink interface {
+	// Send delivers `event`.
+	Send(ctx context.Context, event *CloudEvent) error
+}
+
+// SinkFunc is a function which implements ISink.
+type SinkFunc func(ctx context.Context, event *CloudEvent) error
+
+// sinkQueue is a sink, and the events waiting for delivery to it.
+type sinkQueue struct {
+	sink   ISink
+	events chan *CloudEvent
+}
+
+// EmitterFunc allows to set emitter options.
+type EmitterFunc func(e *Emitter) error
+
+// Emitter emits an event after each successful mutation (create, update,
+// delete) of the storages it's set to (`WithEmitter`), and delivers it,
+// asynchronously, to every sink. Each sink has its own bounded queue, and
+// goroutine, so a slow sink doesn't hold the others back: when its queue is
+// full, new events are dropped for that sink only, and counted.
+//
+// NOTE: Delivery is at-most-once, use the outbox (`WithOutbox`) when events
+// must not be lost.
+type Emitter struct {
+	// Name of the emitter, used in metrics.
+	Name string `json:"name" validate:"required"`
+
+	// Sinks events are delivered to.
+	Sinks []ISink `json:"-" validate:"required,gt=0"`
+
+	// Buffer is the number of events waiting for delivery to a sink before
+	// new ones are dropped.
+	Buffer int `json:"buffer" validate:"gt=0"`
+
+	// Timeout is the time a sink has to accept an event.
+	Timeout time.Duration `json:"timeout" validate:"gt=0"`
+
+	queues []*sinkQueue
+	closed bool
+	done   chan struct{}
+	logger sypl.ISypl
+	mu     sync.RWMutex
+
+	// Metrics.
+	counterEmitted    *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterDropped    *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterSinkFailed *expvar.Int `json:"-" validate:"required,gte=0"`
+}
+
+//////
+// Methods.
+//////
+
+// Send calls `f`.
+func (f SinkFunc) Send(ctx context.Context, event *CloudEvent) error {
+	return f(ctx, event)
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithEmitterBuffer sets the number of events waiting for delivery to a sink
+// before new ones are dropped. Default is `DefaultEmitterBuffer`.
+func WithEmitterBuffer(size int) EmitterFunc {
+	return func(e *Emitter) error {
+		e.Buffer = size
+
+		return nil
+	}
+}
+
+// WithEmitterTimeout sets the time a sink has to accept an event. Default is
+// `DefaultEmitterTimeout`.
+func WithEmitterTimeout(timeout time.Duration) EmitterFunc {
+	return func(e *Emitter) error {
+		e.Timeout = timeout
+
+		return nil
+	}
+}
+
+// WithEmitter emits the storage's mutations through `e`.
+func WithEmitter(e *Emitter) ConfigFunc {
+	return func(s *Storage) error {
+		if e == nil {
+			return customerror.NewRequiredError("emitter")
+		}
+
+		s.emitter = e
+
+		return nil
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// emitterGetter is implemented by storages which can have an emitter, e.g.:
+// the ones embedding `Storage`.
+type emitterGetter interface {
+	GetEmitter() *Emitter
+}
+
+// emitMutation emits the successful mutation observed by `o`, if `o`'s storage
+// has an emitter.
+func (o Observation) emitMutation(payload any) {
+	switch o.operation {
+	case OperationCreate, OperationUpdate, OperationDelete:
+	default:
+		return
+	}
+
+	getter, ok := o.storage.(emitterGetter)
+	if !ok || getter.GetEmitter() == nil {
+		return
+	}
+
+	if o.operation == OperationDelete {
+		payload = nil
+	}
+
+	event, err := NewCloudEvent(o.name, o.operation, o.target, o.id, payload)
+	if err != nil {
+		getter.GetEmitter().logger.Errorln("failed to build event:", err)
+
+		return
+	}
+
+	getter.GetEmitter().Emit(event)
+}
+
+// run delivers the events queued for `q`'s sink until the emitter is closed.
+func (e *Emitter) run(q *sinkQueue) {
+	for event := range q.events {
+		ctx, cancel := context.WithTimeout(context.Background(), e.Timeout)
+
+		if err := q.sink.Send(ctx, event); err != nil {
+			e.counterSinkFailed.Add(1)
+
+			e.logger.Errorln("failed to deliver event", event.ID, "to sink:", err)
+		}
+
+		cancel()
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// NewCloudEvent returns the event of the `operation` of `target`/`id`, by
+// `source`, which wrote `data`, if any.
+func NewCloudEvent(source string, operation Operation, target, id string, data any) (*CloudEvent, error) {
+	event := &CloudEvent{
+		SpecVersion: CloudEventsSpecVersion,
+		ID:          uuid.NewString(),
+		Source:      source,
+		Type:        CloudEventTypePrefix + operation.String(),
+		Subject:     target,
+		Time:        time.Now().UTC(),
+	}
+
+	if id != "" {
+		event.Subject = target + "/" + id
+	}
+
+	if data != nil {
+		b, err := normalizeJSON(data)
+		if err != nil {
+			return nil, customerror.NewFailedToError("marshal event data", customerror.WithError(err))
+		}
+
+		event.Data = b
+		event.DataContentType = "application/json"
+	}
+
+	return event, nil
+}
+
+// Emit queues `event` for delivery to every sink. Returns false if it was
+// dropped for any, because its queue is full, or the emitter closed.
+func (e *Emitter) Emit(event *CloudEvent) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closed {
+		e.counterDropped.Add(int64(len(e.queues)))
+
+		return false
+	}
+
+	queued := 0
+
+	for _, q := range e.queues {
+		select {
+		case q.events <- event:
+			queued++
+		default:
+			e.counterDropped.Add(1)
+		}
+	}
+
+	if queued > 0 {
+		e.counterEmitted.Add(1)
+	}
+
+	return queued == len(e.queues)
+}
+
+// Close stops accepting events, and waits until the queued ones are
+// delivered, or `ctx` is done.
+func (e *Emitter) Close(ctx context.Context) error {
+	e.mu.Lock()
+
+	if !e.closed {
+		e.closed = true
+
+		for _, q := range e.queues {
+			close(q.events)
+		}
+	}
+
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetCounterEmitted returns the metric of events queued for at least a sink.
+func (e *Emitter) GetCounterEmitted() *expvar.Int {
+	return e.counterEmitted
+}
+
+// GetCounterDropped returns the metric of dropped events, per sink.
+func (e *Emitter) GetCounterDropped() *expvar.Int {
+	return e.counterDropped
+}
+
+// GetCounterSinkFailed returns the metric of failed deliveries.
+func (e *Emitter) GetCounterSinkFailed() *expvar.Int {
+	return e.counterSinkFailed
+}
+
+//////
+// Factory.
+//////
+
+// NewEmitter returns a new, started Emitter delivering events to `sinks`.
+// `name` identifies its metrics. Call `Close` to stop it.
+func NewEmitter(name string, sinks []ISink, options ...EmitterFunc) (*Emitter, error) {
+	e := &Emitter{
+		Name:  name,
+		Sinks: sinks,
+
+		Buffer:  DefaultEmitterBuffer,
+		Timeout: DefaultEmitterTimeout,
+
+		done:   make(chan struct{}),
+		logger: logging.Get().New(EmitterName).SetTags(Type, EmitterName, name),
+
+		counterEmitted:    metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, EmitterName, name+".emitted", DefaultMetricCounterLabel)),
+		counterDropped:    metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, EmitterName, name+".dropped", DefaultMetricCounterLabel)),
+		counterSinkFailed: metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, EmitterName, name+".sink.failed", DefaultMetricCounterLabel)),
+	}
+
+	for _, option := range options {
+		if err := option(e); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validation.Validate(e); err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+
+	for _, sink := range e.Sinks {
+		q := &sinkQueue{sink: sink, events: make(chan *CloudEvent, e.Buffer)}
+
+		e.queues = append(e.queues, q)
+
+		wg.Go(func() { e.run(q) })
+	}
+
+	go func() {
+		wg.Wait()
+
+		close(e.done)
+	}()
+
+	return e, nil
+}
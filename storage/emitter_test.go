@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/create"
+)
+
+// emittingMock is a Mock with the emitter of `Storage`.
+type emittingMock struct {
+	*Mock
+
+	storage *Storage
+}
+
+func (m *emittingMock) GetEmitter() *Emitter {
+	return m.storage.GetEmitter()
+}
+
+func TestNewCloudEvent(t *testing.T) {
+	event, err := NewCloudEvent("memory", OperationUpdate, "users", "1", &TestDataS{K: "v"})
+	require.NoError(t, err)
+
+	assert.Equal(t, CloudEventsSpecVersion, event.SpecVersion)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, "memory", event.Source)
+	assert.Equal(t, "dal.update", event.Type)
+	assert.Equal(t, "users/1", event.Subject)
+	assert.Equal(t, "application/json", event.DataContentType)
+	assert.JSONEq(t, `{"k":"v"}`, string(event.Data))
+
+	event, err = NewCloudEvent("memory", OperationDelete, "users", "", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "users", event.Subject)
+	assert.Empty(t, event.Data)
+	assert.Empty(t, event.DataContentType)
+}
+
+func TestEmitter(t *testing.T) {
+	sink := NewChannelSink(10)
+
+	e, err := NewEmitter("emittert1", []ISink{sink})
+	require.NoError(t, err)
+
+	s, err := New(t.Context(), "emitterstorage1", WithEmitter(e))
+	require.NoError(t, err)
+
+	assert.Same(t, e, s.GetEmitter())
+
+	m := &emittingMock{Mock: &Mock{MockGetName: s.GetName}, storage: s}
+
+	for _, op := range []Operation{OperationCreate, OperationRetrieve, OperationDelete} {
+		_, o := Observe(t.Context(), m, op, "users", "1")
+
+		require.NoError(t, o.Done(&TestDataS{K: "v"}, nil, nil))
+	}
+
+	// Failures aren't emitted.
+	_, o := Observe(t.Context(), m, OperationUpdate, "users", "1")
+	require.Error(t, o.Done(nil, ErrNotFound, nil))
+
+	require.NoError(t, e.Close(t.Context()))
+
+	created := <-sink.Events()
+	assert.Equal(t, "dal.create", created.Type)
+	assert.Equal(t, "emitterstorage1", created.Source)
+	assert.JSONEq(t, `{"k":"v"}`, string(created.Data))
+
+	deleted := <-sink.Events()
+	assert.Equal(t, "dal.delete", deleted.Type)
+	assert.Empty(t, deleted.Data)
+
+	assert.Empty(t, sink.Events())
+
+	// Closed emitters drop events.
+	dropped := e.GetCounterDropped().Value()
+
+	assert.False(t, e.Emit(created))
+	assert.Equal(t, dropped+1, e.GetCounterDropped().Value())
+}
+
+func TestEmitter_DropsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+
+	e, err := NewEmitter("emittert2", []ISink{SinkFunc(func(context.Context, *CloudEvent) error {
+		<-release
+
+		return nil
+	})}, WithEmitterBuffer(1))
+	require.NoError(t, err)
+
+	event, err := NewCloudEvent("memory", OperationCreate, "users", "1", nil)
+	require.NoError(t, err)
+
+	dropped := e.GetCounterDropped().Value()
+
+	// One is being delivered, one is buffered, the rest are dropped.
+	accepted := 0
+
+	for range 10 {
+		if e.Emit(event) {
+			accepted++
+		}
+	}
+
+	assert.LessOrEqual(t, accepted, 2)
+	assert.Equal(t, dropped+int64(10-accepted), e.GetCounterDropped().Value())
+
+	close(release)
+
+	require.NoError(t, e.Close(t.Context()))
+
+	_, err = NewEmitter("emittert3", nil)
+	require.Error(t, err)
+}
+
+// A slow sink drops its own events only.
+func TestEmitter_SlowSinkIsIsolated(t *testing.T) {
+	release := make(chan struct{})
+
+	slow := SinkFunc(func(context.Context, *CloudEvent) error {
+		<-release
+
+		return nil
+	})
+
+	fast := NewChannelSink(10)
+
+	e, err := NewEmitter("emittert4", []ISink{slow, fast}, WithEmitterBuffer(1))
+	require.NoError(t, err)
+
+	event, err := NewCloudEvent("memory", OperationCreate, "users", "1", nil)
+	require.NoError(t, err)
+
+	for range 5 {
+		e.Emit(event)
+
+		// Lets the fast sink drain its queue.
+		require.Eventually(t, func() bool { return len(fast.Events()) > 0 }, time.Second, time.Millisecond)
+
+		<-fast.Events()
+	}
+
+	close(release)
+
+	require.NoError(t, e.Close(t.Context()))
+}
+
+func TestWebhookSink(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, CloudEventsContentType, r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		// Fails once.
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		var event CloudEvent
+
+		assert.NoError(t, shared.Decode(r.Body, &event))
+		assert.Equal(t, "dal.create", event.Type)
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(
+		server.URL,
+		WithWebhookHeader("Authorization", "Bearer token"),
+		WithWebhookRetry(3, time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	event, err := NewCloudEvent("memory", OperationCreate, "users", "1", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Send(t.Context(), event))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Client errors aren't retried.
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	sink.URL = rejecting.URL
+
+	require.Error(t, sink.Send(t.Context(), event))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2"} {
+		event, err := NewCloudEvent("memory", OperationCreate, "users", id, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Send(t.Context(), event))
+	}
+
+	require.NoError(t, sink.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	subjects := []string{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event CloudEvent
+
+		require.NoError(t, shared.Unmarshal(scanner.Bytes(), &event))
+
+		subjects = append(subjects, event.Subject)
+	}
+
+	assert.Equal(t, []string{"users/1", "users/2"}, subjects)
+}
+
+func TestStorageSink(t *testing.T) {
+	var created *CloudEvent
+
+	sink, err := NewStorageSink(&Mock{
+		MockCreate: func(_ context.Context, id, target string, v any, _ *create.Create, _ ...Func[*create.Create]) (string, error) {
+			assert.Equal(t, "events", target)
+
+			created = v.(*CloudEvent)
+
+			return id, nil
+		},
+	}, "events")
+	require.NoError(t, err)
+
+	event, err := NewCloudEvent("memory", OperationCreate, "users", "1", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Send(t.Context(), event))
+	assert.Same(t, event, created)
+
+	_, err = NewStorageSink(nil, "events")
+	require.Error(t, err)
+}
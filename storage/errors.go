@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// Error kinds. Every storage maps its native errors to one of these, so
+// callers can portably check them with `errors.Is`, e.g.:
+//
+//	if errors.Is(err, storage.ErrNotFound) { ... }
+var (
+	// ErrNotFound is returned when the target, or the document doesn't exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrAlreadyExists is returned when creating a document which exists.
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrConflict is returned on concurrent modifications, e.g.: deadlocks,
+	// serialization failures, version conflicts, failed conditions.
+	ErrConflict = errors.New("conflict")
+
+	// ErrInvalidArgument is returned when the request is malformed, e.g.:
+	// missing id, constraint violations.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrUnavailable is returned when the storage can't be reached, or is
+	// overloaded. Usually transient.
+	ErrUnavailable = errors.New("unavailable")
+
+	// ErrTimeout is returned when the operation timed out.
+	ErrTimeout = errors.New("timeout")
+
+	// ErrUnsupported is returned when the storage doesn't support the
+	// operation, or the params.
+	ErrUnsupported = errors.New("unsupported")
+
+	// ErrPermissionDenied is returned when the credentials don't allow the
+	// operation.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// kinds lists the error kinds, in matching order.
+var kinds = []error{
+	ErrNotFound,
+	ErrAlreadyExists,
+	ErrConflict,
+	ErrInvalidArgument,
+	ErrUnavailable,
+	ErrTimeout,
+	ErrUnsupported,
+	ErrPermissionDenied,
+}
+
+// kindStatusCode is the HTTP status code of each error kind.
+var kindStatusCode = map[error]int{
+	ErrNotFound:         http.StatusNotFound,
+	ErrAlreadyExists:    http.StatusConflict,
+	ErrConflict:         http.StatusConflict,
+	ErrInvalidArgument:  http.StatusBadRequest,
+	ErrUnavailable:      http.StatusServiceUnavailable,
+	ErrTimeout:          http.StatusGatewayTimeout,
+	ErrUnsupported:      http.StatusNotImplemented,
+	ErrPermissionDenied: http.StatusForbidden,
+}
+
+// ErrorKindFunc maps a native error to one of the error kinds (e.g.:
+// `ErrNotFound`), or nil if it doesn't know it.
+type ErrorKindFunc func(err error) error
+
+// Error is a classified storage error. It matches both its `Kind`, and the
+// original error with `errors.Is`, and `errors.As`.
+type Error struct {
+	// Kind is one of the error kinds, e.g.: `ErrNotFound`, or nil if the
+	// error couldn't be classified.
+	Kind error `json:"-"`
+
+	// Storage is the storage name.
+	Storage string `json:"storage"`
+
+	// Operation which failed.
+	Operation Operation `json:"operation"`
+
+	// Target as given to the operation.
+	Target string `json:"target,omitempty"`
+
+	// ID of the document, if the operation addresses one.
+	ID string `json:"id,omitempty"`
+
+	// Err is the original error.
+	Err error `json:"-"`
+}
+
+// Error implements the error interface. The message is the original error's,
+// so wrapping doesn't change what's logged.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+
+	if e.Kind != nil {
+		return e.Kind.Error()
+	}
+
+	return ""
+}
+
+// Unwrap allows `errors.Is`, and `errors.As` to match both the kind, and the
+// original error.
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, 2)
+
+	for _, err := range []error{e.Kind, e.Err} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+//////
+// Helpers.
+//////
+
+// kindFromStatusCode maps the first meaningful HTTP status code in `err`'s
+// chain to an error kind. Generic 500s are skipped.
+func kindFromStatusCode(err error) error {
+	for err != nil {
+		var cE *customerror.CustomError
+		if !errors.As(err, &cE) {
+			return nil
+		}
+
+		switch cE.StatusCode {
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return ErrInvalidArgument
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrPermissionDenied
+		case http.StatusNotFound:
+			return ErrNotFound
+		case http.StatusConflict, http.StatusPreconditionFailed:
+			return ErrConflict
+		case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+			return ErrTimeout
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+			return ErrUnavailable
+		case http.StatusNotImplemented:
+			return ErrUnsupported
+		}
+
+		err = cE.Unwrap()
+	}
+
+	return nil
+}
+
+//////
+// Exported functionalities.
+//////
+
+// KindOf returns the error kind of `err` (e.g.: `ErrNotFound`) using the
+// storage-specific `kindOf` first, then the generic classification: kinds
+// already in the chain, context deadlines, `io/fs` errors, network timeouts,
+// transient errors, and HTTP status codes. Returns nil if unknown.
+func KindOf(err error, kindOf ErrorKindFunc) error {
+	if err == nil {
+		return nil
+	}
+
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	if kindOf != nil {
+		if kind := kindOf(err); kind != nil {
+			return kind
+		}
+	}
+
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrNotFound
+	case errors.Is(err, fs.ErrExist):
+		return ErrAlreadyExists
+	case errors.Is(err, fs.ErrPermission):
+		return ErrPermissionDenied
+	case errors.Is(err, errors.ErrUnsupported):
+		return ErrUnsupported
+	}
+
+	if kind := kindFromStatusCode(err); kind != nil {
+		return kind
+	}
+
+	if IsTransientError(err) {
+		return ErrUnavailable
+	}
+
+	return nil
+}
+
+// copyCustomError returns a copy of `cE`, fields included.
+func copyCustomError(cE *customerror.CustomError) *customerror.CustomError {
+	c := *cE
+
+	if cE.Fields != nil {
+		c.Fields = &sync.Map{}
+
+		cE.Fields.Range(func(k, v any) bool {
+			c.Fields.Store(k, v)
+
+			return true
+		})
+	}
+
+	return &c
+}
+
+// WrapError classifies `err` (see `KindOf`), and attaches the storage name,
+// operation, target, and id. It's called by every storage, at the end of
+// every operation.
+//
+// Custom errors (`customerror`) are kept at the top of the chain, so their
+// status code, and message are preserved: the classification is inserted
+// below, a generic status code is replaced by the kind's, and the context is
+// added as fields.
+func WrapError(name string, op Operation, target, id string, err error, kindOf ErrorKindFunc) error {
+	if err == nil {
+		return nil
+	}
+
+	// Already classified, e.g.: a storage wrapping another one.
+	var sErr *Error
+	if errors.As(err, &sErr) {
+		return err
+	}
+
+	kind := KindOf(err, kindOf)
+
+	original, ok := customerror.To(err)
+	if !ok {
+		return &Error{Kind: kind, Storage: name, Operation: op, Target: target, ID: id, Err: err}
+	}
+
+	// Wraps a copy, the original may be shared, e.g.: a sentinel.
+	cE := copyCustomError(original)
+
+	cE.Err = &Error{Kind: kind, Storage: name, Operation: op, Target: target, ID: id, Err: cE.Err}
+
+	if kind != nil && (cE.StatusCode == 0 || cE.StatusCode == http.StatusInternalServerError) {
+		cE.StatusCode = kindStatusCode[kind]
+	}
+
+	customerror.WithField("storage", name)(cE)
+	customerror.WithField("operation", op.String())(cE)
+
+	if target != "" {
+		customerror.WithField("target", target)(cE)
+	}
+
+	if id != "" {
+		customerror.WithField("id", id)(cE)
+	}
+
+	return cE
+}
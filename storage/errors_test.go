@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/customerror"
+)
+
+func TestKindOf(t *testing.T) {
+	errNative := errors.New("native")
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		kindOf ErrorKindFunc
+		want   error
+	}{
+		{"nil", nil, nil, nil},
+		{"unknown", errors.New("boom"), nil, nil},
+		{"backend first", errNative, func(err error) error { return ErrConflict }, ErrConflict},
+		{"already a kind", fmt.Errorf("wrapped: %w", ErrUnsupported), nil, ErrUnsupported},
+		{"deadline", context.DeadlineExceeded, nil, ErrTimeout},
+		{"fs not exist", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}, nil, ErrNotFound},
+		{"fs exist", fs.ErrExist, nil, ErrAlreadyExists},
+		{"fs permission", fs.ErrPermission, nil, ErrPermissionDenied},
+		{"404", customerror.NewNotFoundError("doc"), nil, ErrNotFound},
+		{"required", customerror.NewRequiredError("id"), nil, ErrInvalidArgument},
+		{"503 below a generic 500", customerror.NewFailedToError(
+			"retrieve",
+			customerror.WithError(customerror.NewHTTPError(http.StatusServiceUnavailable)),
+		), nil, ErrUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, KindOf(tc.err, tc.kindOf))
+		})
+	}
+}
+
+func TestWrapError_CustomError(t *testing.T) {
+	errNative := errors.New("duplicate key")
+
+	err := WrapError(
+		"pg", OperationCreate, "users", "1",
+		customerror.NewFailedToError("create", customerror.WithError(errNative)),
+		func(err error) error {
+			if errors.Is(err, errNative) {
+				return ErrAlreadyExists
+			}
+
+			return nil
+		},
+	)
+
+	// Matches the kind, and the original error.
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+	assert.ErrorIs(t, err, errNative)
+
+	// Still a custom error, with the kind's status code.
+	cE, ok := customerror.To(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, cE.StatusCode)
+	assert.Contains(t, err.Error(), "duplicate key")
+
+	var sErr *Error
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, "pg", sErr.Storage)
+	assert.Equal(t, OperationCreate, sErr.Operation)
+	assert.Equal(t, "users", sErr.Target)
+	assert.Equal(t, "1", sErr.ID)
+
+	// Wrapping is idempotent.
+	assert.Same(t, err, WrapError("other", OperationRetrieve, "", "", err, nil))
+}
+
+func TestWrapError_DoesNotMutateOriginal(t *testing.T) {
+	original, ok := customerror.To(customerror.NewFailedToError(
+		"retrieve",
+		customerror.WithError(fs.ErrNotExist),
+		customerror.WithField("k", "v"),
+	))
+	require.True(t, ok)
+
+	err := WrapError("pg", OperationRetrieve, "users", "1", original, nil)
+	assert.NotSame(t, original, err)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var sErr *Error
+	require.ErrorAs(t, err, &sErr)
+	assert.False(t, errors.As(original, &sErr))
+	assert.Equal(t, http.StatusInternalServerError, original.StatusCode)
+
+	_, ok = original.Fields.Load("storage")
+	assert.False(t, ok)
+
+	v, ok := original.Fields.Load("k")
+	require.True(t, ok)
+	assert.Equal(t, "v", v)
+}
+
+func TestWrapError_PlainError(t *testing.T) {
+	assert.NoError(t, WrapError("pg", OperationDelete, "t", "1", nil, nil))
+
+	err := WrapError("pg", OperationDelete, "t", "1", fs.ErrNotExist, nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Equal(t, fs.ErrNotExist.Error(), err.Error())
+
+	// Unknown errors still carry the context.
+	err = WrapError("pg", OperationDelete, "t", "1", errors.New("boom"), nil)
+
+	var sErr *Error
+	require.ErrorAs(t, err, &sErr)
+	assert.Nil(t, sErr.Kind)
+	assert.Equal(t, "pg", sErr.Storage)
+}
@@ -0,0 +1,661 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/customapm"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/count"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/list"
+	"github.com/thalesfsp/params/v2/retrieve"
+	"github.com/thalesfsp/params/v2/update"
+	"github.com/thalesfsp/status"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// FaultyName is the name of the faulty storage.
+const FaultyName = "faulty"
+
+// Distribution is a latency distribution.
+type Distribution string
+
+// Latency distributions.
+const (
+	// DistributionFixed is always `Mean`.
+	DistributionFixed Distribution = "fixed"
+
+	// DistributionUniform spans `Mean` ± `Spread`.
+	DistributionUniform Distribution = "uniform"
+
+	// DistributionNormal has a `Mean`, and a standard deviation of `Spread`.
+	DistributionNormal Distribution = "normal"
+
+	// DistributionExponential has a `Mean`: mostly fast, with a long tail.
+	DistributionExponential Distribution = "exponential"
+)
+
+// Latency is the latency added to operations.
+type Latency struct {
+	// Distribution of the latency. Default is `DistributionFixed`.
+	Distribution Distribution `json:"distribution,omitempty" validate:"omitempty,oneof=fixed uniform normal exponential"`
+
+	// Mean latency.
+	Mean time.Duration `json:"mean" validate:"gte=0"`
+
+	// Spread of uniform, and normal distributions.
+	Spread time.Duration `json:"spread,omitempty" validate:"gte=0"`
+
+	// Max caps the latency, if set.
+	Max time.Duration `json:"max,omitempty" validate:"gte=0"`
+}
+
+// Fault is what's injected into an operation. Latency comes first, then
+// either a timeout, or an error, if drawn.
+type Fault struct {
+	// Latency, if set, is added to the operation.
+	Latency *Latency `json:"latency,omitempty"`
+
+	// ErrorRate is the probability (0..1) of failing the operation.
+	ErrorRate float64 `json:"errorRate,omitempty" validate:"gte=0,lte=1"`
+
+	// ErrorKind is the kind of the errors, e.g.: `not found`. Default is
+	// `ErrUnavailable`. See `ErrNotFound`, etc.
+	ErrorKind string `json:"errorKind,omitempty"`
+
+	// TimeoutRate is the probability (0..1) of timing out the operation: it
+	// hangs for `Timeout` - until the context is done if zero -, then fails
+	// with `ErrTimeout`.
+	TimeoutRate float64 `json:"timeoutRate,omitempty" validate:"gte=0,lte=1"`
+
+	// Timeout is how long timed out operations hang.
+	Timeout time.Duration `json:"timeout,omitempty" validate:"gte=0"`
+}
+
+// Outage is a scheduled window during which operations fail.
+type Outage struct {
+	// Start of the outage.
+	Start time.Time `json:"start"`
+
+	// Duration of the outage.
+	Duration time.Duration `json:"duration" validate:"gt=0"`
+
+	// Operations failing. All if empty.
+	Operations []Operation `json:"operations,omitempty"`
+
+	// ErrorKind is the kind of the errors. Default is `ErrUnavailable`.
+	ErrorKind string `json:"errorKind,omitempty"`
+}
+
+// Faults are the faults of a Faulty storage. They can be changed at runtime
+// (`SetFaults`, `FaultyHandler`).
+type Faults struct {
+	// Enabled toggles every fault.
+	Enabled bool `json:"enabled"`
+
+	// Default is the fault of operations without one in `Operations`.
+	Default *Fault `json:"default,omitempty"`
+
+	// Operations is the fault of each operation.
+	Operations map[Operation]*Fault `json:"operations,omitempty"`
+
+	// Outages are the scheduled outages.
+	Outages []Outage `json:"outages,omitempty"`
+}
+
+// FaultyFunc allows to set faulty options.
+type FaultyFunc func(f *Faulty) error
+
+// Faulty is a storage which wraps another one (`Backend`), injecting faults
+// into its operations: latency, errors, timeouts, and scheduled outages. It's
+// meant for chaos, and resilience testing.
+//
+// Draws are deterministic when seeded (`WithFaultySeed`): the same sequence
+// of operations gets the same faults.
+type Faulty struct {
+	*Storage
+
+	// Backend is the wrapped storage.
+	Backend IStorage `json:"-" validate:"required"`
+
+	faults Faults
+	now    func() time.Time
+	random *rand.Rand
+	mu     sync.Mutex
+
+	// Metrics.
+	counterInjected *expvar.Int `json:"-" validate:"required,gte=0"`
+}
+
+// draw is the random outcome of a fault.
+type draw struct {
+	latency time.Duration
+	timeout bool
+	err     bool
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithFault injects `fault` into `operations`, or into every operation
+// without its own fault, if none.
+func WithFault(fault Fault, operations ...Operation) FaultyFunc {
+	return func(f *Faulty) error {
+		if len(operations) == 0 {
+			f.faults.Default = &fault
+
+			return nil
+		}
+
+		if f.faults.Operations == nil {
+			f.faults.Operations = map[Operation]*Fault{}
+		}
+
+		for _, op := range operations {
+			f.faults.Operations[op] = &fault
+		}
+
+		return nil
+	}
+}
+
+// WithOutage schedules an outage of `operations` - all if none -, from
+// `start`, for `duration`.
+func WithOutage(start time.Time, duration time.Duration, operations ...Operation) FaultyFunc {
+	return func(f *Faulty) error {
+		f.faults.Outages = append(f.faults.Outages, Outage{
+			Start:      start,
+			Duration:   duration,
+			Operations: operations,
+		})
+
+		return nil
+	}
+}
+
+// WithFaultySeed seeds the random draws, making them deterministic.
+func WithFaultySeed(seed uint64) FaultyFunc {
+	return func(f *Faulty) error {
+		f.random = rand.New(rand.NewPCG(seed, seed))
+
+		return nil
+	}
+}
+
+// WithFaultyClock sets the clock outages are scheduled against. Default is
+// `time.Now`.
+func WithFaultyClock(now func() time.Time) FaultyFunc {
+	return func(f *Faulty) error {
+		if now == nil {
+			return customerror.NewRequiredError("clock")
+		}
+
+		f.now = now
+
+		return nil
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// errorKind returns the error kind named `name`, `ErrUnavailable` if empty.
+func errorKind(name string) (error, error) {
+	if name == "" {
+		return ErrUnavailable, nil
+	}
+
+	for _, kind := range kinds {
+		if kind.Error() == name {
+			return kind, nil
+		}
+	}
+
+	return nil, customerror.NewInvalidError(fmt.Sprintf("error kind %q", name))
+}
+
+// validateFaults checks that `faults` can be injected.
+func validateFaults(faults *Faults) error {
+	all := []*Fault{faults.Default}
+
+	for _, fault := range faults.Operations {
+		all = append(all, fault)
+	}
+
+	for _, fault := range all {
+		if fault == nil {
+			continue
+		}
+
+		if err := validation.Validate(fault); err != nil {
+			return err
+		}
+
+		if fault.Latency != nil {
+			if err := validation.Validate(fault.Latency); err != nil {
+				return err
+			}
+		}
+
+		if _, err := errorKind(fault.ErrorKind); err != nil {
+			return err
+		}
+	}
+
+	for i := range faults.Outages {
+		if err := validation.Validate(&faults.Outages[i]); err != nil {
+			return err
+		}
+
+		if _, err := errorKind(faults.Outages[i].ErrorKind); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sample returns a latency drawn from `l`.
+func (l *Latency) sample(random *rand.Rand) time.Duration {
+	latency := float64(l.Mean)
+
+	switch l.Distribution {
+	case DistributionUniform:
+		latency += (random.Float64()*2 - 1) * float64(l.Spread)
+	case DistributionNormal:
+		latency += random.NormFloat64() * float64(l.Spread)
+	case DistributionExponential:
+		latency = random.ExpFloat64() * float64(l.Mean)
+	}
+
+	latency = math.Max(latency, 0)
+
+	if l.Max > 0 {
+		latency = math.Min(latency, float64(l.Max))
+	}
+
+	return time.Duration(latency)
+}
+
+// fault returns the outage, or fault of `op`, now, and draws its outcome.
+func (f *Faulty) fault(op Operation) (*Outage, *Fault, draw) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.faults.Enabled {
+		return nil, nil, draw{}
+	}
+
+	now := f.now()
+
+	for i, outage := range f.faults.Outages {
+		if (len(outage.Operations) == 0 || slices.Contains(outage.Operations, op)) &&
+			!now.Before(outage.Start) && now.Before(outage.Start.Add(outage.Duration)) {
+			return &f.faults.Outages[i], nil, draw{}
+		}
+	}
+
+	fault := f.faults.Operations[op]
+	if fault == nil {
+		fault = f.faults.Default
+	}
+
+	if fault == nil {
+		return nil, nil, draw{}
+	}
+
+	// Always draws the same numbers, so outcomes only depend on the seed, and
+	// the sequence of operations.
+	d := draw{
+		timeout: f.random.Float64() < fault.TimeoutRate,
+		err:     f.random.Float64() < fault.ErrorRate,
+	}
+
+	if fault.Latency != nil {
+		d.latency = fault.Latency.sample(f.random)
+	}
+
+	return nil, fault, d
+}
+
+// injected returns the injected error of `kind` for `op`.
+func (f *Faulty) injected(op Operation, kindName string) error {
+	kind, err := errorKind(kindName)
+	if err != nil {
+		return err
+	}
+
+	f.counterInjected.Add(1)
+
+	return customerror.NewFailedToError(
+		op.String()+", "+f.Backend.GetName()+", injected fault",
+		customerror.WithError(kind),
+		customerror.WithStatusCode(kindStatusCode[kind]),
+	)
+}
+
+// inject injects the fault of `op`, if any. Waits are cut short when `ctx` is
+// done, returning its error.
+func (f *Faulty) inject(ctx context.Context, op Operation) error {
+	outage, fault, d := f.fault(op)
+
+	if outage != nil {
+		return f.injected(op, outage.ErrorKind)
+	}
+
+	if fault == nil {
+		return nil
+	}
+
+	if d.latency > 0 {
+		if err := sleep(ctx, d.latency); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case d.timeout:
+		if fault.Timeout == 0 {
+			<-ctx.Done()
+
+			return ctx.Err()
+		}
+
+		if err := sleep(ctx, fault.Timeout); err != nil {
+			return err
+		}
+
+		return f.injected(op, ErrTimeout.Error())
+	case d.err:
+		return f.injected(op, fault.ErrorKind)
+	}
+
+	return nil
+}
+
+// sleep waits `d`, unless `ctx` is done first, returning its error.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//////
+// Implements the IStorage interface.
+//////
+
+// Count data.
+func (f *Faulty) Count(ctx context.Context, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
+	ctx, span := customapm.Trace(ctx, f.GetType(), FaultyName, status.Counted.String())
+	defer span.End()
+
+	if err := f.inject(ctx, OperationCount); err != nil {
+		return 0, customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterCountedFailed())
+	}
+
+	c, err := f.Backend.Count(ctx, target, prm, options...)
+	if err != nil {
+		return 0, customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterCountedFailed())
+	}
+
+	f.GetCounterCounted().Add(1)
+
+	return c, nil
+}
+
+// Delete data.
+func (f *Faulty) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+	ctx, span := customapm.Trace(ctx, f.GetType(), FaultyName, status.Deleted.String())
+	defer span.End()
+
+	if err := f.inject(ctx, OperationDelete); err != nil {
+		return customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterDeletedFailed())
+	}
+
+	if err := f.Backend.Delete(ctx, id, target, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterDeletedFailed())
+	}
+
+	f.GetCounterDeleted().Add(1)
+
+	return nil
+}
+
+// Retrieve data.
+func (f *Faulty) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+	ctx, span := customapm.Trace(ctx, f.GetType(), FaultyName, status.Retrieved.String())
+	defer span.End()
+
+	if err := f.inject(ctx, OperationRetrieve); err != nil {
+		return customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterRetrievedFailed())
+	}
+
+	if err := f.Backend.Retrieve(ctx, id, target, v, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterRetrievedFailed())
+	}
+
+	f.GetCounterRetrieved().Add(1)
+
+	return nil
+}
+
+// List data.
+func (f *Faulty) List(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error {
+	ctx, span := customapm.Trace(ctx, f.GetType(), FaultyName, status.Listed.String())
+	defer span.End()
+
+	if err := f.inject(ctx, OperationList); err != nil {
+		return customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterListedFailed())
+	}
+
+	if err := f.Backend.List(ctx, target, v, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterListedFailed())
+	}
+
+	f.GetCounterListed().Add(1)
+
+	return nil
+}
+
+// Create data.
+func (f *Faulty) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+	ctx, span := customapm.Trace(ctx, f.GetType(), FaultyName, status.Created.String())
+	defer span.End()
+
+	if err := f.inject(ctx, OperationCreate); err != nil {
+		return "", customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterCreatedFailed())
+	}
+
+	createdID, err := f.Backend.Create(ctx, id, target, v, prm, options...)
+	if err != nil {
+		return "", customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterCreatedFailed())
+	}
+
+	f.GetCounterCreated().Add(1)
+
+	return createdID, nil
+}
+
+// Update data.
+func (f *Faulty) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...Func[*update.Update]) error {
+	ctx, span := customapm.Trace(ctx, f.GetType(), FaultyName, status.Updated.String())
+	defer span.End()
+
+	if err := f.inject(ctx, OperationUpdate); err != nil {
+		return customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterUpdatedFailed())
+	}
+
+	if err := f.Backend.Update(ctx, id, target, v, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, f.GetLogger(), f.GetCounterUpdatedFailed())
+	}
+
+	f.GetCounterUpdated().Add(1)
+
+	return nil
+}
+
+// GetClient returns the backend's client.
+func (f *Faulty) GetClient() any {
+	return f.Backend.GetClient()
+}
+
+//////
+// Exported functionalities.
+//////
+
+// GetFaults returns the faults. Change them with SetFaults, not in place.
+func (f *Faulty) GetFaults() Faults {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	faults := f.faults
+
+	faults.Operations = maps.Clone(f.faults.Operations)
+	faults.Outages = slices.Clone(f.faults.Outages)
+
+	return faults
+}
+
+// SetFaults replaces the faults, if valid.
+func (f *Faulty) SetFaults(faults Faults) error {
+	if err := validateFaults(&faults); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = faults
+
+	return nil
+}
+
+// SetEnabled toggles every fault.
+func (f *Faulty) SetEnabled(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults.Enabled = enabled
+}
+
+// GetCounterInjected returns the metric of injected errors.
+func (f *Faulty) GetCounterInjected() *expvar.Int {
+	return f.counterInjected
+}
+
+// FaultyHandler allows to control the faults of `f` at runtime: `GET` serves
+// them as JSON, `PUT` replaces them with the JSON body, and `DELETE` disables
+// them.
+func FaultyHandler(f *Faulty) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var faults Faults
+
+			if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+
+			if err := f.SetFaults(faults); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+		case http.MethodDelete:
+			f.SetEnabled(false)
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := shared.Encode(w, f.GetFaults()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+//////
+// Factory.
+//////
+
+// NewFaulty wraps `backend`, injecting faults into its operations. Faults are
+// enabled, unseeded, and scheduled against `time.Now`, by default.
+func NewFaulty(ctx context.Context, backend IStorage, options ...FaultyFunc) (*Faulty, error) {
+	// Enforces IStorage interface implementation.
+	var _ IStorage = (*Faulty)(nil)
+
+	s, err := New(ctx, FaultyName)
+	if err != nil {
+		return nil, err
+	}
+
+	if backend == nil {
+		return nil, customapm.TraceError(
+			ctx,
+			customerror.NewRequiredError("backend storage"),
+			s.GetLogger(),
+			s.counterInstantiationFailed,
+		)
+	}
+
+	f := &Faulty{
+		Storage: s,
+
+		Backend: backend,
+
+		faults: Faults{Enabled: true},
+		now:    time.Now,
+		random: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+
+		counterInjected: metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, FaultyName, backend.GetName()+".injected", DefaultMetricCounterLabel)),
+	}
+
+	for _, option := range options {
+		if err := option(f); err != nil {
+			return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+		}
+	}
+
+	if err := validateFaults(&f.faults); err != nil {
+		return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+	}
+
+	if err := validation.Validate(f); err != nil {
+		return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/retrieve"
+)
+
+// newHealthyMock returns a storage named `name` whose Retrieve, and Delete
+// always succeed.
+func newHealthyMock(name string) *Mock {
+	return &Mock{
+		MockRetrieve: func(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+			return nil
+		},
+		MockDelete: func(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+			return nil
+		},
+		MockGetName: func() string { return name },
+	}
+}
+
+func TestFaulty_Deterministic(t *testing.T) {
+	outcomes := func() []bool {
+		f, err := NewFaulty(
+			t.Context(),
+			newHealthyMock("faultyd"),
+			WithFault(Fault{ErrorRate: 0.5, ErrorKind: ErrConflict.Error()}),
+			WithFaultySeed(42),
+		)
+		require.NoError(t, err)
+
+		failed := []bool{}
+
+		for range 50 {
+			err := f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil)
+			if err != nil {
+				require.ErrorIs(t, err, ErrConflict)
+			}
+
+			failed = append(failed, err != nil)
+		}
+
+		return failed
+	}
+
+	first := outcomes()
+
+	assert.Equal(t, first, outcomes(), "same seed, same faults")
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+}
+
+func TestFaulty_Operations(t *testing.T) {
+	f, err := NewFaulty(t.Context(), newHealthyMock("faultyo"), WithFault(Fault{ErrorRate: 1}, OperationDelete))
+	require.NoError(t, err)
+
+	before := f.GetCounterInjected().Value()
+
+	require.NoError(t, f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	require.ErrorIs(t, f.Delete(t.Context(), "1", "t", nil), ErrUnavailable)
+	assert.Equal(t, before+1, f.GetCounterInjected().Value())
+
+	f.SetEnabled(false)
+
+	require.NoError(t, f.Delete(t.Context(), "1", "t", nil))
+}
+
+func TestFaulty_LatencyAndTimeouts(t *testing.T) {
+	latency := &Latency{Distribution: DistributionUniform, Mean: 20 * time.Millisecond, Spread: 5 * time.Millisecond}
+
+	f, err := NewFaulty(t.Context(), newHealthyMock("faultyl"), WithFault(Fault{Latency: latency}))
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	require.NoError(t, f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+
+	// Timeouts fail with `ErrTimeout`...
+	require.NoError(t, f.SetFaults(Faults{Enabled: true, Default: &Fault{TimeoutRate: 1, Timeout: 5 * time.Millisecond}}))
+	require.ErrorIs(t, f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil), ErrTimeout)
+
+	// ...unless the context is done first.
+	require.NoError(t, f.SetFaults(Faults{Enabled: true, Default: &Fault{TimeoutRate: 1}}))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, f.Retrieve(ctx, "1", "t", &TestDataS{}, nil), context.DeadlineExceeded)
+}
+
+func TestFaulty_Outages(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	f, err := NewFaulty(
+		t.Context(),
+		newHealthyMock("faultyu"),
+		WithFaultyClock(func() time.Time { return now }),
+		WithOutage(now.Add(time.Minute), time.Minute, OperationRetrieve),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+
+	now = now.Add(90 * time.Second)
+
+	require.ErrorIs(t, f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil), ErrUnavailable)
+	require.NoError(t, f.Delete(t.Context(), "1", "t", nil), "other operations are up")
+
+	now = now.Add(time.Minute)
+
+	require.NoError(t, f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+}
+
+func TestFaultyHandler(t *testing.T) {
+	f, err := NewFaulty(t.Context(), newHealthyMock("faultyh"))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(FaultyHandler(f))
+	defer srv.Close()
+
+	do := func(method, body string) (*http.Response, Faults) {
+		req, err := http.NewRequestWithContext(t.Context(), method, srv.URL, strings.NewReader(body))
+		require.NoError(t, err)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer res.Body.Close()
+
+		var faults Faults
+
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&faults))
+		}
+
+		return res, faults
+	}
+
+	res, faults := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.True(t, faults.Enabled)
+	assert.Nil(t, faults.Default)
+
+	res, _ = do(http.MethodPut, `{"enabled":true,"default":{"errorRate":2}}`)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, _ = do(http.MethodPut, `{"enabled":true,"default":{"errorKind":"bogus"}}`)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, faults = do(http.MethodPut, `{"enabled":true,"operations":{"retrieve":{"errorRate":1,"errorKind":"not found"}}}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 1.0, faults.Operations[OperationRetrieve].ErrorRate)
+
+	require.ErrorIs(t, f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil), ErrNotFound)
+
+	res, faults = do(http.MethodDelete, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.False(t, faults.Enabled)
+
+	require.NoError(t, f.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+
+	res, _ = do(http.MethodPatch, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
@@ -55,7 +55,8 @@ type IStorage interface {
 	// List data.
 	List(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error
 
-	// Create data.
+	// Create data. It's insert-only: if the document exists, it fails with
+	// `ErrAlreadyExists`, unless `WithOverwrite` is set.
 	Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error)
 
 	// Update data.
@@ -114,10 +115,26 @@ type IStorage interface {
 	GetCounterUpdatedFailed() *expvar.Int
 }
 
+// IHealthChecker is implemented by storages which can report their health.
+type IHealthChecker interface {
+	// Health returns an error if the storage is unhealthy.
+	Health(ctx context.Context) error
+}
+
 //////
 // Generic functions.
 //////
 
+// Health returns `s`'s health. Storages which don't implement IHealthChecker
+// are considered healthy.
+func Health(ctx context.Context, s IStorage) error {
+	if h, ok := s.(IHealthChecker); ok {
+		return h.Health(ctx)
+	}
+
+	return nil
+}
+
 // Count data.
 func Count(ctx context.Context, s IStorage, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
 	return s.Count(ctx, target, prm, options...)
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"log/slog"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/sypl/v2"
+	"github.com/thalesfsp/sypl/v2/fields"
+	"github.com/thalesfsp/sypl/v2/level"
+	"github.com/thalesfsp/sypl/v2/message"
+	"github.com/thalesfsp/sypl/v2/output"
+	"github.com/thalesfsp/sypl/v2/processor"
+	"github.com/thalesfsp/sypl/v2/syplslog"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// SlogOutputName is the name of the output forwarding to a `*slog.Logger`.
+const SlogOutputName = "slog"
+
+// renamingOutput renames the fields of messages before writing them to the
+// wrapped output, which is left untouched, so it can be shared.
+type renamingOutput struct {
+	output.IOutput
+
+	rename processor.IProcessor
+}
+
+//////
+// Methods.
+//////
+
+// Write implements the output.IOutput interface.
+func (o *renamingOutput) Write(m message.IMessage) error {
+	if err := o.rename.Run(m); err != nil {
+		return err
+	}
+
+	return o.IOutput.Write(m)
+}
+
+//////
+// Helpers.
+//////
+
+// setupLogger sets the logger up from the logging options.
+func (s *Storage) setupLogger() error {
+	if len(s.logFieldNames) > 0 && !s.loggerInjected {
+		return customerror.NewInvalidError("log field names, requires `WithLogger`, or `WithSlog`")
+	}
+
+	if s.slogLogger != nil {
+		out := syplslog.Output(SlogOutputName, s.slogLogger.With(Type, s.Name), level.Trace)
+
+		if len(s.logFieldNames) > 0 {
+			out.AddProcessors(RenameLogFields(s.logFieldNames))
+		}
+
+		s.Logger = sypl.New(s.Name, out).SetTags(Type, s.Name)
+
+		return nil
+	}
+
+	if len(s.logFieldNames) > 0 {
+		outputs := []output.IOutput{}
+
+		for _, out := range s.Logger.GetOutputs() {
+			outputs = append(outputs, &renamingOutput{IOutput: out, rename: RenameLogFields(s.logFieldNames)})
+		}
+
+		// Replaces, by name, the child logger's outputs only.
+		s.Logger.SetOutputs(outputs...)
+	}
+
+	return nil
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithLogger sets the logger, so each storage can log to a different
+// destination. A child logger, tagged with the storage name, is used.
+func WithLogger(l sypl.ISypl) ConfigFunc {
+	return func(s *Storage) error {
+		if l == nil {
+			return customerror.NewRequiredError("logger")
+		}
+
+		s.Logger = l.New(s.Name).SetTags(Type, s.Name)
+		s.loggerInjected = true
+		s.slogLogger = nil
+
+		return nil
+	}
+}
+
+// WithSlog logs to `l`: messages are forwarded with their fields as attrs,
+// plus the `storage` attr. The slog handler decides which levels are logged.
+func WithSlog(l *slog.Logger) ConfigFunc {
+	return func(s *Storage) error {
+		if l == nil {
+			return customerror.NewRequiredError("slog logger")
+		}
+
+		s.slogLogger = l
+		s.loggerInjected = true
+
+		return nil
+	}
+}
+
+// WithLogFieldNames renames the fields logged, e.g.:
+// `{"trace.id": "trace_id"}`. It requires `WithLogger`, or `WithSlog`. The
+// injected logger, and its outputs, are left untouched.
+func WithLogFieldNames(names map[string]string) ConfigFunc {
+	return func(s *Storage) error {
+		if len(names) == 0 {
+			return customerror.NewRequiredError("log field names")
+		}
+
+		s.logFieldNames = names
+
+		return nil
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// RenameLogFields is a processor which renames message fields, `names` maps
+// the original to the new name.
+func RenameLogFields(names map[string]string) processor.IProcessor {
+	return processor.New("RenameLogFields", func(m message.IMessage) error {
+		f := make(fields.Fields, len(m.GetFields()))
+
+		for k, v := range m.GetFields() {
+			if to, ok := names[k]; ok {
+				k = to
+			}
+
+			f[k] = v
+		}
+
+		m.SetFields(f)
+
+		return nil
+	})
+}
+
+// NewSlogHandler returns a `slog.Handler` logging through `l`, e.g.: a
+// storage's `GetLogger()`, so code using the slog API logs to the same
+// destination.
+func NewSlogHandler(l sypl.ISypl, options ...syplslog.HandlerOption) slog.Handler {
+	return syplslog.NewHandler(l.New(l.GetName()), options...)
+}
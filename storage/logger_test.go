@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/sypl/v2"
+	"github.com/thalesfsp/sypl/v2/fields"
+	"github.com/thalesfsp/sypl/v2/formatter"
+	"github.com/thalesfsp/sypl/v2/level"
+	"github.com/thalesfsp/sypl/v2/output"
+)
+
+func TestNew_WithSlog(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	s, err := New(t.Context(), "slogtest", WithSlog(l), WithLogFieldNames(map[string]string{"target": "tgt"}))
+	require.NoError(t, err)
+
+	s.GetLogger().PrintlnWithOptions(level.Warn, "hello", sypl.WithFields(fields.Fields{"target": "users"}))
+
+	var logged map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &logged))
+
+	assert.Equal(t, "WARN", logged["level"])
+	assert.Equal(t, "hello", logged["msg"])
+	assert.Equal(t, "slogtest", logged[Type])
+	assert.Equal(t, "users", logged["tgt"])
+	assert.NotContains(t, logged, "target")
+}
+
+func TestNew_WithLogger(t *testing.T) {
+	buf, out := output.SafeBuffer(level.Trace)
+	out.SetFormatter(formatter.JSON())
+
+	s, err := New(t.Context(), "loggertest", WithLogger(sypl.New("test", out)))
+	require.NoError(t, err)
+
+	s.GetLogger().PrintlnWithOptions(level.Info, "hello", sypl.WithFields(fields.Fields{"target": "users"}))
+
+	assert.Contains(t, buf.String(), `"message":"hello"`)
+	assert.Contains(t, buf.String(), `"target":"users"`)
+}
+
+// Renaming doesn't leak to the injected, possibly shared, logger.
+func TestNew_WithLogFieldNames(t *testing.T) {
+	buf, out := output.SafeBuffer(level.Trace)
+	out.SetFormatter(formatter.JSON())
+
+	shared := sypl.New("test", out)
+
+	s, err := New(
+		t.Context(),
+		"loggertest",
+		WithLogger(shared),
+		WithLogFieldNames(map[string]string{"target": "tgt"}),
+	)
+	require.NoError(t, err)
+
+	s.GetLogger().PrintlnWithOptions(level.Info, "renamed", sypl.WithFields(fields.Fields{"target": "users"}))
+
+	assert.Contains(t, buf.String(), `"tgt":"users"`)
+	assert.NotContains(t, buf.String(), `"target"`)
+
+	buf.Reset()
+
+	shared.PrintlnWithOptions(level.Info, "untouched", sypl.WithFields(fields.Fields{"target": "users"}))
+
+	assert.Contains(t, buf.String(), `"target":"users"`)
+	assert.Empty(t, out.GetProcessors())
+}
+
+func TestNew_InvalidLoggerOptions(t *testing.T) {
+	_, err := New(t.Context(), "loggertest", WithLogFieldNames(map[string]string{"target": "tgt"}))
+	require.Error(t, err)
+
+	_, err = New(t.Context(), "loggertest", WithLogger(nil))
+	require.Error(t, err)
+
+	_, err = New(t.Context(), "loggertest", WithSlog(nil))
+	require.Error(t, err)
+}
+
+func TestNewSlogHandler(t *testing.T) {
+	buf, out := output.SafeBuffer(level.Trace)
+	out.SetFormatter(formatter.JSON())
+
+	l := slog.New(NewSlogHandler(sypl.New("test", out)))
+	l.Info("hello", "target", "users")
+
+	var logged map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &logged))
+
+	assert.Equal(t, "hello", logged["message"])
+	assert.Equal(t, "users", logged["target"])
+}
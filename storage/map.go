@@ -122,7 +122,8 @@ func DeleteFromMany(
 
 // ListFromMany lists documents concurrently against all DALs in the map.
 //
-// NOTE: The results are flattened into a single slice.
+// NOTE: The results are flattened into a single slice. Use ListFromManyMerged
+// for de-duplicated, sorted results.
 func ListFromMany[T any](
 	ctx context.Context,
 	m Map,
@@ -190,6 +191,8 @@ func UpdateIntoMany(
 //////
 
 // CreateMany creates many documents concurrently against the same DAL.
+//
+// NOTE: Use CreateManyWith to bound concurrency, and rate.
 func CreateMany[T any](
 	ctx context.Context,
 	str IStorage,
@@ -197,23 +200,12 @@ func CreateMany[T any](
 	prm *create.Create,
 	itemsMap map[string]T,
 ) ([]string, error) {
-	r, errs := concurrentloop.MapM(ctx, itemsMap, func(ctx context.Context, key string, item T) (string, error) {
-		id, err := Create(ctx, str, key, target, item, prm)
-		if err != nil {
-			return "", err
-		}
-
-		return id, nil
-	}, concurrentloop.WithRemoveZeroValues(false))
-
-	if len(errs) > 0 {
-		return nil, errs
-	}
-
-	return r, nil
+	return CreateManyWith(ctx, nil, str, target, prm, itemsMap)
 }
 
 // DeleteMany deletes many documents concurrently against the same DAL.
+//
+// NOTE: Use DeleteManyWith to bound concurrency, and rate.
 func DeleteMany(
 	ctx context.Context,
 	str IStorage,
@@ -221,22 +213,12 @@ func DeleteMany(
 	prm *delete.Delete,
 	ids ...string,
 ) ([]bool, error) {
-	r, errs := concurrentloop.Map(ctx, ids, func(ctx context.Context, id string) (bool, error) {
-		if err := Delete(ctx, str, id, target, prm); err != nil {
-			return false, err
-		}
-
-		return true, nil
-	}, concurrentloop.WithRemoveZeroValues(false))
-
-	if len(errs) > 0 {
-		return nil, errs
-	}
-
-	return r, nil
+	return DeleteManyWith(ctx, nil, str, target, prm, ids...)
 }
 
 // RetrieveMany retrieves many documents concurrently against the same DAL.
+//
+// NOTE: Use RetrieveManyWith to bound concurrency, and rate.
 func RetrieveMany[T any](
 	ctx context.Context,
 	str IStorage,
@@ -244,18 +226,12 @@ func RetrieveMany[T any](
 	prm *retrieve.Retrieve,
 	ids ...string,
 ) ([]T, error) {
-	r, errs := concurrentloop.Map(ctx, ids, func(ctx context.Context, id string) (T, error) {
-		return Retrieve[T](ctx, str, id, target, prm)
-	}, concurrentloop.WithRemoveZeroValues(false))
-
-	if len(errs) > 0 {
-		return nil, errs
-	}
-
-	return r, nil
+	return RetrieveManyWith[T](ctx, nil, str, target, prm, ids...)
 }
 
 // UpdateMany updates many documents concurrently against the same DAL.
+//
+// NOTE: Use UpdateManyWith to bound concurrency, and rate.
 func UpdateMany[T any](
 	ctx context.Context,
 	str IStorage,
@@ -263,17 +239,5 @@ func UpdateMany[T any](
 	prm *update.Update,
 	itemsMap map[string]T,
 ) ([]bool, error) {
-	r, errs := concurrentloop.MapM(ctx, itemsMap, func(ctx context.Context, key string, item T) (bool, error) {
-		if err := Update(ctx, str, key, target, item, prm); err != nil {
-			return false, err
-		}
-
-		return true, nil
-	}, concurrentloop.WithRemoveZeroValues(false))
-
-	if len(errs) > 0 {
-		return nil, errs
-	}
-
-	return r, nil
+	return UpdateManyWith(ctx, nil, str, target, prm, itemsMap)
 }
@@ -0,0 +1,429 @@
+package storage
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/concurrentloop"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/params/v2/customsort"
+	"github.com/thalesfsp/params/v2/list"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// DefaultIDTag is the struct tag value (`dal:"id"`) which marks the field
+	// used to identify a document.
+	DefaultIDTag = "id"
+
+	// DefaultUpdatedAtField is the field used by `ConflictPolicyNewest`.
+	DefaultUpdatedAtField = "updated_at"
+)
+
+// ConflictPolicy defines how duplicated documents - same ID, different
+// storages - are resolved.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyPriority keeps the document from the storage which comes
+	// first in `MergeOptions.Priority`.
+	ConflictPolicyPriority ConflictPolicy = "priority"
+
+	// ConflictPolicyNewest keeps the document with the greatest
+	// `MergeOptions.UpdatedAtField`. Ties fall back to priority.
+	ConflictPolicyNewest ConflictPolicy = "newest"
+)
+
+// KeyFunc extracts the ID of a document.
+type KeyFunc[T any] func(item T) (string, error)
+
+// MergeOptions controls how `ListFromManyMerged` merges results.
+type MergeOptions[T any] struct {
+	// KeyFunc extracts the ID used for de-duplication. If not set, the field
+	// tagged with `dal:"id"` is used, falling back to the `id` JSON field.
+	KeyFunc KeyFunc[T]
+
+	// Policy resolves conflicts. Default is `ConflictPolicyPriority`.
+	Policy ConflictPolicy
+
+	// Priority lists storage names, highest priority first. Storages not
+	// listed come after, ordered by name.
+	Priority []string
+
+	// UpdatedAtField is the field compared by `ConflictPolicyNewest`. Default
+	// is `DefaultUpdatedAtField`.
+	UpdatedAtField string
+}
+
+// mergeEntry is a listed document alongside its normalized form.
+type mergeEntry[T any] struct {
+	item   T
+	doc    any
+	key    string
+	source int
+}
+
+// mergeCursor points to the next entry of a storage's result.
+type mergeCursor[T any] struct {
+	entries []*mergeEntry[T]
+	pos     int
+}
+
+// mergeHeap is a min-heap of cursors ordered by the current entry.
+type mergeHeap[T any] struct {
+	cursors []*mergeCursor[T]
+	sort    customsort.SortSlice
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.cursors) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a := h.cursors[i].entries[h.cursors[i].pos]
+	b := h.cursors[j].entries[h.cursors[j].pos]
+
+	if c := compareDocs(a.doc, b.doc, h.sort); c != 0 {
+		return c < 0
+	}
+
+	// Stable across storages: higher priority first.
+	return a.source < b.source
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.cursors = append(h.cursors, x.(*mergeCursor[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	old := h.cursors
+	n := len(old)
+	x := old[n-1]
+	h.cursors = old[:n-1]
+
+	return x
+}
+
+//////
+// Helpers.
+//////
+
+// lookupField returns the value at `path` (dot-separated) in `doc`.
+func lookupField(doc any, path string) any {
+	for _, part := range strings.Split(path, ".") {
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		doc = m[part]
+	}
+
+	return doc
+}
+
+// compareValues compares two JSON-decoded values. Missing values sort first.
+// Strings which are both RFC3339 timestamps are compared as time.
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			return cmp.Compare(av, bv)
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			at, aErr := time.Parse(time.RFC3339Nano, av)
+			bt, bErr := time.Parse(time.RFC3339Nano, bv)
+
+			if aErr == nil && bErr == nil {
+				return at.Compare(bt)
+			}
+
+			return strings.Compare(av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// compareDocs compares two documents according to `sort`.
+func compareDocs(a, b any, sort customsort.SortSlice) int {
+	for _, s := range sort {
+		if len(s) == 0 {
+			continue
+		}
+
+		c := compareValues(lookupField(a, s[0]), lookupField(b, s[0]))
+
+		if len(s) > 1 && strings.EqualFold(s[1], customsort.Desc) {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// keyFromTag returns the value of the field tagged with `dal:"id"`, if any.
+func keyFromTag(item any) (string, bool) {
+	v := reflect.ValueOf(item)
+
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	for i := range v.NumField() {
+		if v.Type().Field(i).Tag.Get("dal") == DefaultIDTag {
+			return fmt.Sprint(v.Field(i).Interface()), true
+		}
+	}
+
+	return "", false
+}
+
+// priorityOrder returns the map's storage names ordered by `priority`, then
+// by name.
+func priorityOrder(m Map, priority []string) []string {
+	names := make([]string, 0, len(m))
+
+	for name := range m {
+		names = append(names, name)
+	}
+
+	rank := func(name string) int {
+		if i := slices.Index(priority, name); i >= 0 {
+			return i
+		}
+
+		return len(priority)
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return names
+}
+
+// wins reports whether `challenger` should replace `current`.
+func wins[T any](challenger, current *mergeEntry[T], o *MergeOptions[T]) bool {
+	if o.Policy == ConflictPolicyNewest {
+		c := compareValues(
+			lookupField(challenger.doc, o.UpdatedAtField),
+			lookupField(current.doc, o.UpdatedAtField),
+		)
+
+		if c != 0 {
+			return c > 0
+		}
+	}
+
+	return challenger.source < current.source
+}
+
+//////
+// 1:N Operations.
+//////
+
+// ListFromManyMerged lists documents concurrently against all DALs in the map,
+// like ListFromMany, but:
+//
+// - Documents with the same ID are de-duplicated, conflicts are resolved
+// according to the `mo.Policy`.
+// - Results are k-way merged according to `prm.Sort`. Each storage is
+// expected to return its documents already sorted.
+// - `prm.Limit`, and `prm.Offset` are applied globally, after the merge.
+//
+// NOTE: Sort fields are looked up in the JSON representation of the documents.
+// Nested fields are supported using dot notation.
+func ListFromManyMerged[T any](
+	ctx context.Context,
+	m Map,
+	target string,
+	prm *list.List,
+	mo *MergeOptions[T],
+	options ...Func[*list.List],
+) ([]T, error) {
+	// Copy mo so defaulting never mutates the caller-owned options.
+	o := &MergeOptions[T]{}
+	if mo != nil {
+		moCopy := *mo
+		o = &moCopy
+	}
+
+	if o.Policy == "" {
+		o.Policy = ConflictPolicyPriority
+	}
+
+	if o.UpdatedAtField == "" {
+		o.UpdatedAtField = DefaultUpdatedAtField
+	}
+
+	if o.Policy != ConflictPolicyPriority && o.Policy != ConflictPolicyNewest {
+		return nil, customerror.NewInvalidError("conflict policy " + string(o.Policy))
+	}
+
+	// Each storage must return enough documents to fill the global page.
+	var (
+		perStoragePrm *list.List
+		offset, limit int
+	)
+
+	if prm != nil {
+		prmCopy := *prm
+		offset, limit = prm.Offset, prm.Limit
+
+		prmCopy.Offset = 0
+
+		if limit > 0 {
+			prmCopy.Limit = offset + limit
+		}
+
+		perStoragePrm = &prmCopy
+	}
+
+	names := priorityOrder(m, o.Priority)
+
+	r, errs := concurrentloop.Map(ctx, names, func(ctx context.Context, name string) ([]T, error) {
+		return List[[]T](ctx, m[name], target, perStoragePrm, options...)
+	}, concurrentloop.WithRemoveZeroValues(false))
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	//////
+	// De-duplication.
+	//////
+
+	winners := map[string]*mergeEntry[T]{}
+	cursors := make([]*mergeCursor[T], len(r))
+
+	for source, items := range r {
+		cursors[source] = &mergeCursor[T]{}
+
+		for _, item := range items {
+			e := &mergeEntry[T]{item: item, source: source}
+
+			if err := ParseToStruct(item, &e.doc); err != nil {
+				return nil, err
+			}
+
+			if o.KeyFunc != nil {
+				k, err := o.KeyFunc(item)
+				if err != nil {
+					return nil, err
+				}
+
+				e.key = k
+			} else if k, ok := keyFromTag(item); ok {
+				e.key = k
+			} else if id := lookupField(e.doc, DefaultIDTag); id != nil {
+				e.key = fmt.Sprint(id)
+			}
+
+			cursors[source].entries = append(cursors[source].entries, e)
+
+			// Documents without an ID can't be de-duplicated.
+			if e.key == "" {
+				continue
+			}
+
+			if current, ok := winners[e.key]; !ok || wins(e, current, o) {
+				winners[e.key] = e
+			}
+		}
+	}
+
+	//////
+	// K-way merge.
+	//////
+
+	h := &mergeHeap[T]{}
+
+	if prm != nil {
+		h.sort = prm.Sort
+	}
+
+	for _, c := range cursors {
+		c.entries = slices.DeleteFunc(c.entries, func(e *mergeEntry[T]) bool {
+			return e.key != "" && winners[e.key] != e
+		})
+
+		if len(c.entries) > 0 {
+			h.cursors = append(h.cursors, c)
+		}
+	}
+
+	heap.Init(h)
+
+	result := []T{}
+	skipped := 0
+
+	for h.Len() > 0 {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+
+		c := h.cursors[0]
+		e := c.entries[c.pos]
+
+		if skipped < offset {
+			skipped++
+		} else {
+			result = append(result, e.item)
+		}
+
+		c.pos++
+
+		if c.pos < len(c.entries) {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+
+	return result, nil
+}
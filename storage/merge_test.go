@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/customsort"
+	"github.com/thalesfsp/params/v2/list"
+)
+
+// mergeTestData is a document identified through the `dal:"id"` tag.
+type mergeTestData struct {
+	ID        string `dal:"id"         json:"id"`
+	Score     int    `json:"score"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// newListMock builds a Mock whose List returns `result` (a JSON array),
+// recording the params it was called with.
+func newListMock(name, result string, got **list.List) *Mock {
+	return &Mock{
+		MockList: func(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error {
+			if got != nil {
+				*got = prm
+			}
+
+			return shared.Unmarshal([]byte(result), v)
+		},
+		MockGetName: func() string { return name },
+	}
+}
+
+func ids(items []mergeTestData) []string {
+	r := make([]string, 0, len(items))
+
+	for _, i := range items {
+		r = append(r, i.ID)
+	}
+
+	return r
+}
+
+func TestListFromManyMerged_SortAndPriority(t *testing.T) {
+	m := Map{
+		"a": newListMock("a", `[
+			{"id":"1","score":1,"updated_at":"2026-01-01T00:00:00Z"},
+			{"id":"3","score":3,"updated_at":"2026-01-01T00:00:00Z"},
+			{"id":"5","score":5,"updated_at":"2026-01-01T00:00:00Z"}
+		]`, nil),
+		"b": newListMock("b", `[
+			{"id":"2","score":2,"updated_at":"2026-01-01T00:00:00Z"},
+			{"id":"3","score":4,"updated_at":"2026-02-01T00:00:00Z"},
+			{"id":"6","score":6,"updated_at":"2026-01-01T00:00:00Z"}
+		]`, nil),
+	}
+
+	prm := &list.List{Sort: customsort.SortSlice{{"score", customsort.Asc}}}
+
+	// Priority: "a" wins the conflict on id 3.
+	got, err := ListFromManyMerged(t.Context(), m, "t", prm, &MergeOptions[mergeTestData]{
+		Priority: []string{"a", "b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "5", "6"}, ids(got))
+	assert.Equal(t, 3, got[2].Score)
+
+	// Priority reversed: "b" wins, and its copy sorts after 2 (score 4).
+	got, err = ListFromManyMerged(t.Context(), m, "t", prm, &MergeOptions[mergeTestData]{
+		Priority: []string{"b", "a"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "5", "6"}, ids(got))
+	assert.Equal(t, 4, got[2].Score)
+
+	// Newest: "b" has the most recent updated_at for id 3.
+	got, err = ListFromManyMerged(t.Context(), m, "t", prm, &MergeOptions[mergeTestData]{
+		Policy:   ConflictPolicyNewest,
+		Priority: []string{"a", "b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, got[2].Score)
+
+	// Descending: storages return their documents sorted accordingly.
+	desc := Map{
+		"a": newListMock("a", `[{"id":"5","score":5},{"id":"1","score":1}]`, nil),
+		"b": newListMock("b", `[{"id":"6","score":6},{"id":"2","score":2}]`, nil),
+	}
+
+	got, err = ListFromManyMerged[mergeTestData](t.Context(), desc, "t", &list.List{
+		Sort: customsort.SortSlice{{"score", customsort.Desc}},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"6", "5", "2", "1"}, ids(got))
+}
+
+func TestListFromManyMerged_GlobalLimitOffset(t *testing.T) {
+	var gotA, gotB *list.List
+
+	m := Map{
+		"a": newListMock("a", `[{"id":"1","score":1},{"id":"3","score":3},{"id":"5","score":5}]`, &gotA),
+		"b": newListMock("b", `[{"id":"2","score":2},{"id":"4","score":4},{"id":"6","score":6}]`, &gotB),
+	}
+
+	prm := &list.List{
+		Limit:  2,
+		Offset: 1,
+		Sort:   customsort.SortSlice{{"score", customsort.Asc}},
+	}
+
+	got, err := ListFromManyMerged[mergeTestData](t.Context(), m, "t", prm, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, ids(got))
+
+	// Every storage is asked for offset+limit documents from the start, and the
+	// caller's params are left untouched.
+	assert.Equal(t, 0, gotA.Offset)
+	assert.Equal(t, 3, gotA.Limit)
+	assert.Equal(t, 3, gotB.Limit)
+	assert.Equal(t, 1, prm.Offset)
+	assert.Equal(t, 2, prm.Limit)
+}
+
+func TestListFromManyMerged_KeyFuncAndInvalidPolicy(t *testing.T) {
+	m := Map{
+		"a": newListMock("a", `["x", "y"]`, nil),
+		"b": newListMock("b", `["y", "z"]`, nil),
+	}
+
+	got, err := ListFromManyMerged(t.Context(), m, "t", nil, &MergeOptions[string]{
+		KeyFunc: func(item string) (string, error) { return item, nil },
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"x", "y", "z"}, got)
+
+	_, err = ListFromManyMerged(t.Context(), m, "t", nil, &MergeOptions[string]{Policy: "oldest"})
+	assert.Error(t, err)
+}
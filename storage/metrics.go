@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/thalesfsp/dal/v2/internal/customapm"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// Outcome of an operation.
+type Outcome string
+
+const (
+	// OutcomeSuccess is the outcome of an operation which succeeded.
+	OutcomeSuccess Outcome = "success"
+
+	// OutcomeFailed is the outcome of an operation which failed.
+	OutcomeFailed Outcome = "failed"
+)
+
+// OperationMetric is the measurement of a finished operation.
+type OperationMetric struct {
+	// Storage is the storage name.
+	Storage string `json:"storage"`
+
+	// Operation which ran.
+	Operation Operation `json:"operation"`
+
+	// Target as given to the operation.
+	Target string `json:"target,omitempty"`
+
+	// Outcome of the operation.
+	Outcome Outcome `json:"outcome"`
+
+	// Duration of the operation.
+	Duration time.Duration `json:"duration"`
+
+	// Payload is the document written (`Create`, `Update`), or read
+	// (`Retrieve`, `List`). Nil for the other operations, and failures.
+	Payload any `json:"-"`
+}
+
+// IMetrics is a metrics sink, e.g.: Prometheus. Sinks are additional: the
+// expvar counters (`GetCounter*`) are always updated, and remain the default.
+type IMetrics interface {
+	// ObserveOperation is called at the end of every storage operation.
+	ObserveOperation(ctx context.Context, m *OperationMetric)
+}
+
+// Observation measures an operation. See `Observe`.
+type Observation struct {
+	ctx       context.Context
+	storage   IStorage
+	name      string
+	operation Operation
+	target    string
+	id        string
+	start     time.Time
+
+	// record is what the span of the operation learned, e.g.: the statement.
+	record *customapm.Operation
+}
+
+// metricsSinks are the registered metrics sinks.
+var metricsSinks atomic.Pointer[[]IMetrics]
+
+//////
+// Methods.
+//////
+
+// PayloadBytes returns the size of the payload: the length of bytes, strings,
+// and files, otherwise the length of its JSON encoding. Zero if there's no
+// payload, or it can't be measured.
+func (m *OperationMetric) PayloadBytes() int {
+	switch p := m.Payload.(type) {
+	case nil:
+		return 0
+	case []byte:
+		return len(p)
+	case string:
+		return len(p)
+	case *os.File:
+		info, err := p.Stat()
+		if err != nil {
+			return 0
+		}
+
+		return int(info.Size())
+	}
+
+	b, err := shared.Marshal(m.Payload)
+	if err != nil {
+		return 0
+	}
+
+	return len(b)
+}
+
+// Done ends the observation: `err` is classified (see `WrapError`), the
+// operation is logged if slow (see `SetSlowThreshold`), successful mutations
+// are emitted (see `WithEmitter`), and it's reported to the metrics sinks.
+// `payload` is the document written, or read, if any. Returns the classified
+// error.
+func (o Observation) Done(payload any, err error, kindOf ErrorKindFunc) error {
+	duration := time.Since(o.start)
+
+	err = WrapError(o.name, o.operation, o.target, o.id, err, kindOf)
+
+	o.logSlowOperation(duration, err)
+
+	if err == nil {
+		o.emitMutation(payload)
+	}
+
+	sinks := metricsSinks.Load()
+	if sinks == nil {
+		return err
+	}
+
+	m := &OperationMetric{
+		Storage:   o.name,
+		Operation: o.operation,
+		Target:    o.target,
+		Outcome:   OutcomeSuccess,
+		Duration:  duration,
+		Payload:   payload,
+	}
+
+	if err != nil {
+		m.Outcome = OutcomeFailed
+		m.Payload = nil
+	}
+
+	for _, sink := range *sinks {
+		sink.ObserveOperation(o.ctx, m)
+	}
+
+	return err
+}
+
+//////
+// Exported functionalities.
+//////
+
+// SetMetrics sets the metrics sinks every storage reports to, replacing the
+// previous ones. Call it without sinks to remove them.
+func SetMetrics(sinks ...IMetrics) {
+	if len(sinks) == 0 {
+		metricsSinks.Store(nil)
+
+		return
+	}
+
+	metricsSinks.Store(&sinks)
+}
+
+// Observe starts measuring an operation of `s`. Every storage calls it at the
+// start of every operation, and `Observation.Done` at the end. The operation
+// must run with the returned context, which collects what its span learns,
+// e.g.:
+//
+//	ctx, o := storage.Observe(ctx, p, storage.OperationRetrieve, target, id)
+//
+//	return o.Done(v, p.retrieve(ctx, id, target, v, prm, options...), ErrorKind)
+func Observe(ctx context.Context, s IStorage, op Operation, target, id string) (context.Context, Observation) {
+	ctx, record := customapm.WithOperation(ctx)
+
+	return ctx, Observation{
+		ctx:       ctx,
+		storage:   s,
+		name:      s.GetName(),
+		operation: op,
+		target:    target,
+		id:        id,
+		start:     time.Now(),
+		record:    record,
+	}
+}
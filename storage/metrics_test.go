@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetrics is a metrics sink which records the observations.
+type recordingMetrics struct {
+	mu      sync.Mutex
+	metrics []OperationMetric
+}
+
+func (r *recordingMetrics) ObserveOperation(_ context.Context, m *OperationMetric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics = append(r.metrics, *m)
+}
+
+// newNamedMock returns a storage named `name`.
+func newNamedMock(name string) *Mock {
+	return &Mock{MockGetName: func() string { return name }}
+}
+
+func TestObserve(t *testing.T) {
+	sink := &recordingMetrics{}
+
+	SetMetrics(sink)
+	t.Cleanup(func() { SetMetrics() })
+
+	doc := &TestDataS{K: "v"}
+
+	mem := newNamedMock("mem")
+
+	_, o := Observe(t.Context(), mem, OperationCreate, "users", "1")
+	require.NoError(t, o.Done(doc, nil, nil))
+
+	_, o = Observe(t.Context(), mem, OperationRetrieve, "users", "2")
+	err := o.Done(doc, ErrNotFound, nil)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// Errors are classified, as with WrapError.
+	var sErr *Error
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, "2", sErr.ID)
+
+	require.Len(t, sink.metrics, 2)
+
+	assert.Equal(t, "mem", sink.metrics[0].Storage)
+	assert.Equal(t, OperationCreate, sink.metrics[0].Operation)
+	assert.Equal(t, "users", sink.metrics[0].Target)
+	assert.Equal(t, OutcomeSuccess, sink.metrics[0].Outcome)
+	assert.Equal(t, len(`{"k":"v"}`), sink.metrics[0].PayloadBytes())
+	assert.Positive(t, sink.metrics[0].Duration)
+
+	// Failures carry no payload.
+	assert.Equal(t, OutcomeFailed, sink.metrics[1].Outcome)
+	assert.Zero(t, sink.metrics[1].PayloadBytes())
+
+	// Without sinks, only the error is classified.
+	SetMetrics()
+
+	_, o = Observe(t.Context(), mem, OperationDelete, "", "")
+
+	err = o.Done(nil, errors.New("boom"), nil)
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, OperationDelete, sErr.Operation)
+	assert.Len(t, sink.metrics, 2)
+}
+
+func TestOperationMetric_PayloadBytes(t *testing.T) {
+	assert.Equal(t, 3, (&OperationMetric{Payload: []byte("abc")}).PayloadBytes())
+	assert.Equal(t, 2, (&OperationMetric{Payload: "ab"}).PayloadBytes())
+	assert.Equal(t, 0, (&OperationMetric{Payload: make(chan int)}).PayloadBytes())
+}
@@ -94,6 +94,12 @@ type Mock struct {
 
 	// GetCounterUpdatedFailed returns the metric.
 	MockGetCounterUpdatedFailed func() *expvar.Int
+
+	// GetCounterInstantiationFailed returns the metric.
+	MockGetCounterInstantiationFailed func() *expvar.Int
+
+	// GetCounterSlow returns the metric.
+	MockGetCounterSlow func() *expvar.Int
 }
 
 //////
@@ -308,3 +314,21 @@ func (m *Mock) GetCounterUpdatedFailed() *expvar.Int {
 
 	return m.MockGetCounterUpdatedFailed()
 }
+
+// GetCounterInstantiationFailed returns the metric.
+func (m *Mock) GetCounterInstantiationFailed() *expvar.Int {
+	if m.MockGetCounterInstantiationFailed == nil {
+		return nil
+	}
+
+	return m.MockGetCounterInstantiationFailed()
+}
+
+// GetCounterSlow returns the metric.
+func (m *Mock) GetCounterSlow() *expvar.Int {
+	if m.MockGetCounterSlow == nil {
+		return nil
+	}
+
+	return m.MockGetCounterSlow()
+}
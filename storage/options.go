@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/params/v2/create"
 	"github.com/thalesfsp/validation"
 )
 
@@ -38,6 +39,14 @@ type Options[T any] struct {
 
 	// PostHookFunc is the function which runs after the operation.
 	PostHookFunc HookFunc[T] `json:"-"`
+
+	// Overwrite allows `Create` to replace an existing document. By default,
+	// `Create` is insert-only, and fails with `ErrAlreadyExists`.
+	Overwrite bool `json:"overwrite"`
+
+	// OutboxTable, if set, is the outbox table an event describing the
+	// mutation is written to, in the same transaction. See `WithOutbox`.
+	OutboxTable string `json:"outboxTable"`
 }
 
 //////
@@ -79,6 +88,16 @@ func WithDatabase[T any](db string) Func[T] {
 	}
 }
 
+// WithOverwrite allows `Create` to replace an existing document, instead of
+// failing with `ErrAlreadyExists`.
+func WithOverwrite() Func[*create.Create] {
+	return func(o *Options[*create.Create]) error {
+		o.Overwrite = true
+
+		return nil
+	}
+}
+
 // NewOptions creates Options.
 func NewOptions[T any]() (*Options[T], error) {
 	o := &Options[T]{}
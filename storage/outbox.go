@@ -0,0 +1,394 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/sypl/v2"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// OutboxName is the name of the outbox relay.
+	OutboxName = "outbox"
+
+	// DefaultOutboxTable is the default outbox table.
+	DefaultOutboxTable = "dal_outbox"
+
+	// DefaultRelayInterval is the default wait between polls of an empty
+	// outbox.
+	DefaultRelayInterval = time.Second
+
+	// DefaultRelayBatchSize is the default number of events claimed per poll.
+	DefaultRelayBatchSize = 100
+
+	// DefaultRelayMaxAttempts is the default number of deliveries before an
+	// event is dead-lettered.
+	DefaultRelayMaxAttempts = 10
+
+	// DefaultRelayBackoff is the default wait before the first redelivery.
+	DefaultRelayBackoff = time.Second
+
+	// DefaultRelayMaxBackoff caps the exponential redelivery backoff.
+	DefaultRelayMaxBackoff = 5 * time.Minute
+)
+
+// OutboxEvent is an event written to an outbox in the same transaction as the
+// mutation it describes.
+type OutboxEvent struct {
+	// ID of the event, assigned by the outbox table.
+	ID int64 `json:"id"`
+
+	// Operation is one of `create`, `update`, or `delete`.
+	Operation Operation `json:"operation"`
+
+	// Target of the mutation.
+	Target string `json:"target"`
+
+	// DocumentID is the ID of the mutated document.
+	DocumentID string `json:"documentId"`
+
+	// Payload is the written document, normalized to JSON. Empty for
+	// deletions.
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// CreatedAt is when the mutation happened.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// AvailableAt is when the event is next delivered.
+	AvailableAt time.Time `json:"availableAt"`
+
+	// Attempts is the number of failed deliveries.
+	Attempts int `json:"attempts"`
+
+	// LastError is the error of the last failed delivery.
+	LastError string `json:"lastError,omitempty"`
+
+	// ProcessedAt is set once delivered.
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+
+	// FailedAt is set once dead-lettered, after `MaxAttempts` deliveries.
+	FailedAt *time.Time `json:"failedAt,omitempty"`
+}
+
+// OutboxDeliverFunc delivers an event, recording the outcome in it:
+// `ProcessedAt` if delivered, otherwise `Attempts`, `LastError`, and either
+// `AvailableAt` (retried), or `FailedAt` (dead-lettered).
+type OutboxDeliverFunc func(ctx context.Context, event *OutboxEvent)
+
+// IOutbox is implemented by storages which can write events to an outbox
+// table transactionally (`WithOutbox`), and relay them.
+type IOutbox interface {
+	// CreateOutbox creates the outbox `table`, if it doesn't exist.
+	CreateOutbox(ctx context.Context, table string) error
+
+	// RelayOutbox claims up to `limit` pending events of `table` - available,
+	// neither processed, nor failed - in a transaction, calls `deliver` for
+	// each, persists the outcomes, and commits. Returns the number of claimed
+	// events.
+	RelayOutbox(ctx context.Context, table string, limit int, deliver OutboxDeliverFunc) (int, error)
+}
+
+// IPublisher publishes outbox events, e.g.: to a message broker.
+type IPublisher interface {
+	// Publish publishes `event`. Events are delivered at-least-once, so
+	// consumers should be idempotent, e.g.: deduplicating by `ID`.
+	Publish(ctx context.Context, event *OutboxEvent) error
+}
+
+// PublisherFunc is a function which implements IPublisher.
+type PublisherFunc func(ctx context.Context, event *OutboxEvent) error
+
+// RelayFunc allows to set relay options.
+type RelayFunc func(r *Relay) error
+
+// Relay polls an outbox, and delivers its events to a publisher. Failed
+// deliveries are retried with exponential backoff, then dead-lettered after
+// `MaxAttempts`. Multiple relays can poll the same outbox: storages which
+// support it claim events with `FOR UPDATE SKIP LOCKED`.
+type Relay struct {
+	// Outbox is the storage holding the outbox table.
+	Outbox IOutbox `json:"-" validate:"required"`
+
+	// Publisher events are delivered to.
+	Publisher IPublisher `json:"-" validate:"required"`
+
+	// Table is the outbox table.
+	Table string `json:"table" validate:"required"`
+
+	// Interval is the wait between polls of an empty outbox.
+	Interval time.Duration `json:"interval" validate:"gt=0"`
+
+	// BatchSize is the number of events claimed per poll.
+	BatchSize int `json:"batchSize" validate:"gt=0"`
+
+	// MaxAttempts is the number of deliveries before an event is
+	// dead-lettered.
+	MaxAttempts int `json:"maxAttempts" validate:"gt=0"`
+
+	// Backoff is the wait before the first redelivery, doubled for each one.
+	Backoff time.Duration `json:"backoff" validate:"gte=0"`
+
+	// MaxBackoff caps the redelivery backoff.
+	MaxBackoff time.Duration `json:"maxBackoff" validate:"gte=0"`
+
+	logger sypl.ISypl
+
+	// Metrics.
+	counterPublished     *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterPublishFailed *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterDeadLettered  *expvar.Int `json:"-" validate:"required,gte=0"`
+}
+
+//////
+// Methods.
+//////
+
+// Publish calls `f`.
+func (f PublisherFunc) Publish(ctx context.Context, event *OutboxEvent) error {
+	return f(ctx, event)
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithOutbox writes an event describing the mutation to the outbox `table`
+// - `DefaultOutboxTable` if empty - in the same transaction. Storages which
+// don't implement IOutbox fail with `ErrUnsupported`, see NoOutbox.
+func WithOutbox[T any](table string) Func[T] {
+	return func(o *Options[T]) error {
+		if table == "" {
+			table = DefaultOutboxTable
+		}
+
+		o.OutboxTable = table
+
+		return nil
+	}
+}
+
+// WithRelayTable sets the outbox table. Default is `DefaultOutboxTable`.
+func WithRelayTable(table string) RelayFunc {
+	return func(r *Relay) error {
+		r.Table = table
+
+		return nil
+	}
+}
+
+// WithRelayInterval sets the wait between polls of an empty outbox. Default
+// is `DefaultRelayInterval`.
+func WithRelayInterval(interval time.Duration) RelayFunc {
+	return func(r *Relay) error {
+		r.Interval = interval
+
+		return nil
+	}
+}
+
+// WithRelayBatchSize sets the number of events claimed per poll. Default is
+// `DefaultRelayBatchSize`.
+func WithRelayBatchSize(size int) RelayFunc {
+	return func(r *Relay) error {
+		r.BatchSize = size
+
+		return nil
+	}
+}
+
+// WithRelayRetry sets the number of deliveries before an event is
+// dead-lettered, and the exponential redelivery backoff. Defaults are
+// `DefaultRelayMaxAttempts`, `DefaultRelayBackoff`, and
+// `DefaultRelayMaxBackoff`.
+func WithRelayRetry(maxAttempts int, backoff, maxBackoff time.Duration) RelayFunc {
+	return func(r *Relay) error {
+		r.MaxAttempts = maxAttempts
+		r.Backoff = backoff
+		r.MaxBackoff = maxBackoff
+
+		return nil
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// backoff returns the wait before the redelivery following `attempts` failed
+// ones.
+func (r *Relay) backoff(attempts int) time.Duration {
+	wait := r.Backoff
+
+	for i := 1; i < attempts && wait < r.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, r.MaxBackoff)
+}
+
+// deliver publishes `event`, recording the outcome.
+func (r *Relay) deliver(ctx context.Context, event *OutboxEvent) {
+	err := r.Publisher.Publish(ctx, event)
+
+	now := time.Now()
+
+	if err == nil {
+		event.ProcessedAt = &now
+
+		r.counterPublished.Add(1)
+
+		return
+	}
+
+	event.Attempts++
+	event.LastError = err.Error()
+
+	r.counterPublishFailed.Add(1)
+
+	if event.Attempts >= r.MaxAttempts {
+		event.FailedAt = &now
+
+		r.counterDeadLettered.Add(1)
+
+		r.logger.Errorln("outbox event", event.ID, "dead-lettered after", event.Attempts, "attempts:", err)
+
+		return
+	}
+
+	event.AvailableAt = now.Add(r.backoff(event.Attempts))
+}
+
+//////
+// Exported functionalities.
+//////
+
+// NoOutbox fails with `ErrUnsupported` if `o` sets an outbox table. Storages
+// which don't implement IOutbox call it on mutations, so `WithOutbox` isn't
+// silently ignored.
+func NoOutbox[T any](o *Options[T]) error {
+	if o.OutboxTable == "" {
+		return nil
+	}
+
+	return customerror.NewFailedToError(
+		"write to outbox "+o.OutboxTable,
+		customerror.WithError(ErrUnsupported),
+	)
+}
+
+// NewOutboxEvent returns the event describing the `operation` of `target`/`id`
+// which wrote `v`.
+func NewOutboxEvent(operation Operation, target, id string, v any) (*OutboxEvent, error) {
+	now := time.Now()
+
+	event := &OutboxEvent{
+		Operation:   operation,
+		Target:      target,
+		DocumentID:  id,
+		CreatedAt:   now,
+		AvailableAt: now,
+	}
+
+	if v != nil {
+		b, err := normalizeJSON(v)
+		if err != nil {
+			return nil, customerror.NewFailedToError("marshal outbox payload", customerror.WithError(err))
+		}
+
+		event.Payload = b
+	}
+
+	return event, nil
+}
+
+// RelayOnce claims, and delivers one batch of events. Returns the number of
+// claimed events.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	return r.Outbox.RelayOutbox(ctx, r.Table, r.BatchSize, r.deliver)
+}
+
+// Run relays events until `ctx` is done. Full batches are followed right away
+// by the next one, otherwise the relay waits `Interval`. Errors are logged, and
+// retried on the next poll.
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Errorln("failed to relay outbox", r.Table+":", err)
+		}
+
+		if err == nil && n >= r.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.Interval):
+		}
+	}
+}
+
+// GetCounterPublished returns the metric.
+func (r *Relay) GetCounterPublished() *expvar.Int {
+	return r.counterPublished
+}
+
+// GetCounterPublishFailed returns the metric.
+func (r *Relay) GetCounterPublishFailed() *expvar.Int {
+	return r.counterPublishFailed
+}
+
+// GetCounterDeadLettered returns the metric.
+func (r *Relay) GetCounterDeadLettered() *expvar.Int {
+	return r.counterDeadLettered
+}
+
+//////
+// Factory.
+//////
+
+// NewRelay returns a new Relay delivering the events of `outbox` to
+// `publisher`. Call `Run` to start it.
+func NewRelay(outbox IOutbox, publisher IPublisher, options ...RelayFunc) (*Relay, error) {
+	r := &Relay{
+		Outbox:    outbox,
+		Publisher: publisher,
+
+		Table:       DefaultOutboxTable,
+		Interval:    DefaultRelayInterval,
+		BatchSize:   DefaultRelayBatchSize,
+		MaxAttempts: DefaultRelayMaxAttempts,
+		Backoff:     DefaultRelayBackoff,
+		MaxBackoff:  DefaultRelayMaxBackoff,
+
+		logger: logging.Get().New(OutboxName).SetTags(Type, OutboxName),
+
+		counterPublished:     metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, OutboxName, "published", DefaultMetricCounterLabel)),
+		counterPublishFailed: metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, OutboxName, "publish.failed", DefaultMetricCounterLabel)),
+		counterDeadLettered:  metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, OutboxName, "dead.lettered", DefaultMetricCounterLabel)),
+	}
+
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validation.Validate(r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOutbox is an in-memory IOutbox.
+type fakeOutbox struct {
+	events []*OutboxEvent
+}
+
+func (f *fakeOutbox) CreateOutbox(_ context.Context, _ string) error {
+	return nil
+}
+
+func (f *fakeOutbox) RelayOutbox(ctx context.Context, _ string, limit int, deliver OutboxDeliverFunc) (int, error) {
+	n := 0
+
+	for _, event := range f.events {
+		if n == limit {
+			break
+		}
+
+		if event.ProcessedAt != nil || event.FailedAt != nil || event.AvailableAt.After(time.Now()) {
+			continue
+		}
+
+		deliver(ctx, event)
+
+		n++
+	}
+
+	return n, nil
+}
+
+func TestRelay(t *testing.T) {
+	ctx := t.Context()
+
+	ok, err := NewOutboxEvent(OperationCreate, "orders", "1", map[string]any{"total": 10})
+	require.NoError(t, err)
+
+	poison, err := NewOutboxEvent(OperationDelete, "orders", "2", nil)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"total":10}`, string(ok.Payload))
+	assert.Empty(t, poison.Payload)
+
+	outbox := &fakeOutbox{events: []*OutboxEvent{ok, poison}}
+
+	published := []string{}
+
+	r, err := NewRelay(outbox, PublisherFunc(func(_ context.Context, event *OutboxEvent) error {
+		if event.DocumentID == "2" {
+			return errors.New("broker unavailable")
+		}
+
+		published = append(published, event.DocumentID)
+
+		return nil
+	}), WithRelayRetry(2, 0, 0))
+	require.NoError(t, err)
+
+	// Counters are shared by relays.
+	published0 := r.GetCounterPublished().Value()
+	failed0 := r.GetCounterPublishFailed().Value()
+	deadLettered0 := r.GetCounterDeadLettered().Value()
+
+	n, err := r.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.Equal(t, []string{"1"}, published)
+	assert.NotNil(t, ok.ProcessedAt)
+	assert.Equal(t, 1, poison.Attempts)
+	assert.Equal(t, "broker unavailable", poison.LastError)
+	assert.Nil(t, poison.FailedAt)
+
+	// Retried, then dead-lettered.
+	n, err = r.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NotNil(t, poison.FailedAt)
+
+	n, err = r.RelayOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	assert.Equal(t, published0+1, r.GetCounterPublished().Value())
+	assert.Equal(t, failed0+2, r.GetCounterPublishFailed().Value())
+	assert.Equal(t, deadLettered0+1, r.GetCounterDeadLettered().Value())
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	r, err := NewRelay(&fakeOutbox{}, PublisherFunc(func(context.Context, *OutboxEvent) error {
+		return nil
+	}), WithRelayRetry(10, time.Second, 5*time.Second))
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Second, r.backoff(1))
+	assert.Equal(t, 2*time.Second, r.backoff(2))
+	assert.Equal(t, 4*time.Second, r.backoff(3))
+	assert.Equal(t, 5*time.Second, r.backoff(4))
+	assert.Equal(t, 5*time.Second, r.backoff(100))
+
+	_, err = NewRelay(nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,422 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/customapm"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/count"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/list"
+	"github.com/thalesfsp/params/v2/retrieve"
+	"github.com/thalesfsp/params/v2/update"
+	"github.com/thalesfsp/status"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// RecorderName is the name of the recorder storage.
+const RecorderName = "recorder"
+
+// InteractionError is a recorded error.
+type InteractionError struct {
+	// Message of the error.
+	Message string `json:"message"`
+
+	// Kind of the error, e.g.: `not found`. See `KindOf`.
+	Kind string `json:"kind,omitempty"`
+
+	// StatusCode of the error, if any.
+	StatusCode int `json:"statusCode,omitempty"`
+}
+
+// Interaction is a recorded operation.
+type Interaction struct {
+	// Operation recorded.
+	Operation Operation `json:"operation"`
+
+	// ID of the document, if the operation addresses one.
+	ID string `json:"id,omitempty"`
+
+	// Target of the operation.
+	Target string `json:"target"`
+
+	// Params of the operation, normalized to JSON.
+	Params json.RawMessage `json:"params,omitempty"`
+
+	// Value written (create, update), normalized to JSON.
+	Value json.RawMessage `json:"value,omitempty"`
+
+	// Result of the operation, normalized to JSON: the document(s) read
+	// (retrieve, list), the count, or the created ID.
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Error returned by the operation, if any.
+	Error *InteractionError `json:"error,omitempty"`
+}
+
+// Cassette is a set of recorded interactions.
+type Cassette struct {
+	// Storage is the name of the recorded storage.
+	Storage string `json:"storage"`
+
+	// Interactions in the order they happened.
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is a storage which wraps another one (`Backend`), and records every
+// operation - with its params, value, result, and error - to a cassette file,
+// so tests can run once against real backends, then be replayed offline with
+// `Replayer`.
+//
+// NOTE: The cassette is written by `Save`. Options (e.g.: hooks) aren't
+// recorded.
+type Recorder struct {
+	*Storage
+
+	// Backend is the recorded storage.
+	Backend IStorage `json:"-" validate:"required"`
+
+	// Path of the cassette file.
+	Path string `json:"path" validate:"required"`
+
+	cassette *Cassette
+	mu       sync.Mutex
+
+	// Metrics.
+	counterRecorded *expvar.Int `json:"-" validate:"required,gte=0"`
+}
+
+//////
+// Helpers.
+//////
+
+// toRawJSON normalizes `v` to JSON. Nil, or unserializable values are
+// omitted.
+func toRawJSON(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+
+	b, err := normalizeJSON(v)
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+// newInteractionError converts `err` into its recorded form.
+func newInteractionError(err error) *InteractionError {
+	if err == nil {
+		return nil
+	}
+
+	iE := &InteractionError{Message: err.Error()}
+
+	if kind := KindOf(err, nil); kind != nil {
+		iE.Kind = kind.Error()
+	}
+
+	var cE *customerror.CustomError
+	if errors.As(err, &cE) {
+		iE.StatusCode = cE.StatusCode
+	}
+
+	return iE
+}
+
+// record appends an interaction to the cassette.
+func (r *Recorder) record(interaction Interaction, err error) {
+	interaction.Error = newInteractionError(err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+
+	r.counterRecorded.Add(1)
+}
+
+//////
+// Implements the IStorage interface.
+//////
+
+// Count counts from the backend, and records it.
+func (r *Recorder) Count(ctx context.Context, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
+	ctx, span := customapm.Trace(ctx, r.GetType(), RecorderName, status.Counted.String())
+	defer span.End()
+
+	c, err := r.Backend.Count(ctx, target, prm, options...)
+
+	interaction := Interaction{Operation: OperationCount, Target: target, Params: toRawJSON(prm)}
+
+	if err == nil {
+		interaction.Result = toRawJSON(c)
+	}
+
+	r.record(interaction, err)
+
+	if err != nil {
+		return 0, customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCountedFailed())
+	}
+
+	r.GetCounterCounted().Add(1)
+
+	return c, nil
+}
+
+// Delete deletes from the backend, and records it.
+func (r *Recorder) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), RecorderName, status.Deleted.String())
+	defer span.End()
+
+	err := r.Backend.Delete(ctx, id, target, prm, options...)
+
+	r.record(Interaction{Operation: OperationDelete, ID: id, Target: target, Params: toRawJSON(prm)}, err)
+
+	if err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterDeletedFailed())
+	}
+
+	r.GetCounterDeleted().Add(1)
+
+	return nil
+}
+
+// Retrieve retrieves from the backend, and records it.
+func (r *Recorder) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), RecorderName, status.Retrieved.String())
+	defer span.End()
+
+	err := r.Backend.Retrieve(ctx, id, target, v, prm, options...)
+
+	interaction := Interaction{Operation: OperationRetrieve, ID: id, Target: target, Params: toRawJSON(prm)}
+
+	if err == nil {
+		interaction.Result = toRawJSON(v)
+	}
+
+	r.record(interaction, err)
+
+	if err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterRetrievedFailed())
+	}
+
+	r.GetCounterRetrieved().Add(1)
+
+	return nil
+}
+
+// List lists from the backend, and records it.
+func (r *Recorder) List(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), RecorderName, status.Listed.String())
+	defer span.End()
+
+	err := r.Backend.List(ctx, target, v, prm, options...)
+
+	interaction := Interaction{Operation: OperationList, Target: target, Params: toRawJSON(prm)}
+
+	if err == nil {
+		interaction.Result = toRawJSON(v)
+	}
+
+	r.record(interaction, err)
+
+	if err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterListedFailed())
+	}
+
+	r.GetCounterListed().Add(1)
+
+	return nil
+}
+
+// Create creates into the backend, and records it.
+func (r *Recorder) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+	ctx, span := customapm.Trace(ctx, r.GetType(), RecorderName, status.Created.String())
+	defer span.End()
+
+	returnedID, err := r.Backend.Create(ctx, id, target, v, prm, options...)
+
+	interaction := Interaction{
+		Operation: OperationCreate,
+		ID:        id,
+		Target:    target,
+		Params:    toRawJSON(prm),
+		Value:     toRawJSON(v),
+	}
+
+	if err == nil {
+		interaction.Result = toRawJSON(returnedID)
+	}
+
+	r.record(interaction, err)
+
+	if err != nil {
+		return "", customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCreatedFailed())
+	}
+
+	r.GetCounterCreated().Add(1)
+
+	return returnedID, nil
+}
+
+// Update updates the backend, and records it.
+func (r *Recorder) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...Func[*update.Update]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), RecorderName, status.Updated.String())
+	defer span.End()
+
+	err := r.Backend.Update(ctx, id, target, v, prm, options...)
+
+	r.record(Interaction{
+		Operation: OperationUpdate,
+		ID:        id,
+		Target:    target,
+		Params:    toRawJSON(prm),
+		Value:     toRawJSON(v),
+	}, err)
+
+	if err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterUpdatedFailed())
+	}
+
+	r.GetCounterUpdated().Add(1)
+
+	return nil
+}
+
+// GetClient returns the backend's client.
+func (r *Recorder) GetClient() any {
+	return r.Backend.GetClient()
+}
+
+// GetCounterRecorded returns the metric.
+func (r *Recorder) GetCounterRecorded() *expvar.Int {
+	return r.counterRecorded
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Cassette returns a copy of the recorded cassette.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Cassette{
+		Storage:      r.cassette.Storage,
+		Interactions: append([]Interaction(nil), r.cassette.Interactions...),
+	}
+}
+
+// Save writes the cassette to `Path`. It's written to a temporary file of the
+// same directory, then renamed, so `Path` always holds a complete cassette.
+func (r *Recorder) Save() error {
+	b, err := shared.Marshal(r.Cassette())
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(r.Path), filepath.Base(r.Path)+".*.tmp")
+	if err != nil {
+		return customerror.NewFailedToError("create cassette file", customerror.WithError(err))
+	}
+
+	// No-op once renamed.
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+
+		return customerror.NewFailedToError("write cassette", customerror.WithError(err))
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+
+		return customerror.NewFailedToError("sync cassette file", customerror.WithError(err))
+	}
+
+	if err := f.Close(); err != nil {
+		return customerror.NewFailedToError("close cassette file", customerror.WithError(err))
+	}
+
+	if err := os.Rename(f.Name(), r.Path); err != nil {
+		return customerror.NewFailedToError("rename cassette file", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// LoadCassette reads a cassette written by `Recorder.Save`.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, customerror.NewFailedToError("read cassette", customerror.WithError(err))
+	}
+
+	var c Cassette
+
+	if err := shared.Unmarshal(b, &c); err != nil {
+		return nil, customerror.NewFailedToError("unmarshal cassette", customerror.WithError(err))
+	}
+
+	return &c, nil
+}
+
+//////
+// Factory.
+//////
+
+// NewRecorder returns a new Recorder storage recording the operations of
+// `backend` to the cassette file at `path`.
+func NewRecorder(ctx context.Context, backend IStorage, path string) (*Recorder, error) {
+	// Enforces IStorage interface implementation.
+	var _ IStorage = (*Recorder)(nil)
+
+	s, err := New(ctx, RecorderName)
+	if err != nil {
+		return nil, err
+	}
+
+	if backend == nil {
+		return nil, customapm.TraceError(
+			ctx,
+			customerror.NewRequiredError("backend storage"),
+			s.GetLogger(),
+			s.counterInstantiationFailed,
+		)
+	}
+
+	r := &Recorder{
+		Storage: s,
+
+		Backend: backend,
+		Path:    path,
+
+		cassette: &Cassette{Storage: backend.GetName(), Interactions: []Interaction{}},
+
+		counterRecorded: metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, RecorderName, "recorded", DefaultMetricCounterLabel)),
+	}
+
+	if err := validation.Validate(r); err != nil {
+		return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Replayer(t *testing.T) {
+	ctx := t.Context()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	backend, _ := newKVMock("recorderb1")
+
+	r, err := NewRecorder(ctx, backend, path)
+	require.NoError(t, err)
+
+	// scenario runs the same operations against recorder, and replayer.
+	scenario := func(s IStorage) {
+		id, err := s.Create(ctx, "1", "users", &TestDataS{K: "v1"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "1", id)
+
+		require.NoError(t, s.Update(ctx, "1", "users", &TestDataS{K: "v2"}, nil))
+
+		var got TestDataS
+		require.NoError(t, s.Retrieve(ctx, "1", "users", &got, nil))
+		assert.Equal(t, "v2", got.K)
+
+		c, err := s.Count(ctx, "users", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), c)
+
+		require.NoError(t, s.Delete(ctx, "1", "users", nil))
+
+		err = s.Retrieve(ctx, "1", "users", &got, nil)
+		require.Error(t, err)
+		assert.Equal(t, ErrNotFound, KindOf(err, nil))
+	}
+
+	scenario(r)
+
+	require.NoError(t, r.Save())
+	assert.Len(t, r.Cassette().Interactions, 6)
+
+	// Saved through a temporary file, renamed.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, filepath.Base(path), entries[0].Name())
+
+	replayer, err := NewReplayerFromFile(ctx, path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "recorderb1", replayer.Cassette.Storage)
+
+	scenario(replayer)
+
+	assert.Empty(t, replayer.Unused())
+
+	// Not recorded.
+	_, err = replayer.Create(ctx, "2", "users", &TestDataS{K: "v1"}, nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUnexpectedCall))
+}
+
+func TestReplayer_Unused(t *testing.T) {
+	replayer, err := NewReplayer(t.Context(), &Cassette{
+		Storage: "replayerb1",
+		Interactions: []Interaction{
+			{Operation: OperationDelete, ID: "1", Target: "users"},
+			{Operation: OperationDelete, ID: "2", Target: "users"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, replayer.Delete(t.Context(), "2", "users", nil))
+
+	unused := replayer.Unused()
+	require.Len(t, unused, 1)
+	assert.Equal(t, "1", unused[0].ID)
+
+	// Interactions are only replayed once.
+	require.Error(t, replayer.Delete(t.Context(), "2", "users", nil))
+}
+
+func TestIgnoringFields(t *testing.T) {
+	recorded := Interaction{
+		Operation: OperationCreate,
+		Target:    "users",
+		Value:     json.RawMessage(`{"k": "v", "meta": {"createdAt": "2026-01-01T00:00:00Z"}}`),
+	}
+
+	actual := Interaction{
+		Operation: OperationCreate,
+		Target:    "users",
+		Value:     json.RawMessage(`{"k":"v","meta":{"createdAt":"2026-10-18T00:00:00Z"}}`),
+	}
+
+	assert.False(t, DefaultMatcher(recorded, actual))
+	assert.True(t, IgnoringFields("createdAt")(recorded, actual))
+
+	actual.Value = json.RawMessage(`{"k":"other","meta":{}}`)
+	assert.False(t, IgnoringFields("createdAt")(recorded, actual))
+}
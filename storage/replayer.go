@@ -0,0 +1,445 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/customapm"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/count"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/list"
+	"github.com/thalesfsp/params/v2/retrieve"
+	"github.com/thalesfsp/params/v2/update"
+	"github.com/thalesfsp/status"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// ReplayerName is the name of the replayer storage.
+const ReplayerName = "replayer"
+
+// ErrUnexpectedCall is returned by the replayer when no recorded interaction
+// matches the operation.
+var ErrUnexpectedCall = errors.New("unexpected call")
+
+// MatcherFunc reports whether the `recorded` interaction matches the `actual`
+// one. Only the operation, id, target, params, and value of `actual` are set.
+type MatcherFunc func(recorded, actual Interaction) bool
+
+// ReplayerFunc allows to set replayer options.
+type ReplayerFunc func(r *Replayer) error
+
+// Replayer is a storage which serves the interactions recorded by `Recorder`,
+// so tests run offline, and deterministically.
+//
+// Each operation is matched (`Matcher`) against the unused interactions, in
+// order; the first match is served, and marked used. Operations without a
+// match fail with `ErrUnexpectedCall`. Use `Unused` to check every
+// interaction was replayed.
+//
+// NOTE: Options (e.g.: hooks) are ignored.
+type Replayer struct {
+	*Storage
+
+	// Cassette being replayed.
+	Cassette *Cassette `json:"-" validate:"required"`
+
+	// Matcher matches interactions. Default is `DefaultMatcher`.
+	Matcher MatcherFunc `json:"-" validate:"required"`
+
+	mu   sync.Mutex
+	used []bool
+
+	// Metrics.
+	counterReplayed   *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterUnexpected *expvar.Int `json:"-" validate:"required,gte=0"`
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithMatcher sets how interactions are matched. Default is
+// `DefaultMatcher`.
+func WithMatcher(matcher MatcherFunc) ReplayerFunc {
+	return func(r *Replayer) error {
+		if matcher == nil {
+			return customerror.NewRequiredError("matcher")
+		}
+
+		r.Matcher = matcher
+
+		return nil
+	}
+}
+
+// WithIgnoredFields matches interactions ignoring volatile fields (e.g.:
+// timestamps, generated IDs) of the params, and the value, at any depth.
+func WithIgnoredFields(fields ...string) ReplayerFunc {
+	return WithMatcher(IgnoringFields(fields...))
+}
+
+//////
+// Helpers.
+//////
+
+// withoutFields removes the `fields` keys, at any depth, from a generic JSON
+// value.
+func withoutFields(v any, fields []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		// The params package shadows the builtin `delete`.
+		maps.DeleteFunc(t, func(k string, _ any) bool { return slices.Contains(fields, k) })
+
+		for k, value := range t {
+			t[k] = withoutFields(value, fields)
+		}
+	case []any:
+		for i, value := range t {
+			t[i] = withoutFields(value, fields)
+		}
+	}
+
+	return v
+}
+
+// equalJSON compares two JSON documents, regardless of their formatting, and
+// ignoring `fields`. Empty documents equal `null`.
+func equalJSON(a, b json.RawMessage, fields []string) bool {
+	if len(a) == 0 {
+		a = json.RawMessage("null")
+	}
+
+	if len(b) == 0 {
+		b = json.RawMessage("null")
+	}
+
+	var genericA, genericB any
+
+	if err := shared.Unmarshal(a, &genericA); err != nil {
+		return false
+	}
+
+	if err := shared.Unmarshal(b, &genericB); err != nil {
+		return false
+	}
+
+	normalizedA, errA := shared.Marshal(withoutFields(genericA, fields))
+	normalizedB, errB := shared.Marshal(withoutFields(genericB, fields))
+
+	return errA == nil && errB == nil && bytes.Equal(normalizedA, normalizedB)
+}
+
+// toError rebuilds a recorded error. It matches its kind with `errors.Is`.
+func (r *Replayer) toError(interaction Interaction) error {
+	if interaction.Error == nil {
+		return nil
+	}
+
+	var err error = errors.New(interaction.Error.Message)
+
+	if interaction.Error.StatusCode != 0 {
+		err = customerror.New(interaction.Error.Message, customerror.WithStatusCode(interaction.Error.StatusCode))
+	}
+
+	var kind error
+
+	for _, k := range kinds {
+		if k.Error() == interaction.Error.Kind {
+			kind = k
+
+			break
+		}
+	}
+
+	return &Error{
+		Kind:      kind,
+		Storage:   r.Cassette.Storage,
+		Operation: interaction.Operation,
+		Target:    interaction.Target,
+		ID:        interaction.ID,
+		Err:       err,
+	}
+}
+
+// replay returns the first unused interaction matching `actual`, marking it
+// used.
+func (r *Replayer) replay(actual Interaction) (Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, recorded := range r.Cassette.Interactions {
+		if r.used[i] || !r.Matcher(recorded, actual) {
+			continue
+		}
+
+		r.used[i] = true
+
+		r.counterReplayed.Add(1)
+
+		return recorded, r.toError(recorded)
+	}
+
+	r.counterUnexpected.Add(1)
+
+	return Interaction{}, &Error{
+		Storage:   r.Cassette.Storage,
+		Operation: actual.Operation,
+		Target:    actual.Target,
+		ID:        actual.ID,
+		Err: customerror.New(
+			fmt.Sprintf("%s %s %s", actual.Operation, actual.Target, actual.ID),
+			customerror.WithError(ErrUnexpectedCall),
+		),
+	}
+}
+
+//////
+// Implements the IStorage interface.
+//////
+
+// Count replays a count.
+func (r *Replayer) Count(ctx context.Context, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ReplayerName, status.Counted.String())
+	defer span.End()
+
+	var c int64
+
+	interaction, err := r.replay(Interaction{Operation: OperationCount, Target: target, Params: toRawJSON(prm)})
+	if err == nil {
+		err = shared.Unmarshal(interaction.Result, &c)
+	}
+
+	if err != nil {
+		return 0, customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCountedFailed())
+	}
+
+	r.GetCounterCounted().Add(1)
+
+	return c, nil
+}
+
+// Delete replays a delete.
+func (r *Replayer) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ReplayerName, status.Deleted.String())
+	defer span.End()
+
+	if _, err := r.replay(Interaction{Operation: OperationDelete, ID: id, Target: target, Params: toRawJSON(prm)}); err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterDeletedFailed())
+	}
+
+	r.GetCounterDeleted().Add(1)
+
+	return nil
+}
+
+// Retrieve replays a retrieve, filling `v`.
+func (r *Replayer) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ReplayerName, status.Retrieved.String())
+	defer span.End()
+
+	interaction, err := r.replay(Interaction{Operation: OperationRetrieve, ID: id, Target: target, Params: toRawJSON(prm)})
+	if err == nil {
+		err = shared.Unmarshal(interaction.Result, v)
+	}
+
+	if err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterRetrievedFailed())
+	}
+
+	r.GetCounterRetrieved().Add(1)
+
+	return nil
+}
+
+// List replays a list, filling `v`.
+func (r *Replayer) List(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ReplayerName, status.Listed.String())
+	defer span.End()
+
+	interaction, err := r.replay(Interaction{Operation: OperationList, Target: target, Params: toRawJSON(prm)})
+	if err == nil {
+		err = shared.Unmarshal(interaction.Result, v)
+	}
+
+	if err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterListedFailed())
+	}
+
+	r.GetCounterListed().Add(1)
+
+	return nil
+}
+
+// Create replays a create, returning the recorded ID.
+func (r *Replayer) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ReplayerName, status.Created.String())
+	defer span.End()
+
+	var returnedID string
+
+	interaction, err := r.replay(Interaction{
+		Operation: OperationCreate,
+		ID:        id,
+		Target:    target,
+		Params:    toRawJSON(prm),
+		Value:     toRawJSON(v),
+	})
+	if err == nil {
+		err = shared.Unmarshal(interaction.Result, &returnedID)
+	}
+
+	if err != nil {
+		return "", customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCreatedFailed())
+	}
+
+	r.GetCounterCreated().Add(1)
+
+	return returnedID, nil
+}
+
+// Update replays an update.
+func (r *Replayer) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...Func[*update.Update]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ReplayerName, status.Updated.String())
+	defer span.End()
+
+	if _, err := r.replay(Interaction{
+		Operation: OperationUpdate,
+		ID:        id,
+		Target:    target,
+		Params:    toRawJSON(prm),
+		Value:     toRawJSON(v),
+	}); err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterUpdatedFailed())
+	}
+
+	r.GetCounterUpdated().Add(1)
+
+	return nil
+}
+
+// GetClient returns nil, there's no client.
+func (r *Replayer) GetClient() any {
+	return nil
+}
+
+// GetCounterReplayed returns the metric.
+func (r *Replayer) GetCounterReplayed() *expvar.Int {
+	return r.counterReplayed
+}
+
+// GetCounterUnexpected returns the metric.
+func (r *Replayer) GetCounterUnexpected() *expvar.Int {
+	return r.counterUnexpected
+}
+
+//////
+// Exported functionalities.
+//////
+
+// DefaultMatcher matches interactions with the same operation, id, target,
+// params, and value.
+func DefaultMatcher(recorded, actual Interaction) bool {
+	return IgnoringFields()(recorded, actual)
+}
+
+// IgnoringFields is like `DefaultMatcher`, but ignores the `fields` keys, at
+// any depth, of the params, and the value.
+func IgnoringFields(fields ...string) MatcherFunc {
+	return func(recorded, actual Interaction) bool {
+		return recorded.Operation == actual.Operation &&
+			recorded.ID == actual.ID &&
+			recorded.Target == actual.Target &&
+			equalJSON(recorded.Params, actual.Params, fields) &&
+			equalJSON(recorded.Value, actual.Value, fields)
+	}
+}
+
+// Unused returns the interactions which weren't replayed.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unused := []Interaction{}
+
+	for i, interaction := range r.Cassette.Interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+
+	return unused
+}
+
+//////
+// Factory.
+//////
+
+// NewReplayer returns a new Replayer storage serving `cassette`.
+func NewReplayer(ctx context.Context, cassette *Cassette, options ...ReplayerFunc) (*Replayer, error) {
+	// Enforces IStorage interface implementation.
+	var _ IStorage = (*Replayer)(nil)
+
+	s, err := New(ctx, ReplayerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if cassette == nil {
+		return nil, customapm.TraceError(
+			ctx,
+			customerror.NewRequiredError("cassette"),
+			s.GetLogger(),
+			s.counterInstantiationFailed,
+		)
+	}
+
+	r := &Replayer{
+		Storage: s,
+
+		Cassette: cassette,
+		Matcher:  DefaultMatcher,
+
+		used: make([]bool, len(cassette.Interactions)),
+
+		counterReplayed:   metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, ReplayerName, "replayed", DefaultMetricCounterLabel)),
+		counterUnexpected: metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, ReplayerName, "unexpected", DefaultMetricCounterLabel)),
+	}
+
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+		}
+	}
+
+	if err := validation.Validate(r); err != nil {
+		return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+	}
+
+	return r, nil
+}
+
+// NewReplayerFromFile is like NewReplayer, but loads the cassette from
+// `path`. See `LoadCassette`.
+func NewReplayerFromFile(ctx context.Context, path string, options ...ReplayerFunc) (*Replayer, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayer(ctx, cassette, options...)
+}
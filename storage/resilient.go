@@ -0,0 +1,522 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/eapache/go-resiliency/breaker"
+	"github.com/eapache/go-resiliency/retrier"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/customapm"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/params/v2/count"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/list"
+	"github.com/thalesfsp/params/v2/retrieve"
+	"github.com/thalesfsp/params/v2/update"
+	"github.com/thalesfsp/status"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// ResilientName is the name of the resilient storage.
+	ResilientName = "resilient"
+
+	// DefaultRetryAttempts is the default number of retries.
+	DefaultRetryAttempts = 3
+
+	// DefaultRetryInitialBackoff is the default wait before the first retry.
+	DefaultRetryInitialBackoff = 100 * time.Millisecond
+
+	// DefaultRetryMaxBackoff caps the exponential backoff.
+	DefaultRetryMaxBackoff = 5 * time.Second
+
+	// DefaultRetryJitter is the default jitter factor (0..1) applied to each
+	// backoff.
+	DefaultRetryJitter = 0.25
+
+	// DefaultBreakerErrorThreshold is the default number of transient errors,
+	// without an error-free `DefaultBreakerTimeout`, which opens the breaker.
+	DefaultBreakerErrorThreshold = 5
+
+	// DefaultBreakerSuccessThreshold is the default number of consecutive
+	// successes which closes a half-open breaker.
+	DefaultBreakerSuccessThreshold = 1
+
+	// DefaultBreakerTimeout is the default time an open breaker waits before
+	// half-opening.
+	DefaultBreakerTimeout = 30 * time.Second
+)
+
+// ResilientFunc allows to set resilient options.
+type ResilientFunc func(r *Resilient) error
+
+// Resilient is a storage which wraps another one (`Backend`) with operation
+// level retries - exponential backoff with jitter - and a circuit breaker.
+//
+// Errors are classified by `IsTransient`: transient errors are retried, and
+// count towards opening the breaker; permanent ones (e.g.: not found,
+// validation) are returned right away, and leave it as is - a half-open
+// breaker neither closes, nor re-opens, on them. Use the backend's
+// classifier, e.g.: `postgres.IsTransientError`.
+//
+// While the breaker is open, operations fail fast with an HTTP 503 error
+// wrapping `breaker.ErrBreakerOpen`, and `Health` reports it.
+//
+// NOTE: Retries re-run the whole operation, including the backend's pre/post
+// hooks. Only wrap operations which are safe to repeat.
+type Resilient struct {
+	*Storage
+
+	// Backend is the wrapped storage.
+	Backend IStorage `json:"-" validate:"required"`
+
+	// IsTransient classifies errors.
+	IsTransient ErrorClassifierFunc `json:"-" validate:"required"`
+
+	breaker *circuit
+	retrier *retrier.Retrier
+
+	// Metrics.
+	counterRetried    *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterRejected   *expvar.Int `json:"-" validate:"required,gte=0"`
+	gaugeBreakerState *expvar.Int `json:"-" validate:"required"`
+}
+
+// transientClassifier adapts an ErrorClassifierFunc to retrier.Classifier.
+type transientClassifier ErrorClassifierFunc
+
+// Classify implements the retrier.Classifier interface.
+func (c transientClassifier) Classify(err error) retrier.Action {
+	switch {
+	case err == nil:
+		return retrier.Succeed
+	case errors.Is(err, breaker.ErrBreakerOpen):
+		return retrier.Fail
+	case c(err):
+		return retrier.Retry
+	default:
+		return retrier.Fail
+	}
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithRetry retries transient errors up to `attempts` times, waiting an
+// exponential backoff, starting at `initial`, capped at `maxBackoff`, and
+// randomized by `jitter` (0..1). Zero `attempts` disables retries.
+func WithRetry(attempts int, initial, maxBackoff time.Duration, jitter float64) ResilientFunc {
+	return func(r *Resilient) error {
+		if attempts < 0 || initial <= 0 || maxBackoff < initial || jitter < 0 || jitter > 1 {
+			return customerror.NewInvalidError("retry, must be attempts >= 0, 0 < initial <= max, and 0 <= jitter <= 1")
+		}
+
+		if attempts == 0 {
+			r.retrier = nil
+
+			return nil
+		}
+
+		r.retrier = retrier.New(
+			retrier.LimitedExponentialBackoff(attempts, initial, maxBackoff),
+			transientClassifier(func(err error) bool { return r.IsTransient(err) }),
+		)
+
+		r.retrier.SetJitter(jitter)
+
+		return nil
+	}
+}
+
+// WithBreaker sets the circuit breaker: it opens after `errorThreshold`
+// transient errors without an error-free `timeout`, half-opens after
+// `timeout`, and closes after `successThreshold` consecutive successes. Zero
+// `errorThreshold` disables the breaker.
+func WithBreaker(errorThreshold, successThreshold int, timeout time.Duration) ResilientFunc {
+	return func(r *Resilient) error {
+		if errorThreshold < 0 || successThreshold <= 0 || timeout <= 0 {
+			return customerror.NewInvalidError("breaker, must be errorThreshold >= 0, successThreshold > 0, and timeout > 0")
+		}
+
+		if errorThreshold == 0 {
+			r.breaker = nil
+
+			return nil
+		}
+
+		r.breaker = newCircuit(errorThreshold, successThreshold, timeout)
+
+		return nil
+	}
+}
+
+// WithTransientClassifier sets how errors are classified. Default is
+// `IsTransientError`.
+func WithTransientClassifier(isTransient ErrorClassifierFunc) ResilientFunc {
+	return func(r *Resilient) error {
+		if isTransient == nil {
+			return customerror.NewRequiredError("transient classifier")
+		}
+
+		r.IsTransient = isTransient
+
+		return nil
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// refreshState reflects the breaker state in the gauge, and returns it.
+func (r *Resilient) refreshState() breaker.State {
+	if r.breaker == nil {
+		return breaker.Closed
+	}
+
+	s := r.breaker.GetState()
+
+	r.gaugeBreakerState.Set(int64(s))
+
+	return s
+}
+
+// judge tells how `err` affects the breaker: only transient errors count
+// towards opening it, permanent ones are neutral.
+func (r *Resilient) judge(err error) verdict {
+	switch {
+	case err == nil:
+		return verdictSuccess
+	case r.IsTransient(err):
+		return verdictFailure
+	default:
+		return verdictNeutral
+	}
+}
+
+// attempt runs `fn` once, through the breaker.
+func (r *Resilient) attempt(ctx context.Context, op Operation, fn func(ctx context.Context) error) error {
+	if r.breaker == nil {
+		return fn(ctx)
+	}
+
+	err := r.breaker.Run(func() error { return fn(ctx) }, r.judge)
+
+	r.refreshState()
+
+	if errors.Is(err, breaker.ErrBreakerOpen) {
+		r.counterRejected.Add(1)
+
+		return customerror.NewFailedToError(
+			op.String()+", "+r.Backend.GetName(),
+			customerror.WithError(err),
+			customerror.WithStatusCode(http.StatusServiceUnavailable),
+		)
+	}
+
+	return err
+}
+
+// run runs `fn` with retries, and the breaker.
+func (r *Resilient) run(ctx context.Context, op Operation, fn func(ctx context.Context) error) error {
+	if r.retrier == nil {
+		return r.attempt(ctx, op, fn)
+	}
+
+	return r.retrier.RunFn(ctx, func(ctx context.Context, retries int) error {
+		if retries > 0 {
+			r.counterRetried.Add(1)
+		}
+
+		return r.attempt(ctx, op, fn)
+	})
+}
+
+//////
+// Implements the IStorage interface.
+//////
+
+// Count data.
+func (r *Resilient) Count(ctx context.Context, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ResilientName, status.Counted.String())
+	defer span.End()
+
+	var c int64
+
+	if err := r.run(ctx, OperationCount, func(ctx context.Context) error {
+		var err error
+
+		c, err = r.Backend.Count(ctx, target, prm, options...)
+
+		return err
+	}); err != nil {
+		return 0, customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCountedFailed())
+	}
+
+	r.GetCounterCounted().Add(1)
+
+	return c, nil
+}
+
+// Delete data.
+func (r *Resilient) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ResilientName, status.Deleted.String())
+	defer span.End()
+
+	if err := r.run(ctx, OperationDelete, func(ctx context.Context) error {
+		return r.Backend.Delete(ctx, id, target, prm, options...)
+	}); err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterDeletedFailed())
+	}
+
+	r.GetCounterDeleted().Add(1)
+
+	return nil
+}
+
+// Retrieve data.
+func (r *Resilient) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ResilientName, status.Retrieved.String())
+	defer span.End()
+
+	if err := r.run(ctx, OperationRetrieve, func(ctx context.Context) error {
+		return r.Backend.Retrieve(ctx, id, target, v, prm, options...)
+	}); err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterRetrievedFailed())
+	}
+
+	r.GetCounterRetrieved().Add(1)
+
+	return nil
+}
+
+// List data.
+func (r *Resilient) List(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ResilientName, status.Listed.String())
+	defer span.End()
+
+	if err := r.run(ctx, OperationList, func(ctx context.Context) error {
+		return r.Backend.List(ctx, target, v, prm, options...)
+	}); err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterListedFailed())
+	}
+
+	r.GetCounterListed().Add(1)
+
+	return nil
+}
+
+// Create data.
+func (r *Resilient) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ResilientName, status.Created.String())
+	defer span.End()
+
+	var createdID string
+
+	if err := r.run(ctx, OperationCreate, func(ctx context.Context) error {
+		var err error
+
+		createdID, err = r.Backend.Create(ctx, id, target, v, prm, options...)
+
+		return err
+	}); err != nil {
+		return "", customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterCreatedFailed())
+	}
+
+	r.GetCounterCreated().Add(1)
+
+	return createdID, nil
+}
+
+// Update data.
+func (r *Resilient) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...Func[*update.Update]) error {
+	ctx, span := customapm.Trace(ctx, r.GetType(), ResilientName, status.Updated.String())
+	defer span.End()
+
+	if err := r.run(ctx, OperationUpdate, func(ctx context.Context) error {
+		return r.Backend.Update(ctx, id, target, v, prm, options...)
+	}); err != nil {
+		return customapm.TraceError(ctx, err, r.GetLogger(), r.GetCounterUpdatedFailed())
+	}
+
+	r.GetCounterUpdated().Add(1)
+
+	return nil
+}
+
+// GetClient returns the backend's client.
+func (r *Resilient) GetClient() any {
+	return r.Backend.GetClient()
+}
+
+//////
+// Implements the IHealthChecker interface.
+//////
+
+// Health returns an error while the breaker is open.
+func (r *Resilient) Health(ctx context.Context) error {
+	if r.refreshState() == breaker.Open {
+		return customerror.NewHTTPError(
+			http.StatusServiceUnavailable,
+			customerror.WithError(breaker.ErrBreakerOpen),
+			customerror.WithField("storage", r.Backend.GetName()),
+		)
+	}
+
+	return Health(ctx, r.Backend)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// GetBreakerState returns the breaker state. Always closed if the breaker is
+// disabled.
+func (r *Resilient) GetBreakerState() breaker.State {
+	return r.refreshState()
+}
+
+// GetCounterRetried returns the metric.
+func (r *Resilient) GetCounterRetried() *expvar.Int {
+	return r.counterRetried
+}
+
+// GetCounterRejected returns the metric.
+func (r *Resilient) GetCounterRejected() *expvar.Int {
+	return r.counterRejected
+}
+
+// GetGaugeBreakerState returns the metric: 0 closed, 1 open, 2 half-open.
+func (r *Resilient) GetGaugeBreakerState() *expvar.Int {
+	return r.gaugeBreakerState
+}
+
+// HasStatusCode reports whether any custom error in `err`'s chain has one of
+// the `codes`. Wrapping errors (e.g.: "failed to") default to 500, so the
+// whole chain is inspected.
+func HasStatusCode(err error, codes ...int) bool {
+	for err != nil {
+		var cE *customerror.CustomError
+		if !errors.As(err, &cE) {
+			return false
+		}
+
+		if slices.Contains(codes, cE.StatusCode) {
+			return true
+		}
+
+		err = cE.Unwrap()
+	}
+
+	return false
+}
+
+// IsTransientError is the default classifier. It reports whether `err` is
+// likely to go away if the operation is retried: network timeouts, connection
+// resets/refusals, bad driver connections, and HTTP 429, 502, 503, 504.
+//
+// NOTE: Cancelled contexts are never transient. Deadlines of the caller's
+// context are checked by the retrier, before each wait.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, breaker.ErrBreakerOpen) {
+		return false
+	}
+
+	if errors.Is(err, os.ErrDeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return HasStatusCode(
+		err,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	)
+}
+
+//////
+// Factory.
+//////
+
+// NewResilient wraps `backend` with retries, and a circuit breaker. Defaults:
+// `DefaultRetryAttempts` retries, `DefaultBreakerErrorThreshold` errors to
+// open, and `IsTransientError` as classifier.
+func NewResilient(ctx context.Context, backend IStorage, options ...ResilientFunc) (*Resilient, error) {
+	// Enforces IStorage interface implementation.
+	var _ IStorage = (*Resilient)(nil)
+
+	s, err := New(ctx, ResilientName)
+	if err != nil {
+		return nil, err
+	}
+
+	if backend == nil {
+		return nil, customapm.TraceError(
+			ctx,
+			customerror.NewRequiredError("backend storage"),
+			s.GetLogger(),
+			s.counterInstantiationFailed,
+		)
+	}
+
+	prefix := fmt.Sprintf("%s.%s.%s", Type, ResilientName, backend.GetName())
+
+	r := &Resilient{
+		Storage: s,
+
+		Backend:     backend,
+		IsTransient: IsTransientError,
+
+		counterRetried:    metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "retried", DefaultMetricCounterLabel)),
+		counterRejected:   metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "rejected", DefaultMetricCounterLabel)),
+		gaugeBreakerState: metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "breaker.state", DefaultMetricGaugeLabel)),
+	}
+
+	defaults := []ResilientFunc{
+		WithRetry(DefaultRetryAttempts, DefaultRetryInitialBackoff, DefaultRetryMaxBackoff, DefaultRetryJitter),
+		WithBreaker(DefaultBreakerErrorThreshold, DefaultBreakerSuccessThreshold, DefaultBreakerTimeout),
+	}
+
+	for _, option := range append(defaults, options...) {
+		if err := option(r); err != nil {
+			return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+		}
+	}
+
+	if err := validation.Validate(r); err != nil {
+		return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+	}
+
+	r.refreshState()
+
+	return r, nil
+}
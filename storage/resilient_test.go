@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eapache/go-resiliency/breaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/params/v2/retrieve"
+)
+
+// newFlakyMock builds a Mock whose Retrieve returns `errs[i]` on the i-th call,
+// then nil.
+func newFlakyMock(name string, calls *atomic.Int64, errs ...error) *Mock {
+	return &Mock{
+		MockRetrieve: func(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+			n := calls.Add(1)
+
+			if int(n) <= len(errs) {
+				return errs[n-1]
+			}
+
+			return nil
+		},
+		MockGetName: func() string { return name },
+	}
+}
+
+func TestResilient_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int64
+
+	unavailable := customerror.NewHTTPError(http.StatusServiceUnavailable)
+
+	r, err := NewResilient(
+		t.Context(),
+		newFlakyMock("resilientr1", &calls, unavailable, unavailable),
+		WithRetry(3, time.Millisecond, 5*time.Millisecond, 0.5),
+	)
+	require.NoError(t, err)
+
+	before := r.GetCounterRetried().Value()
+
+	require.NoError(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	assert.Equal(t, int64(3), calls.Load())
+	assert.Equal(t, before+2, r.GetCounterRetried().Value())
+}
+
+func TestResilient_PermanentErrorsAreNotRetried(t *testing.T) {
+	var calls atomic.Int64
+
+	notFound := customerror.NewHTTPError(http.StatusNotFound)
+
+	r, err := NewResilient(
+		t.Context(),
+		newFlakyMock("resilientr2", &calls, notFound, notFound, notFound, notFound, notFound, notFound),
+		WithRetry(3, time.Millisecond, 5*time.Millisecond, 0),
+		WithBreaker(2, 1, time.Minute),
+	)
+	require.NoError(t, err)
+
+	for range 3 {
+		require.Error(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	}
+
+	// One call each, and the breaker never trips.
+	assert.Equal(t, int64(3), calls.Load())
+	assert.Equal(t, breaker.Closed, r.GetBreakerState())
+	assert.NoError(t, r.Health(t.Context()))
+}
+
+func TestResilient_BreakerOpensAndRecovers(t *testing.T) {
+	var calls atomic.Int64
+
+	timeout := customerror.NewHTTPError(http.StatusGatewayTimeout)
+
+	r, err := NewResilient(
+		t.Context(),
+		newFlakyMock("resilientr3", &calls, timeout, timeout),
+		WithRetry(0, time.Millisecond, time.Millisecond, 0),
+		WithBreaker(2, 1, 50*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	require.Error(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	require.Error(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+
+	assert.Equal(t, breaker.Open, r.GetBreakerState())
+	assert.Equal(t, int64(breaker.Open), r.GetGaugeBreakerState().Value())
+	assert.Error(t, Health(t.Context(), r))
+
+	// Fails fast, without hitting the backend.
+	before := r.GetCounterRejected().Value()
+
+	err = r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, breaker.ErrBreakerOpen))
+	assert.Equal(t, int64(2), calls.Load())
+	assert.Equal(t, before+1, r.GetCounterRejected().Value())
+
+	// Half-opens after the timeout, and closes on success.
+	require.Eventually(t, func() bool {
+		return r.GetBreakerState() != breaker.Open
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	assert.Equal(t, breaker.Closed, r.GetBreakerState())
+	assert.NoError(t, r.Health(t.Context()))
+}
+
+func TestResilient_PermanentErrorsKeepBreakerHalfOpen(t *testing.T) {
+	var calls atomic.Int64
+
+	timeout := customerror.NewHTTPError(http.StatusGatewayTimeout)
+	notFound := customerror.NewHTTPError(http.StatusNotFound)
+
+	r, err := NewResilient(
+		t.Context(),
+		newFlakyMock("resilientr6", &calls, timeout, notFound),
+		WithRetry(0, time.Millisecond, time.Millisecond, 0),
+		WithBreaker(1, 1, 50*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	require.Error(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	assert.Equal(t, breaker.Open, r.GetBreakerState())
+
+	require.Eventually(t, func() bool {
+		return r.GetBreakerState() == breaker.HalfOpen
+	}, time.Second, 10*time.Millisecond)
+
+	// A permanent error neither closes, nor re-opens it.
+	require.Error(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	assert.Equal(t, breaker.HalfOpen, r.GetBreakerState())
+
+	require.NoError(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	assert.Equal(t, breaker.Closed, r.GetBreakerState())
+}
+
+func TestResilient_CustomClassifier(t *testing.T) {
+	var calls atomic.Int64
+
+	errFlaky := errors.New("flaky")
+
+	r, err := NewResilient(
+		t.Context(),
+		newFlakyMock("resilientr4", &calls, errFlaky),
+		WithRetry(1, time.Millisecond, time.Millisecond, 0),
+		WithTransientClassifier(func(err error) bool { return errors.Is(err, errFlaky) }),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Retrieve(t.Context(), "1", "t", &TestDataS{}, nil))
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.False(t, IsTransientError(nil))
+	assert.False(t, IsTransientError(context.Canceled))
+	assert.False(t, IsTransientError(customerror.NewHTTPError(http.StatusNotFound)))
+	assert.False(t, IsTransientError(breaker.ErrBreakerOpen))
+
+	assert.True(t, IsTransientError(customerror.NewHTTPError(http.StatusTooManyRequests)))
+
+	// Nested within the error chain.
+	assert.True(t, IsTransientError(customerror.NewFailedToError(
+		"retrieve",
+		customerror.WithError(customerror.NewHTTPError(http.StatusServiceUnavailable)),
+	)))
+}
+
+func TestNewResilient_InvalidOptions(t *testing.T) {
+	_, err := NewResilient(t.Context(), nil)
+	assert.Error(t, err)
+
+	var calls atomic.Int64
+
+	m := newFlakyMock("resilientr5", &calls)
+
+	_, err = NewResilient(t.Context(), m, WithRetry(1, time.Second, time.Millisecond, 0))
+	assert.Error(t, err)
+
+	_, err = NewResilient(t.Context(), m, WithBreaker(1, 0, time.Second))
+	assert.Error(t, err)
+
+	_, err = NewResilient(t.Context(), m, WithTransientClassifier(nil))
+	assert.Error(t, err)
+}
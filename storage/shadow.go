@@ -0,0 +1,595 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/customapm"
+	"github.com/thalesfsp/dal/v2/internal/logging"
+	"github.com/thalesfsp/dal/v2/internal/metrics"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/count"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/list"
+	"github.com/thalesfsp/params/v2/retrieve"
+	"github.com/thalesfsp/params/v2/update"
+	"github.com/thalesfsp/status"
+	"github.com/thalesfsp/sypl/v2"
+	"github.com/thalesfsp/sypl/v2/fields"
+	"github.com/thalesfsp/sypl/v2/level"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// ShadowName is the name of the shadow storage.
+	ShadowName = "shadow"
+
+	// DefaultShadowTimeout bounds every operation against the secondary.
+	DefaultShadowTimeout = 30 * time.Second
+)
+
+// Mismatch describes a divergence between the primary and the shadow storage
+// for the same operation.
+type Mismatch struct {
+	// Operation which diverged.
+	Operation Operation `json:"operation"`
+
+	// ID of the document, if the operation addresses one.
+	ID string `json:"id,omitempty"`
+
+	// Target of the operation.
+	Target string `json:"target,omitempty"`
+
+	// Primary is the primary's result normalized to JSON.
+	Primary []byte `json:"primary,omitempty"`
+
+	// Shadow is the shadow's result normalized to JSON.
+	Shadow []byte `json:"shadow,omitempty"`
+
+	// PrimaryErr is the error returned by the primary, if any.
+	PrimaryErr error `json:"-"`
+
+	// ShadowErr is the error returned by the shadow, if any.
+	ShadowErr error `json:"-"`
+}
+
+// outcome is the outcome of an operation, with its result normalized to JSON.
+type outcome struct {
+	// result normalized to JSON, if the operation succeeded.
+	result []byte
+
+	// err returned by the operation.
+	err error
+
+	// normalizeErr is set if the result couldn't be normalized.
+	normalizeErr error
+}
+
+// MismatchFunc is called every time the primary and the shadow storage
+// disagree. It may be called concurrently.
+type MismatchFunc func(ctx context.Context, m *Mismatch)
+
+// Shadow is a storage which serves every operation from the `Primary` while
+// mirroring it against the `Secondary` - the storage being migrated to.
+//
+// Writes (create, update, delete) are applied to the primary and, only if they
+// succeed, replayed against the secondary. Reads (retrieve, list, count) are
+// issued against both; results are normalized to JSON and compared - errors
+// by kind, see KindOf. Divergences are reported through the `OnMismatch`
+// callback, logs, and metrics.
+//
+// NOTE: The caller only ever gets the primary's result (or error), as soon as
+// the primary returns. The secondary is called in the background, detached
+// from the caller's context, and bounded by `Timeout`. Its failures are
+// logged, and counted, but never surfaced. Writes are replayed in order, and
+// reads, and writes wait for the operations issued before them. Use `Wait` to drain in-flight
+// operations, e.g.: on shutdown.
+//
+// NOTE: Options are forwarded to both storages, so pre/post hooks run once
+// per storage. Use the `strg` argument to tell them apart.
+type Shadow struct {
+	*Storage
+
+	// Primary is the storage results are served from.
+	Primary IStorage `json:"-" validate:"required"`
+
+	// Secondary is the storage being shadowed.
+	Secondary IStorage `json:"-" validate:"required"`
+
+	// OnMismatch is called when results diverge. Optional.
+	OnMismatch MismatchFunc `json:"-"`
+
+	// Timeout bounds every operation against the secondary.
+	Timeout time.Duration `json:"timeout" validate:"gt=0"`
+
+	// wg tracks in-flight operations against the secondary.
+	wg sync.WaitGroup
+
+	// mu guards lastWrite, and reads.
+	mu sync.Mutex
+
+	// lastWrite is closed once the last replayed write is done.
+	lastWrite chan struct{}
+
+	// reads tracks the reads issued since the last write.
+	reads *sync.WaitGroup
+
+	// Metrics.
+	counterShadowMatched  *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterShadowMismatch *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterShadowFailed   *expvar.Int `json:"-" validate:"required,gte=0"`
+}
+
+//////
+// Helpers.
+//////
+
+// normalizeJSON converts `v` into a canonical JSON form: it's marshalled,
+// unmarshalled into a generic value, then marshalled again so keys are sorted
+// and type-specific encodings (struct field order, tags) don't matter.
+func normalizeJSON(v any) ([]byte, error) {
+	var generic any
+
+	if err := ParseToStruct(v, &generic); err != nil {
+		return nil, err
+	}
+
+	return shared.Marshal(generic)
+}
+
+// newOutcome returns the outcome of an operation which returned `v`, and
+// `err`.
+func newOutcome(v any, err error) outcome {
+	if err != nil {
+		return outcome{err: err}
+	}
+
+	b, nErr := normalizeJSON(v)
+
+	return outcome{result: b, normalizeErr: nErr}
+}
+
+// clone deep copies `v` through JSON, so the secondary isn't affected by the
+// caller reusing `v` once the call returns.
+func clone(v any) (any, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return v, nil
+	}
+
+	if t.Kind() == reflect.Pointer {
+		c := reflect.New(t.Elem())
+
+		if err := ParseToStruct(v, c.Interface()); err != nil {
+			return nil, err
+		}
+
+		return c.Interface(), nil
+	}
+
+	c := reflect.New(t)
+
+	if err := ParseToStruct(v, c.Interface()); err != nil {
+		return nil, err
+	}
+
+	return c.Elem().Interface(), nil
+}
+
+// newLike returns a new pointer of the same type `v` points to, so the shadow
+// decodes into its own destination. It falls back to a generic value.
+func newLike(v any) any {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return new(any)
+	}
+
+	return reflect.New(t.Elem()).Interface()
+}
+
+// compare compares the outcomes of an operation against both storages, and
+// reports any divergence.
+func (s *Shadow) compare(
+	ctx context.Context,
+	op Operation,
+	id, target string,
+	primary outcome,
+	shadow outcome,
+) {
+	for _, o := range []outcome{primary, shadow} {
+		if o.normalizeErr != nil {
+			s.shadowFailed(ctx, op, id, target, o.normalizeErr)
+
+			return
+		}
+	}
+
+	// Both failed the same way, e.g.: a "not found" on both sides agrees.
+	if primary.err != nil && shadow.err != nil &&
+		KindOf(primary.err, nil) == KindOf(shadow.err, nil) {
+		s.counterShadowMatched.Add(1)
+
+		return
+	}
+
+	if primary.err == nil && shadow.err == nil && bytes.Equal(primary.result, shadow.result) {
+		s.counterShadowMatched.Add(1)
+
+		return
+	}
+
+	m := &Mismatch{
+		Operation:  op,
+		ID:         id,
+		Target:     target,
+		Primary:    primary.result,
+		Shadow:     shadow.result,
+		PrimaryErr: primary.err,
+		ShadowErr:  shadow.err,
+	}
+
+	s.counterShadowMismatch.Add(1)
+
+	f := fields.Fields{
+		"operation": op.String(),
+		"id":        id,
+		"target":    target,
+		"primary":   string(m.Primary),
+		"shadow":    string(m.Shadow),
+	}
+
+	if m.PrimaryErr != nil {
+		f["primaryError"] = m.PrimaryErr.Error()
+	}
+
+	if m.ShadowErr != nil {
+		f["shadowError"] = m.ShadowErr.Error()
+	}
+
+	s.GetLogger().PrintlnWithOptions(
+		level.Warn,
+		"shadow mismatch",
+		sypl.WithFields(logging.ToAPM(ctx, f)),
+	)
+
+	if s.OnMismatch != nil {
+		s.OnMismatch(ctx, m)
+	}
+}
+
+// read runs `fn` against the secondary in the background, once the writes
+// issued so far are replayed.
+func (s *Shadow) read(ctx context.Context, fn func(ctx context.Context)) {
+	s.mu.Lock()
+	prev := s.lastWrite
+	reads := s.reads
+	reads.Add(1)
+	s.mu.Unlock()
+
+	s.wg.Go(func() {
+		defer reads.Done()
+
+		<-prev
+
+		s.run(ctx, fn)
+	})
+}
+
+// write runs `fn` against the secondary in the background, once the
+// operations issued so far are done.
+func (s *Shadow) write(ctx context.Context, fn func(ctx context.Context)) {
+	done := make(chan struct{})
+
+	s.mu.Lock()
+	prev := s.lastWrite
+	reads := s.reads
+	s.lastWrite = done
+	s.reads = &sync.WaitGroup{}
+	s.mu.Unlock()
+
+	s.wg.Go(func() {
+		defer close(done)
+
+		<-prev
+		reads.Wait()
+
+		s.run(ctx, fn)
+	})
+}
+
+// run runs `fn` with a context which isn't canceled with `ctx`, but is
+// bounded by `Timeout`.
+func (s *Shadow) run(ctx context.Context, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.Timeout)
+	defer cancel()
+
+	fn(ctx)
+}
+
+// replicate replays a write of `v` against the secondary through `fn`.
+func (s *Shadow) replicate(
+	ctx context.Context,
+	op Operation,
+	id, target string,
+	v any,
+	fn func(ctx context.Context, v any) error,
+) {
+	c, err := clone(v)
+	if err != nil {
+		s.shadowFailed(ctx, op, id, target, err)
+
+		return
+	}
+
+	s.write(ctx, func(ctx context.Context) {
+		if err := fn(ctx, c); err != nil {
+			s.shadowFailed(ctx, op, id, target, err)
+		}
+	})
+}
+
+// shadowFailed logs, and counts a failure on the secondary.
+func (s *Shadow) shadowFailed(ctx context.Context, op Operation, id, target string, err error) {
+	s.counterShadowFailed.Add(1)
+
+	s.GetLogger().PrintlnWithOptions(
+		level.Warn,
+		customerror.NewFailedToError("shadow "+op.String(), customerror.WithError(err)).Error(),
+		sypl.WithFields(logging.ToAPM(ctx, fields.Fields{
+			"operation": op.String(),
+			"id":        id,
+			"target":    target,
+		})),
+	)
+}
+
+//////
+// Implements the IStorage interface.
+//////
+
+// Count counts from both storages, returning the primary's count.
+func (s *Shadow) Count(ctx context.Context, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
+	ctx, span := customapm.Trace(ctx, s.GetType(), ShadowName, status.Counted.String())
+	defer span.End()
+
+	c, err := s.Primary.Count(ctx, target, prm, options...)
+
+	primary := newOutcome(c, err)
+
+	s.read(ctx, func(ctx context.Context) {
+		shadowC, shadowErr := s.Secondary.Count(ctx, target, prm, options...)
+
+		s.compare(ctx, OperationCount, "", target, primary, newOutcome(shadowC, shadowErr))
+	})
+
+	if err != nil {
+		return 0, customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCountedFailed())
+	}
+
+	s.GetCounterCounted().Add(1)
+
+	return c, nil
+}
+
+// Delete deletes from the primary, then from the secondary.
+func (s *Shadow) Delete(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+	ctx, span := customapm.Trace(ctx, s.GetType(), ShadowName, status.Deleted.String())
+	defer span.End()
+
+	if err := s.Primary.Delete(ctx, id, target, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterDeletedFailed())
+	}
+
+	s.write(ctx, func(ctx context.Context) {
+		if err := s.Secondary.Delete(ctx, id, target, prm, options...); err != nil {
+			s.shadowFailed(ctx, OperationDelete, id, target, err)
+		}
+	})
+
+	s.GetCounterDeleted().Add(1)
+
+	return nil
+}
+
+// Retrieve retrieves from both storages, `v` is filled with the primary's
+// document.
+func (s *Shadow) Retrieve(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+	ctx, span := customapm.Trace(ctx, s.GetType(), ShadowName, status.Retrieved.String())
+	defer span.End()
+
+	err := s.Primary.Retrieve(ctx, id, target, v, prm, options...)
+
+	primary := newOutcome(v, err)
+	shadowV := newLike(v)
+
+	s.read(ctx, func(ctx context.Context) {
+		shadowErr := s.Secondary.Retrieve(ctx, id, target, shadowV, prm, options...)
+
+		s.compare(ctx, OperationRetrieve, id, target, primary, newOutcome(shadowV, shadowErr))
+	})
+
+	if err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterRetrievedFailed())
+	}
+
+	s.GetCounterRetrieved().Add(1)
+
+	return nil
+}
+
+// List lists from both storages, `v` is filled with the primary's documents.
+//
+// NOTE: Results are compared as-is, set a `Sort` if storages don't guarantee
+// the same order.
+func (s *Shadow) List(ctx context.Context, target string, v any, prm *list.List, options ...Func[*list.List]) error {
+	ctx, span := customapm.Trace(ctx, s.GetType(), ShadowName, status.Listed.String())
+	defer span.End()
+
+	err := s.Primary.List(ctx, target, v, prm, options...)
+
+	primary := newOutcome(v, err)
+	shadowV := newLike(v)
+
+	s.read(ctx, func(ctx context.Context) {
+		shadowErr := s.Secondary.List(ctx, target, shadowV, prm, options...)
+
+		s.compare(ctx, OperationList, "", target, primary, newOutcome(shadowV, shadowErr))
+	})
+
+	if err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterListedFailed())
+	}
+
+	s.GetCounterListed().Add(1)
+
+	return nil
+}
+
+// Create creates into the primary, then into the secondary using the ID
+// returned by the primary.
+func (s *Shadow) Create(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+	ctx, span := customapm.Trace(ctx, s.GetType(), ShadowName, status.Created.String())
+	defer span.End()
+
+	returnedID, err := s.Primary.Create(ctx, id, target, v, prm, options...)
+	if err != nil {
+		return "", customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterCreatedFailed())
+	}
+
+	shadowID := returnedID
+	if shadowID == "" {
+		shadowID = id
+	}
+
+	s.replicate(ctx, OperationCreate, shadowID, target, v, func(ctx context.Context, v any) error {
+		_, err := s.Secondary.Create(ctx, shadowID, target, v, prm, options...)
+
+		return err
+	})
+
+	s.GetCounterCreated().Add(1)
+
+	return returnedID, nil
+}
+
+// Update updates the primary, then the secondary.
+func (s *Shadow) Update(ctx context.Context, id, target string, v any, prm *update.Update, options ...Func[*update.Update]) error {
+	ctx, span := customapm.Trace(ctx, s.GetType(), ShadowName, status.Updated.String())
+	defer span.End()
+
+	if err := s.Primary.Update(ctx, id, target, v, prm, options...); err != nil {
+		return customapm.TraceError(ctx, err, s.GetLogger(), s.GetCounterUpdatedFailed())
+	}
+
+	s.replicate(ctx, OperationUpdate, id, target, v, func(ctx context.Context, v any) error {
+		return s.Secondary.Update(ctx, id, target, v, prm, options...)
+	})
+
+	s.GetCounterUpdated().Add(1)
+
+	return nil
+}
+
+// Wait blocks until the in-flight operations against the secondary are done.
+func (s *Shadow) Wait() {
+	s.wg.Wait()
+}
+
+// GetClient returns the primary's client.
+func (s *Shadow) GetClient() any {
+	return s.Primary.GetClient()
+}
+
+// GetCounterShadowMatched returns the metric.
+func (s *Shadow) GetCounterShadowMatched() *expvar.Int {
+	return s.counterShadowMatched
+}
+
+// GetCounterShadowMismatch returns the metric.
+func (s *Shadow) GetCounterShadowMismatch() *expvar.Int {
+	return s.counterShadowMismatch
+}
+
+// GetCounterShadowFailed returns the metric.
+func (s *Shadow) GetCounterShadowFailed() *expvar.Int {
+	return s.counterShadowFailed
+}
+
+//////
+// Factory.
+//////
+
+// NewShadow returns a new Shadow storage serving from `primary`, and shadowing
+// `secondary`. `onMismatch` is optional.
+func NewShadow(ctx context.Context, primary, secondary IStorage, onMismatch MismatchFunc) (*Shadow, error) {
+	// Enforces IStorage interface implementation.
+	var _ IStorage = (*Shadow)(nil)
+
+	s, err := New(ctx, ShadowName)
+	if err != nil {
+		return nil, err
+	}
+
+	if primary == nil || secondary == nil {
+		return nil, customapm.TraceError(
+			ctx,
+			customerror.NewRequiredError("primary and secondary storages"),
+			s.GetLogger(),
+			s.counterInstantiationFailed,
+		)
+	}
+
+	prefix := fmt.Sprintf("%s.%s.%s.%s", Type, ShadowName, primary.GetName(), secondary.GetName())
+
+	shadow := &Shadow{
+		Storage: s,
+
+		Primary:    primary,
+		Secondary:  secondary,
+		OnMismatch: onMismatch,
+		Timeout:    DefaultShadowTimeout,
+
+		lastWrite: make(chan struct{}),
+		reads:     &sync.WaitGroup{},
+
+		counterShadowMatched:  metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "matched", DefaultMetricCounterLabel)),
+		counterShadowMismatch: metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, "mismatch", DefaultMetricCounterLabel)),
+		counterShadowFailed:   metrics.NewInt(fmt.Sprintf("%s.%s.%s", prefix, status.Failed, DefaultMetricCounterLabel)),
+	}
+
+	if err := validation.Validate(shadow); err != nil {
+		return nil, customapm.TraceError(ctx, err, s.GetLogger(), s.counterInstantiationFailed)
+	}
+
+	// Nothing to wait for.
+	close(shadow.lastWrite)
+
+	return shadow, nil
+}
+
+// NewShadowFromMap is like NewShadow but picks the primary, and the secondary
+// storages from `m` by name.
+func NewShadowFromMap(ctx context.Context, m Map, primary, secondary string, onMismatch MismatchFunc) (*Shadow, error) {
+	p, ok := m[primary]
+	if !ok {
+		return nil, customerror.NewMissingError("primary storage " + primary)
+	}
+
+	sc, ok := m[secondary]
+	if !ok {
+		return nil, customerror.NewMissingError("secondary storage " + secondary)
+	}
+
+	return NewShadow(ctx, p, sc, onMismatch)
+}
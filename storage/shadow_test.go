@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/params/v2/count"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/params/v2/delete"
+	"github.com/thalesfsp/params/v2/retrieve"
+	"github.com/thalesfsp/params/v2/update"
+)
+
+// newKVMock builds a Mock backed by an in-test map, so writes are observable
+// through reads.
+func newKVMock(name string) (*Mock, map[string]TestDataS) {
+	var mu sync.Mutex
+
+	data := map[string]TestDataS{}
+
+	return &Mock{
+		MockCount: func(ctx context.Context, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return int64(len(data)), nil
+		},
+		MockCreate: func(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			data[id] = *v.(*TestDataS)
+
+			return id, nil
+		},
+		MockDelete: func(ctx context.Context, id, target string, prm *delete.Delete, options ...Func[*delete.Delete]) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			// The params package shadows the builtin `delete`.
+			maps.DeleteFunc(data, func(k string, _ TestDataS) bool { return k == id })
+
+			return nil
+		},
+		MockRetrieve: func(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			d, ok := data[id]
+			if !ok {
+				return customerror.NewNotFoundError(id)
+			}
+
+			*v.(*TestDataS) = d
+
+			return nil
+		},
+		MockUpdate: func(ctx context.Context, id, target string, v any, prm *update.Update, options ...Func[*update.Update]) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			data[id] = *v.(*TestDataS)
+
+			return nil
+		},
+		MockGetName: func() string { return name },
+	}, data
+}
+
+// Writes go to both storages; reads agree so no mismatch is reported.
+func TestShadow_WritesMirroredAndReadsMatch(t *testing.T) {
+	ctx := t.Context()
+
+	primary, _ := newKVMock("shadowp1")
+	secondary, secondaryData := newKVMock("shadows1")
+
+	var mismatches []*Mismatch
+
+	s, err := NewShadow(ctx, primary, secondary, func(ctx context.Context, m *Mismatch) {
+		mismatches = append(mismatches, m)
+	})
+	require.NoError(t, err)
+
+	id, err := s.Create(ctx, "1", "t", &TestDataS{K: "v1"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "1", id)
+
+	require.NoError(t, s.Update(ctx, "1", "t", &TestDataS{K: "v2"}, nil))
+
+	s.Wait()
+	assert.Equal(t, "v2", secondaryData["1"].K)
+
+	var got TestDataS
+	require.NoError(t, s.Retrieve(ctx, "1", "t", &got, nil))
+	assert.Equal(t, "v2", got.K)
+
+	c, err := s.Count(ctx, "t", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	require.NoError(t, s.Delete(ctx, "1", "t", nil))
+
+	s.Wait()
+	assert.Empty(t, secondaryData)
+
+	assert.Empty(t, mismatches)
+	assert.Equal(t, int64(0), s.GetCounterShadowMismatch().Value())
+}
+
+// Divergent data is reported, but the caller only ever sees the primary's.
+func TestShadow_MismatchReportedPrimaryReturned(t *testing.T) {
+	ctx := t.Context()
+
+	primary, primaryData := newKVMock("shadowp2")
+	secondary, secondaryData := newKVMock("shadows2")
+
+	primaryData["1"] = TestDataS{K: "old"}
+	secondaryData["1"] = TestDataS{K: "new"}
+	primaryData["2"] = TestDataS{K: "only-primary"}
+
+	var mismatches []*Mismatch
+
+	s, err := NewShadow(ctx, primary, secondary, func(ctx context.Context, m *Mismatch) {
+		mismatches = append(mismatches, m)
+	})
+	require.NoError(t, err)
+
+	var got TestDataS
+	require.NoError(t, s.Retrieve(ctx, "1", "t", &got, nil))
+	assert.Equal(t, "old", got.K)
+
+	s.Wait()
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, OperationRetrieve, mismatches[0].Operation)
+	assert.JSONEq(t, `{"k":"old"}`, string(mismatches[0].Primary))
+	assert.JSONEq(t, `{"k":"new"}`, string(mismatches[0].Shadow))
+
+	// Missing on the shadow only is a mismatch, not an error.
+	require.NoError(t, s.Retrieve(ctx, "2", "t", &got, nil))
+
+	s.Wait()
+	require.Len(t, mismatches, 2)
+	assert.Error(t, mismatches[1].ShadowErr)
+
+	// Missing on both agrees.
+	assert.Error(t, s.Retrieve(ctx, "3", "t", &got, nil))
+
+	s.Wait()
+	assert.Len(t, mismatches, 2)
+
+	// Counts differ (2 vs 1).
+	c, err := s.Count(ctx, "t", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+
+	s.Wait()
+	assert.Len(t, mismatches, 3)
+}
+
+// Errors of different kinds are a mismatch.
+func TestShadow_ErrorKindsCompared(t *testing.T) {
+	ctx := t.Context()
+
+	primary, _ := newKVMock("shadowp5")
+	secondary := &Mock{
+		MockRetrieve: func(ctx context.Context, id, target string, v any, prm *retrieve.Retrieve, options ...Func[*retrieve.Retrieve]) error {
+			return customerror.NewFailedToError("retrieve", customerror.WithError(ErrUnavailable))
+		},
+		MockGetName: func() string { return "shadows5" },
+	}
+
+	var mismatches []*Mismatch
+
+	s, err := NewShadow(ctx, primary, secondary, func(ctx context.Context, m *Mismatch) {
+		mismatches = append(mismatches, m)
+	})
+	require.NoError(t, err)
+
+	var got TestDataS
+	assert.Error(t, s.Retrieve(ctx, "1", "t", &got, nil))
+
+	s.Wait()
+	require.Len(t, mismatches, 1)
+	assert.ErrorIs(t, mismatches[0].ShadowErr, ErrUnavailable)
+}
+
+// The caller never waits for the secondary, which is bounded by `Timeout`.
+func TestShadow_SecondaryDetached(t *testing.T) {
+	ctx := t.Context()
+
+	primary, _ := newKVMock("shadowp6")
+	secondary := &Mock{
+		MockCount: func(ctx context.Context, target string, prm *count.Count, options ...Func[*count.Count]) (int64, error) {
+			<-ctx.Done()
+
+			return 0, ctx.Err()
+		},
+		MockGetName: func() string { return "shadows6" },
+	}
+
+	s, err := NewShadow(ctx, primary, secondary, nil)
+	require.NoError(t, err)
+
+	s.Timeout = 50 * time.Millisecond
+
+	before := s.GetCounterShadowMismatch().Value()
+
+	cctx, cancel := context.WithCancel(ctx)
+
+	start := time.Now()
+
+	_, err = s.Count(cctx, "t", nil)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), s.Timeout)
+
+	// Canceling the caller's context doesn't cancel the secondary.
+	cancel()
+
+	s.Wait()
+	assert.GreaterOrEqual(t, time.Since(start), s.Timeout)
+	assert.Equal(t, before+1, s.GetCounterShadowMismatch().Value())
+}
+
+// A failing shadow write never fails the operation.
+func TestShadow_ShadowWriteFailureIsSwallowed(t *testing.T) {
+	ctx := t.Context()
+
+	primary, primaryData := newKVMock("shadowp3")
+	secondary := &Mock{
+		MockCreate: func(ctx context.Context, id, target string, v any, prm *create.Create, options ...Func[*create.Create]) (string, error) {
+			return "", customerror.NewFailedToError("create")
+		},
+		MockGetName: func() string { return "shadows3" },
+	}
+
+	s, err := NewShadow(ctx, primary, secondary, nil)
+	require.NoError(t, err)
+
+	before := s.GetCounterShadowFailed().Value()
+
+	_, err = s.Create(ctx, "1", "t", &TestDataS{K: "v"}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, primaryData, "1")
+
+	s.Wait()
+	assert.Equal(t, before+1, s.GetCounterShadowFailed().Value())
+}
+
+func TestNewShadowFromMap(t *testing.T) {
+	ctx := t.Context()
+
+	p, _ := newKVMock("shadowp4")
+	sc, _ := newKVMock("shadows4")
+
+	_, err := NewShadowFromMap(ctx, Map{"p": p, "s": sc}, "p", "s", nil)
+	require.NoError(t, err)
+
+	_, err = NewShadowFromMap(ctx, Map{"p": p}, "p", "s", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/dal/v2/internal/shared"
+	"github.com/thalesfsp/params/v2/create"
+	"github.com/thalesfsp/validation"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// DefaultWebhookAttempts is the default number of webhook deliveries.
+	DefaultWebhookAttempts = 3
+
+	// DefaultWebhookBackoff is the default wait before the first webhook
+	// redelivery, doubled for each one.
+	DefaultWebhookBackoff = 500 * time.Millisecond
+
+	// CloudEventsContentType is the content type of structured CloudEvents.
+	CloudEventsContentType = "application/cloudevents+json"
+)
+
+// ChannelSink delivers events to an in-process channel.
+type ChannelSink struct {
+	events chan *CloudEvent
+}
+
+// WebhookFunc allows to set webhook sink options.
+type WebhookFunc func(w *WebhookSink) error
+
+// WebhookSink POSTs events, as structured CloudEvents, to a URL. Network
+// errors, 429, and 5xx responses are retried with exponential backoff.
+type WebhookSink struct {
+	// URL events are POSTed to.
+	URL string `json:"url" validate:"required,url"`
+
+	// Client sending the requests.
+	Client *http.Client `json:"-" validate:"required"`
+
+	// Headers added to the requests, e.g.: authorization.
+	Headers http.Header `json:"-"`
+
+	// Attempts is the number of deliveries.
+	Attempts int `json:"attempts" validate:"gt=0"`
+
+	// Backoff is the wait before the first redelivery.
+	Backoff time.Duration `json:"backoff" validate:"gte=0"`
+}
+
+// FileSink appends events, one JSON per line, to a file.
+type FileSink struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+// StorageSink creates events, by ID, into a storage's target.
+//
+// NOTE: The storage must not emit through the emitter delivering to the sink,
+// otherwise each event emits another one.
+type StorageSink struct {
+	// Storage events are created into.
+	Storage IStorage
+
+	// Target events are created into.
+	Target string
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithWebhookClient sets the HTTP client. Default is `http.DefaultClient`.
+func WithWebhookClient(client *http.Client) WebhookFunc {
+	return func(w *WebhookSink) error {
+		w.Client = client
+
+		return nil
+	}
+}
+
+// WithWebhookHeader adds a header to the requests.
+func WithWebhookHeader(key, value string) WebhookFunc {
+	return func(w *WebhookSink) error {
+		w.Headers.Add(key, value)
+
+		return nil
+	}
+}
+
+// WithWebhookRetry sets the number of deliveries, and the wait before the first
+// redelivery. Defaults are `DefaultWebhookAttempts`, and
+// `DefaultWebhookBackoff`.
+func WithWebhookRetry(attempts int, backoff time.Duration) WebhookFunc {
+	return func(w *WebhookSink) error {
+		w.Attempts = attempts
+		w.Backoff = backoff
+
+		return nil
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// post delivers `body` once. Returns whether failures are worth retrying.
+func (w *WebhookSink) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, customerror.NewFailedToError("create webhook request", customerror.WithError(err))
+	}
+
+	for key, values := range w.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	req.Header.Set("Content-Type", CloudEventsContentType)
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return true, customerror.NewFailedToError("post webhook", customerror.WithError(err))
+	}
+
+	defer resp.Body.Close()
+
+	// Allows the connection to be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError,
+		customerror.NewFailedToError(
+			"post webhook",
+			customerror.WithStatusCode(resp.StatusCode),
+			customerror.WithError(fmt.Errorf("unexpected status %d", resp.StatusCode)),
+		)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Send delivers `event` to the channel, unless `ctx` is done first.
+func (c *ChannelSink) Send(ctx context.Context, event *CloudEvent) error {
+	select {
+	case c.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Events returns the channel events are delivered to.
+func (c *ChannelSink) Events() <-chan *CloudEvent {
+	return c.events
+}
+
+// Send POSTs `event`, retrying transient failures.
+func (w *WebhookSink) Send(ctx context.Context, event *CloudEvent) error {
+	body, err := shared.Marshal(event)
+	if err != nil {
+		return customerror.NewFailedToError("marshal event", customerror.WithError(err))
+	}
+
+	wait := w.Backoff
+
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil || !retry || attempt >= w.Attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+	}
+}
+
+// Send appends `event` to the file.
+func (f *FileSink) Send(_ context.Context, event *CloudEvent) error {
+	b, err := shared.Marshal(event)
+	if err != nil {
+		return customerror.NewFailedToError("marshal event", customerror.WithError(err))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.file.Write(append(b, '\n')); err != nil {
+		return customerror.NewFailedToError("append event", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+// Send creates `event` into the storage.
+func (s *StorageSink) Send(ctx context.Context, event *CloudEvent) error {
+	_, err := s.Storage.Create(ctx, event.ID, s.Target, event, &create.Create{})
+
+	return err
+}
+
+//////
+// Factory.
+//////
+
+// NewChannelSink returns a new ChannelSink with a channel of `size`. Events
+// must be consumed (`Events`), or deliveries time out.
+func NewChannelSink(size int) *ChannelSink {
+	return &ChannelSink{events: make(chan *CloudEvent, size)}
+}
+
+// NewWebhookSink returns a new WebhookSink POSTing to `url`.
+func NewWebhookSink(url string, options ...WebhookFunc) (*WebhookSink, error) {
+	w := &WebhookSink{
+		URL:      url,
+		Client:   http.DefaultClient,
+		Headers:  http.Header{},
+		Attempts: DefaultWebhookAttempts,
+		Backoff:  DefaultWebhookBackoff,
+	}
+
+	for _, option := range options {
+		if err := option(w); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validation.Validate(w); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// NewFileSink returns a new FileSink appending to `path`, created if it
+// doesn't exist. Call `Close` once done.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, customerror.NewFailedToError("open event file", customerror.WithError(err))
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// NewStorageSink returns a new StorageSink creating events into `target` of
+// `s`.
+func NewStorageSink(s IStorage, target string) (*StorageSink, error) {
+	if s == nil {
+		return nil, customerror.NewRequiredError("storage")
+	}
+
+	return &StorageSink{Storage: s, Target: target}, nil
+}
@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"expvar"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/dal/v2/tracing"
+	"github.com/thalesfsp/sypl/v2"
+	"github.com/thalesfsp/sypl/v2/fields"
+	"github.com/thalesfsp/sypl/v2/level"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// EnvSlowThreshold is the prefix of the env vars setting slow operation
+// thresholds, as Go durations, from the least to the most specific:
+//
+//   - `DAL_SLOW_THRESHOLD`: every storage, and operation
+//   - `DAL_SLOW_THRESHOLD_{OPERATION}`: an operation of every storage, e.g.:
+//     `DAL_SLOW_THRESHOLD_LIST=500ms`
+//   - `DAL_SLOW_THRESHOLD_{STORAGE}`: every operation of a storage, e.g.:
+//     `DAL_SLOW_THRESHOLD_POSTGRES=200ms`
+//   - `DAL_SLOW_THRESHOLD_{STORAGE}_{OPERATION}`: e.g.:
+//     `DAL_SLOW_THRESHOLD_POSTGRES_LIST=1s`.
+//
+// They're read once, on the first lookup. Thresholds set with
+// `SetSlowThreshold` take precedence.
+const EnvSlowThreshold = "DAL_SLOW_THRESHOLD"
+
+// AnyStorage, and AnyOperation match every storage, and every operation in
+// `SetSlowThreshold`.
+const (
+	AnyStorage   = ""
+	AnyOperation = Operation("")
+)
+
+// slowKey identifies a threshold.
+type slowKey struct {
+	storage   string
+	operation Operation
+
+	// env is set for the thresholds from the environment.
+	env bool
+}
+
+// slowCounter is implemented by storages which count slow operations, e.g.:
+// the ones embedding `Storage`.
+type slowCounter interface {
+	GetCounterSlow() *expvar.Int
+}
+
+// slowThresholds are the thresholds set in code, and from the environment.
+var (
+	slowThresholds   = map[slowKey]time.Duration{}
+	slowThresholdsMu sync.RWMutex
+
+	// slowThresholdsEnvOnce loads the thresholds from the environment once.
+	slowThresholdsEnvOnce sync.Once
+)
+
+// operations are the operations, to tell them from storage names in env vars.
+var operations = []Operation{
+	OperationCount,
+	OperationCreate,
+	OperationDelete,
+	OperationList,
+	OperationRetrieve,
+	OperationUpdate,
+}
+
+//////
+// Helpers.
+//////
+
+// envSlowKey returns the key of the threshold set by the `name` env var, if
+// it's one of `EnvSlowThreshold`.
+func envSlowKey(name string) (slowKey, bool) {
+	key := slowKey{env: true}
+
+	if name == EnvSlowThreshold {
+		return key, true
+	}
+
+	suffix, ok := strings.CutPrefix(name, EnvSlowThreshold+"_")
+	if !ok || suffix == "" {
+		return slowKey{}, false
+	}
+
+	suffix = strings.ToLower(suffix)
+
+	if slices.Contains(operations, Operation(suffix)) {
+		key.operation = Operation(suffix)
+
+		return key, true
+	}
+
+	if i := strings.LastIndex(suffix, "_"); i > 0 && slices.Contains(operations, Operation(suffix[i+1:])) {
+		key.storage = suffix[:i]
+		key.operation = Operation(suffix[i+1:])
+
+		return key, true
+	}
+
+	key.storage = suffix
+
+	return key, true
+}
+
+// loadEnvSlowThresholds replaces the thresholds from the environment with the
+// valid ones currently set.
+func loadEnvSlowThresholds() {
+	slowThresholdsMu.Lock()
+	defer slowThresholdsMu.Unlock()
+
+	for key := range slowThresholds {
+		if key.env {
+			delete(slowThresholds, key)
+		}
+	}
+
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+
+		key, ok := envSlowKey(name)
+		if !ok {
+			continue
+		}
+
+		threshold, err := time.ParseDuration(value)
+		if err != nil || threshold <= 0 {
+			continue
+		}
+
+		slowThresholds[key] = threshold
+	}
+}
+
+// logSlowOperation logs, and counts the operation if it's slower than its
+// threshold.
+func (o Observation) logSlowOperation(duration time.Duration, err error) {
+	threshold := SlowThreshold(o.name, o.operation)
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	if counter, ok := o.storage.(slowCounter); ok && counter.GetCounterSlow() != nil {
+		counter.GetCounterSlow().Add(1)
+	}
+
+	f := fields.Fields{
+		"operation": o.operation.String(),
+		"target":    o.target,
+		"id":        o.id,
+		"duration":  duration.String(),
+		"threshold": threshold.String(),
+		"outcome":   string(OutcomeSuccess),
+	}
+
+	if err != nil {
+		f["outcome"] = string(OutcomeFailed)
+	}
+
+	if o.record != nil {
+		for k, v := range o.record.Fields() {
+			f[k] = v
+		}
+
+		db := o.record.Database()
+
+		if db.Statement != "" {
+			f["query"] = db.Statement
+
+			if tracing.Redaction() {
+				f["query"] = tracing.Redact(db.System, db.Statement)
+			}
+		}
+	}
+
+	o.storage.GetLogger().PrintlnWithOptions(level.Warn, "slow "+o.operation.String(), sypl.WithFields(f))
+}
+
+//////
+// Exported functionalities.
+//////
+
+// SetSlowThreshold sets the duration above which an operation of a storage is
+// logged at warn level - with its target, id, native query, duration, and
+// trace fields - and counted (`GetCounterSlow`). Use `AnyStorage`, and
+// `AnyOperation` as wildcards. A zero threshold removes it. See
+// `EnvSlowThreshold` to set thresholds from the environment.
+func SetSlowThreshold(storageName string, op Operation, threshold time.Duration) {
+	slowThresholdsMu.Lock()
+	defer slowThresholdsMu.Unlock()
+
+	key := slowKey{storage: storageName, operation: op}
+
+	if threshold <= 0 {
+		delete(slowThresholds, key)
+
+		return
+	}
+
+	slowThresholds[key] = threshold
+}
+
+// SlowThreshold returns the threshold of an operation of a storage, the most
+// specific one - set in code, then from the environment - winning. Zero if
+// there's none.
+func SlowThreshold(storageName string, op Operation) time.Duration {
+	slowThresholdsEnvOnce.Do(loadEnvSlowThresholds)
+
+	keys := [...]slowKey{
+		{storage: storageName, operation: op},
+		{storage: storageName, operation: AnyOperation},
+		{storage: AnyStorage, operation: op},
+		{storage: AnyStorage, operation: AnyOperation},
+	}
+
+	slowThresholdsMu.RLock()
+	defer slowThresholdsMu.RUnlock()
+
+	for _, key := range keys {
+		if threshold, ok := slowThresholds[key]; ok {
+			return threshold
+		}
+	}
+
+	// Env var names are case-insensitive.
+	for _, key := range keys {
+		key.storage = strings.ToLower(key.storage)
+		key.env = true
+
+		if threshold, ok := slowThresholds[key]; ok {
+			return threshold
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"encoding/json"
+	"expvar"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thalesfsp/dal/v2/internal/customapm"
+	"github.com/thalesfsp/dal/v2/tracing"
+	"github.com/thalesfsp/sypl/v2"
+	"github.com/thalesfsp/sypl/v2/formatter"
+	"github.com/thalesfsp/sypl/v2/level"
+	"github.com/thalesfsp/sypl/v2/output"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// slowMock is a storage which counts slow operations.
+type slowMock struct {
+	*Mock
+
+	counterSlow *expvar.Int
+}
+
+func (s *slowMock) GetCounterSlow() *expvar.Int {
+	return s.counterSlow
+}
+
+func TestSlowThreshold(t *testing.T) {
+	// Runs once the env vars are restored.
+	t.Cleanup(func() {
+		SetSlowThreshold(AnyStorage, AnyOperation, 0)
+		SetSlowThreshold("postgres", OperationList, 0)
+
+		loadEnvSlowThresholds()
+	})
+
+	assert.Zero(t, SlowThreshold("postgres", OperationList))
+
+	// From the environment, the most specific winning.
+	t.Setenv(EnvSlowThreshold, "1s")
+	t.Setenv(EnvSlowThreshold+"_LIST", "500ms")
+	t.Setenv(EnvSlowThreshold+"_POSTGRES", "200ms")
+	t.Setenv(EnvSlowThreshold+"_POSTGRES_LIST", "100ms")
+	t.Setenv(EnvSlowThreshold+"_MONGODB", "invalid")
+	t.Setenv(EnvSlowThreshold+"_MY_STORE_COUNT", "50ms")
+
+	// Read once, reloaded.
+	assert.Zero(t, SlowThreshold("postgres", OperationList))
+
+	loadEnvSlowThresholds()
+
+	assert.Equal(t, 100*time.Millisecond, SlowThreshold("postgres", OperationList))
+	assert.Equal(t, 200*time.Millisecond, SlowThreshold("postgres", OperationCount))
+	assert.Equal(t, 500*time.Millisecond, SlowThreshold("redis", OperationList))
+	assert.Equal(t, time.Second, SlowThreshold("mongodb", OperationCount))
+	assert.Equal(t, 50*time.Millisecond, SlowThreshold("my_store", OperationCount))
+	assert.Equal(t, 500*time.Millisecond, SlowThreshold("my_store", OperationList))
+
+	// Set in code, taking precedence.
+	SetSlowThreshold(AnyStorage, AnyOperation, 2*time.Second)
+	assert.Equal(t, 2*time.Second, SlowThreshold("postgres", OperationList))
+
+	SetSlowThreshold("postgres", OperationList, 3*time.Second)
+	assert.Equal(t, 3*time.Second, SlowThreshold("postgres", OperationList))
+	assert.Equal(t, 2*time.Second, SlowThreshold("postgres", OperationCount))
+}
+
+func TestObservation_SlowOperation(t *testing.T) {
+	tracing.Set(tracing.NewOTel(tracing.WithTracerProvider(sdktrace.NewTracerProvider())))
+
+	t.Cleanup(func() {
+		tracing.Set(nil)
+		SetSlowThreshold("slowmock", OperationList, 0)
+	})
+
+	buf, out := output.SafeBuffer(level.Trace)
+	out.SetFormatter(formatter.JSON())
+
+	s := &slowMock{
+		Mock: &Mock{
+			MockGetName:   func() string { return "slowmock" },
+			MockGetLogger: func() sypl.ISypl { return sypl.New("test", out) },
+		},
+		counterSlow: new(expvar.Int),
+	}
+
+	// run runs a traced, observed list.
+	run := func() {
+		ctx, o := Observe(t.Context(), s, OperationList, "users", "")
+
+		_, span := customapm.Trace(ctx, Type, "slowmock", "listed")
+		span.SetDatabase(tracing.Database{Statement: `SELECT * FROM "users"`})
+		span.End()
+
+		time.Sleep(2 * time.Millisecond)
+
+		require.NoError(t, o.Done(nil, nil, nil))
+	}
+
+	// No threshold.
+	run()
+	assert.Zero(t, s.counterSlow.Value())
+	assert.Empty(t, buf.String())
+
+	SetSlowThreshold("slowmock", OperationList, time.Millisecond)
+
+	run()
+	assert.Equal(t, int64(1), s.counterSlow.Value())
+
+	var logged map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &logged))
+
+	assert.Equal(t, "warn", logged["level"])
+	assert.Equal(t, "slow list", logged["message"])
+	assert.Equal(t, "users", logged["target"])
+	assert.Equal(t, `SELECT * FROM "users"`, logged["query"])
+	assert.Equal(t, "1ms", logged["threshold"])
+	assert.NotEmpty(t, logged["duration"])
+	assert.NotEmpty(t, logged["trace.id"])
+}
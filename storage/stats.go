@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"expvar"
+	"net/http"
+	"sort"
+
+	"github.com/thalesfsp/dal/v2/internal/shared"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// instantiationCounter is implemented by storages which count instantiation
+// failures, e.g.: the ones embedding `Storage`.
+type instantiationCounter interface {
+	GetCounterInstantiationFailed() *expvar.Int
+}
+
+// OperationStats are the stats of an operation.
+type OperationStats struct {
+	// Succeeded is the number of successful operations.
+	Succeeded int64 `json:"succeeded"`
+
+	// Failed is the number of failed operations.
+	Failed int64 `json:"failed"`
+
+	// FailureRatio is `Failed` over all operations, 0 if there's none.
+	FailureRatio float64 `json:"failureRatio"`
+}
+
+// StatsSnapshot is a snapshot of a storage's counters.
+type StatsSnapshot struct {
+	// Name of the storage.
+	Name string `json:"name,omitempty"`
+
+	Count    OperationStats `json:"count"`
+	Create   OperationStats `json:"create"`
+	Delete   OperationStats `json:"delete"`
+	List     OperationStats `json:"list"`
+	Retrieve OperationStats `json:"retrieve"`
+	Update   OperationStats `json:"update"`
+
+	// Total sums all operations.
+	Total OperationStats `json:"total"`
+
+	// PingFailed is the number of failed pings.
+	PingFailed int64 `json:"pingFailed"`
+
+	// InstantiationFailed is the number of failed instantiations.
+	InstantiationFailed int64 `json:"instantiationFailed"`
+
+	// Slow is the number of operations slower than their threshold. See
+	// `SetSlowThreshold`.
+	Slow int64 `json:"slow"`
+}
+
+// MapStats are the stats of every storage in a `Map`, and their aggregate.
+type MapStats struct {
+	// Storages is the snapshot of each storage, by name.
+	Storages map[string]StatsSnapshot `json:"storages"`
+
+	// Total aggregates every storage.
+	Total StatsSnapshot `json:"total"`
+}
+
+//////
+// Helpers.
+//////
+
+// value returns the counter's value, 0 if nil.
+func value(counter *expvar.Int) int64 {
+	if counter == nil {
+		return 0
+	}
+
+	return counter.Value()
+}
+
+// reset zeroes the counters, skipping nil ones.
+func reset(counters ...*expvar.Int) {
+	for _, counter := range counters {
+		if counter != nil {
+			counter.Set(0)
+		}
+	}
+}
+
+// newOperationStats returns the stats of an operation.
+func newOperationStats(succeeded, failed int64) OperationStats {
+	o := OperationStats{Succeeded: succeeded, Failed: failed}
+
+	if total := succeeded + failed; total > 0 {
+		o.FailureRatio = float64(failed) / float64(total)
+	}
+
+	return o
+}
+
+// add sums `o`, and `other`, recomputing the failure ratio.
+func (o OperationStats) add(other OperationStats) OperationStats {
+	return newOperationStats(o.Succeeded+other.Succeeded, o.Failed+other.Failed)
+}
+
+// add sums `s`, and `other`.
+func (s StatsSnapshot) add(other StatsSnapshot) StatsSnapshot {
+	return StatsSnapshot{
+		Name:                s.Name,
+		Count:               s.Count.add(other.Count),
+		Create:              s.Create.add(other.Create),
+		Delete:              s.Delete.add(other.Delete),
+		List:                s.List.add(other.List),
+		Retrieve:            s.Retrieve.add(other.Retrieve),
+		Update:              s.Update.add(other.Update),
+		Total:               s.Total.add(other.Total),
+		PingFailed:          s.PingFailed + other.PingFailed,
+		InstantiationFailed: s.InstantiationFailed + other.InstantiationFailed,
+		Slow:                s.Slow + other.Slow,
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Stats returns a snapshot of `s`'s counters.
+func Stats(s IStorage) StatsSnapshot {
+	snapshot := StatsSnapshot{
+		Name:       s.GetName(),
+		Count:      newOperationStats(value(s.GetCounterCounted()), value(s.GetCounterCountedFailed())),
+		Create:     newOperationStats(value(s.GetCounterCreated()), value(s.GetCounterCreatedFailed())),
+		Delete:     newOperationStats(value(s.GetCounterDeleted()), value(s.GetCounterDeletedFailed())),
+		List:       newOperationStats(value(s.GetCounterListed()), value(s.GetCounterListedFailed())),
+		Retrieve:   newOperationStats(value(s.GetCounterRetrieved()), value(s.GetCounterRetrievedFailed())),
+		Update:     newOperationStats(value(s.GetCounterUpdated()), value(s.GetCounterUpdatedFailed())),
+		PingFailed: value(s.GetCounterPingFailed()),
+	}
+
+	for _, o := range []OperationStats{
+		snapshot.Count,
+		snapshot.Create,
+		snapshot.Delete,
+		snapshot.List,
+		snapshot.Retrieve,
+		snapshot.Update,
+	} {
+		snapshot.Total = snapshot.Total.add(o)
+	}
+
+	if counter, ok := s.(instantiationCounter); ok {
+		snapshot.InstantiationFailed = value(counter.GetCounterInstantiationFailed())
+	}
+
+	if counter, ok := s.(slowCounter); ok {
+		snapshot.Slow = value(counter.GetCounterSlow())
+	}
+
+	return snapshot
+}
+
+// ResetStats zeroes `s`'s counters, e.g.: between tests.
+//
+// NOTE: Counters are shared by storages with the same name.
+func ResetStats(s IStorage) {
+	reset(
+		s.GetCounterCounted(),
+		s.GetCounterCountedFailed(),
+		s.GetCounterCreated(),
+		s.GetCounterCreatedFailed(),
+		s.GetCounterDeleted(),
+		s.GetCounterDeletedFailed(),
+		s.GetCounterListed(),
+		s.GetCounterListedFailed(),
+		s.GetCounterRetrieved(),
+		s.GetCounterRetrievedFailed(),
+		s.GetCounterUpdated(),
+		s.GetCounterUpdatedFailed(),
+		s.GetCounterPingFailed(),
+	)
+
+	if counter, ok := s.(instantiationCounter); ok {
+		reset(counter.GetCounterInstantiationFailed())
+	}
+
+	if counter, ok := s.(slowCounter); ok {
+		reset(counter.GetCounterSlow())
+	}
+}
+
+// Stats returns a snapshot of every storage's counters, and their aggregate.
+func (m Map) Stats() MapStats {
+	stats := MapStats{Storages: make(map[string]StatsSnapshot, len(m))}
+
+	names := make([]string, 0, len(m))
+
+	for name := range m {
+		names = append(names, name)
+	}
+
+	// Sums in a stable order, so ratios are deterministic.
+	sort.Strings(names)
+
+	for _, name := range names {
+		snapshot := Stats(m[name])
+
+		stats.Storages[name] = snapshot
+		stats.Total = stats.Total.add(snapshot)
+	}
+
+	return stats
+}
+
+// ResetStats zeroes the counters of every storage. See `ResetStats`.
+func (m Map) ResetStats() {
+	for _, s := range m {
+		ResetStats(s)
+	}
+}
+
+// StatsHandler serves `m.Stats()` as JSON.
+func StatsHandler(m Map) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := shared.Encode(w, m.Stats()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
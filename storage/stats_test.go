@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStatsMock returns a Mock counting through a new Storage.
+func newStatsMock(t *testing.T, name string) (*Mock, *Storage) {
+	t.Helper()
+
+	s, err := New(t.Context(), name)
+	require.NoError(t, err)
+
+	return &Mock{
+		MockGetName:                       s.GetName,
+		MockGetCounterCounted:             s.GetCounterCounted,
+		MockGetCounterCountedFailed:       s.GetCounterCountedFailed,
+		MockGetCounterCreated:             s.GetCounterCreated,
+		MockGetCounterCreatedFailed:       s.GetCounterCreatedFailed,
+		MockGetCounterDeleted:             s.GetCounterDeleted,
+		MockGetCounterDeletedFailed:       s.GetCounterDeletedFailed,
+		MockGetCounterListed:              s.GetCounterListed,
+		MockGetCounterListedFailed:        s.GetCounterListedFailed,
+		MockGetCounterRetrieved:           s.GetCounterRetrieved,
+		MockGetCounterRetrievedFailed:     s.GetCounterRetrievedFailed,
+		MockGetCounterUpdated:             s.GetCounterUpdated,
+		MockGetCounterUpdatedFailed:       s.GetCounterUpdatedFailed,
+		MockGetCounterPingFailed:          s.GetCounterPingFailed,
+		MockGetCounterInstantiationFailed: s.GetCounterInstantiationFailed,
+		MockGetCounterSlow:                s.GetCounterSlow,
+	}, s
+}
+
+func TestStats(t *testing.T) {
+	m, s := newStatsMock(t, "statstest")
+
+	ResetStats(m)
+
+	s.GetCounterCreated().Add(3)
+	s.GetCounterCreatedFailed().Add(1)
+	s.GetCounterRetrieved().Add(4)
+	s.GetCounterPingFailed().Add(2)
+	s.GetCounterInstantiationFailed().Add(1)
+	s.GetCounterSlow().Add(5)
+
+	snapshot := Stats(m)
+
+	assert.Equal(t, "statstest", snapshot.Name)
+	assert.Equal(t, OperationStats{Succeeded: 3, Failed: 1, FailureRatio: 0.25}, snapshot.Create)
+	assert.Equal(t, OperationStats{Succeeded: 4}, snapshot.Retrieve)
+	assert.Equal(t, OperationStats{}, snapshot.Delete)
+	assert.Equal(t, OperationStats{Succeeded: 7, Failed: 1, FailureRatio: 0.125}, snapshot.Total)
+	assert.Equal(t, int64(2), snapshot.PingFailed)
+	assert.Equal(t, int64(1), snapshot.InstantiationFailed)
+	assert.Equal(t, int64(5), snapshot.Slow)
+
+	ResetStats(m)
+	assert.Equal(t, StatsSnapshot{Name: "statstest"}, Stats(m))
+
+	// Storages without counters.
+	assert.Equal(t, StatsSnapshot{Name: "mock"}, Stats(&Mock{MockGetName: func() string { return "mock" }}))
+}
+
+func TestMap_StatsHandler(t *testing.T) {
+	m1, s1 := newStatsMock(t, "statstest1")
+	m2, s2 := newStatsMock(t, "statstest2")
+
+	m := Map{"s1": m1, "s2": m2}
+	m.ResetStats()
+
+	s1.GetCounterListed().Add(1)
+	s2.GetCounterListedFailed().Add(1)
+
+	rec := httptest.NewRecorder()
+	StatsHandler(m).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var stats MapStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+
+	assert.Equal(t, int64(1), stats.Storages["s1"].List.Succeeded)
+	assert.Equal(t, int64(1), stats.Storages["s2"].List.Failed)
+	assert.Equal(t, OperationStats{Succeeded: 1, Failed: 1, FailureRatio: 0.5}, stats.Total.List)
+
+	m.ResetStats()
+	assert.Equal(t, OperationStats{}, m.Stats().Total.Total)
+}
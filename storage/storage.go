@@ -4,6 +4,7 @@ import (
 	"context"
 	"expvar"
 	"fmt"
+	"log/slog"
 
 	"github.com/thalesfsp/dal/v2/internal/customapm"
 	"github.com/thalesfsp/dal/v2/internal/logging"
@@ -25,6 +26,9 @@ const (
 	Type                      = "storage"
 )
 
+// ConfigFunc allows to set storage options, e.g.: `WithLogger`.
+type ConfigFunc func(s *Storage) error
+
 // Storage definition.
 type Storage struct {
 	// Logger.
@@ -46,8 +50,17 @@ type Storage struct {
 	counterPingFailed          *expvar.Int `json:"-" validate:"required,gte=0"`
 	counterRetrieved           *expvar.Int `json:"-" validate:"required,gte=0"`
 	counterRetrievedFailed     *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterSlow                *expvar.Int `json:"-" validate:"required,gte=0"`
 	counterUpdate              *expvar.Int `json:"-" validate:"required,gte=0"`
 	counterUpdateFailed        *expvar.Int `json:"-" validate:"required,gte=0"`
+
+	// Logging.
+	logFieldNames  map[string]string
+	loggerInjected bool
+	slogLogger     *slog.Logger
+
+	// emitter emits mutation events. See `WithEmitter`.
+	emitter *Emitter
 }
 
 //////
@@ -109,11 +122,27 @@ func (s *Storage) GetCounterListedFailed() *expvar.Int {
 	return s.counterListedFailed
 }
 
+// GetCounterInstantiationFailed returns the metric.
+func (s *Storage) GetCounterInstantiationFailed() *expvar.Int {
+	return s.counterInstantiationFailed
+}
+
 // GetCounterPingFailed returns the metric.
 func (s *Storage) GetCounterPingFailed() *expvar.Int {
 	return s.counterPingFailed
 }
 
+// GetEmitter returns the mutation event emitter, if any. See `WithEmitter`.
+func (s *Storage) GetEmitter() *Emitter {
+	return s.emitter
+}
+
+// GetCounterSlow returns the metric of operations slower than their
+// threshold. See `SetSlowThreshold`.
+func (s *Storage) GetCounterSlow() *expvar.Int {
+	return s.counterSlow
+}
+
 // GetCounterCreated returns the metric.
 func (s *Storage) GetCounterCreated() *expvar.Int {
 	return s.counterCreated
@@ -138,8 +167,9 @@ func (s *Storage) GetCounterUpdatedFailed() *expvar.Int {
 // Factory.
 //////
 
-// New returns a new Storage.
-func New(ctx context.Context, name string) (*Storage, error) {
+// New returns a new Storage. By default, it logs through the dal logger,
+// use `WithLogger`, or `WithSlog` to log elsewhere.
+func New(ctx context.Context, name string, options ...ConfigFunc) (*Storage, error) {
 	// Storage's individual logger.
 	logger := logging.Get().New(name).SetTags(Type, name)
 
@@ -159,13 +189,24 @@ func New(ctx context.Context, name string) (*Storage, error) {
 		counterPingFailed:          metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, name, "ping."+status.Failed, DefaultMetricCounterLabel)),
 		counterRetrieved:           metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, name, status.Retrieved, DefaultMetricCounterLabel)),
 		counterRetrievedFailed:     metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, name, status.Retrieved+"."+status.Failed, DefaultMetricCounterLabel)),
+		counterSlow:                metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, name, "slow", DefaultMetricCounterLabel)),
 		counterUpdate:              metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, name, status.Updated, DefaultMetricCounterLabel)),
 		counterUpdateFailed:        metrics.NewInt(fmt.Sprintf("%s.%s.%s.%s", Type, name, status.Updated+"."+status.Failed, DefaultMetricCounterLabel)),
 	}
 
+	for _, option := range options {
+		if err := option(a); err != nil {
+			return nil, customapm.TraceError(ctx, err, logger, a.counterInstantiationFailed)
+		}
+	}
+
+	if err := a.setupLogger(); err != nil {
+		return nil, customapm.TraceError(ctx, err, logger, a.counterInstantiationFailed)
+	}
+
 	// Validate the storage.
 	if err := validation.Validate(a); err != nil {
-		return nil, customapm.TraceError(ctx, err, logger, a.counterInstantiationFailed)
+		return nil, customapm.TraceError(ctx, err, a.GetLogger(), a.counterInstantiationFailed)
 	}
 
 	a.GetLogger().PrintlnWithOptions(level.Debug, status.Created.String())
@@ -0,0 +1,385 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+const (
+	// DefaultPollInterval is the default interval of polling watchers.
+	DefaultPollInterval = time.Second
+
+	// DefaultWatchBuffer is the default size of the events channel.
+	DefaultWatchBuffer = 64
+)
+
+// ChangeEvent describes a change to a document.
+type ChangeEvent struct {
+	// Operation is one of `create`, `update`, or `delete`.
+	Operation Operation `json:"operation"`
+
+	// ID of the changed document.
+	ID string `json:"id"`
+
+	// Target of the change.
+	Target string `json:"target"`
+
+	// Document is the new document, if requested (`WithFullDocument`), and
+	// available. Empty for deletions.
+	Document json.RawMessage `json:"document,omitempty"`
+
+	// ResumeToken allows to resume watching after this event
+	// (`WithResumeToken`), if the storage supports it.
+	ResumeToken string `json:"resumeToken,omitempty"`
+
+	// Timestamp of the change, or of its detection.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Err is set if watching failed. Unless stated otherwise by the storage,
+	// the channel is closed right after.
+	Err error `json:"-"`
+}
+
+// IWatcher is implemented by storages which can stream changes.
+type IWatcher interface {
+	// Watch streams the changes to `target` until `ctx` is done, then closes
+	// the channel.
+	Watch(ctx context.Context, target string, options ...WatchFunc) <-chan ChangeEvent
+}
+
+// WatchOptions are the watch options.
+type WatchOptions struct {
+	// Buffer is the size of the events channel.
+	Buffer int `json:"buffer" validate:"gte=0"`
+
+	// FullDocument includes the new document in events.
+	FullDocument bool `json:"fullDocument"`
+
+	// PollInterval is the interval of polling watchers.
+	PollInterval time.Duration `json:"pollInterval" validate:"gt=0"`
+
+	// ResumeToken resumes watching after the event with this token.
+	ResumeToken string `json:"resumeToken"`
+}
+
+// WatchFunc allows to set watch options.
+type WatchFunc func(o *WatchOptions) error
+
+// PollEntry is the version of a document, as seen by polling watchers.
+type PollEntry struct {
+	// ModTime is the modification time.
+	ModTime time.Time
+
+	// Size in bytes.
+	Size int64
+}
+
+// PollSnapshotFunc returns the version of every document of a target, by ID.
+type PollSnapshotFunc func(ctx context.Context) (map[string]PollEntry, error)
+
+// PollReadFunc reads a document.
+type PollReadFunc func(ctx context.Context, id string) ([]byte, error)
+
+// subscriber is a Broadcaster subscriber.
+type subscriber struct {
+	ctx     context.Context
+	ch      chan ChangeEvent
+	options *WatchOptions
+}
+
+// Broadcaster streams in-process events to their target's subscribers. The
+// zero value is ready to use.
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*subscriber]struct{}
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithFullDocument includes the new document in events.
+func WithFullDocument() WatchFunc {
+	return func(o *WatchOptions) error {
+		o.FullDocument = true
+
+		return nil
+	}
+}
+
+// WithResumeToken resumes watching after the event with `token`. Storages
+// which don't support it fail with `ErrUnsupported`.
+func WithResumeToken(token string) WatchFunc {
+	return func(o *WatchOptions) error {
+		o.ResumeToken = token
+
+		return nil
+	}
+}
+
+// WithPollInterval sets the interval of polling watchers. Default is
+// `DefaultPollInterval`.
+func WithPollInterval(interval time.Duration) WatchFunc {
+	return func(o *WatchOptions) error {
+		if interval <= 0 {
+			return customerror.NewInvalidError("poll interval, must be > 0")
+		}
+
+		o.PollInterval = interval
+
+		return nil
+	}
+}
+
+// WithWatchBuffer sets the size of the events channel. Default is
+// `DefaultWatchBuffer`.
+func WithWatchBuffer(size int) WatchFunc {
+	return func(o *WatchOptions) error {
+		if size < 0 {
+			return customerror.NewInvalidError("watch buffer, must be >= 0")
+		}
+
+		o.Buffer = size
+
+		return nil
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// SendChangeEvent sends `event` to `ch`, unless `ctx` is done first. Returns
+// false if it is.
+func SendChangeEvent(ctx context.Context, ch chan<- ChangeEvent, event ChangeEvent) bool {
+	select {
+	case ch <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// FailedWatch returns a closed channel holding only an event with `err`.
+func FailedWatch(target string, err error) <-chan ChangeEvent {
+	ch := make(chan ChangeEvent, 1)
+
+	ch <- ChangeEvent{Target: target, Timestamp: time.Now(), Err: err}
+
+	close(ch)
+
+	return ch
+}
+
+//////
+// Exported functionalities.
+//////
+
+// NewWatchOptions returns the watch options, with defaults, and `options`
+// applied.
+func NewWatchOptions(options ...WatchFunc) (*WatchOptions, error) {
+	o := &WatchOptions{
+		Buffer:       DefaultWatchBuffer,
+		PollInterval: DefaultPollInterval,
+	}
+
+	for _, option := range options {
+		if err := option(o); err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
+
+// Watch streams the changes to `target` of `s`. Storages which don't
+// implement IWatcher yield a single `ErrUnsupported` event.
+func Watch(ctx context.Context, s IStorage, target string, options ...WatchFunc) <-chan ChangeEvent {
+	w, ok := s.(IWatcher)
+	if !ok {
+		return FailedWatch(target, customerror.NewFailedToError(
+			"watch "+s.GetName(),
+			customerror.WithError(ErrUnsupported),
+		))
+	}
+
+	return w.Watch(ctx, target, options...)
+}
+
+// PollWatch streams the changes to `target` detected by comparing snapshots,
+// taken every `o.PollInterval`: new documents are created, ones with another
+// modification time, or size are updated, and missing ones are deleted. The
+// first snapshot is the baseline. Snapshot errors are sent as events, and
+// polling continues.
+//
+// NOTE: Changes happening within an interval are coalesced. Resume tokens
+// aren't supported.
+func PollWatch(
+	ctx context.Context,
+	target string,
+	o *WatchOptions,
+	snapshot PollSnapshotFunc,
+	read PollReadFunc,
+) <-chan ChangeEvent {
+	if o.ResumeToken != "" {
+		return FailedWatch(target, customerror.NewFailedToError(
+			"resume polling watch",
+			customerror.WithError(ErrUnsupported),
+		))
+	}
+
+	previous, err := snapshot(ctx)
+	if err != nil {
+		return FailedWatch(target, err)
+	}
+
+	ch := make(chan ChangeEvent, o.Buffer)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(o.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := snapshot(ctx)
+			if err != nil {
+				if !SendChangeEvent(ctx, ch, ChangeEvent{Target: target, Timestamp: time.Now(), Err: err}) {
+					return
+				}
+
+				continue
+			}
+
+			for _, event := range diffSnapshots(ctx, target, previous, current, o, read) {
+				if !SendChangeEvent(ctx, ch, event) {
+					return
+				}
+			}
+
+			previous = current
+		}
+	}()
+
+	return ch
+}
+
+// diffSnapshots returns the events turning `previous` into `current`.
+func diffSnapshots(
+	ctx context.Context,
+	target string,
+	previous, current map[string]PollEntry,
+	o *WatchOptions,
+	read PollReadFunc,
+) []ChangeEvent {
+	events := []ChangeEvent{}
+	now := time.Now()
+
+	for id, entry := range current {
+		before, existed := previous[id]
+
+		if existed && before == entry {
+			continue
+		}
+
+		event := ChangeEvent{Operation: OperationUpdate, ID: id, Target: target, Timestamp: entry.ModTime}
+
+		if !existed {
+			event.Operation = OperationCreate
+		}
+
+		if o.FullDocument && read != nil {
+			if b, err := read(ctx, id); err == nil {
+				event.Document = b
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			events = append(events, ChangeEvent{Operation: OperationDelete, ID: id, Target: target, Timestamp: now})
+		}
+	}
+
+	return events
+}
+
+// Subscribe streams the events published to `target` until `ctx` is done.
+func (b *Broadcaster) Subscribe(ctx context.Context, target string, o *WatchOptions) <-chan ChangeEvent {
+	sub := &subscriber{ctx: ctx, ch: make(chan ChangeEvent, o.Buffer), options: o}
+
+	b.mu.Lock()
+
+	if b.subscribers == nil {
+		b.subscribers = map[string]map[*subscriber]struct{}{}
+	}
+
+	if b.subscribers[target] == nil {
+		b.subscribers[target] = map[*subscriber]struct{}{}
+	}
+
+	b.subscribers[target][sub] = struct{}{}
+
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		// Publishers hold the read lock while sending, and give up once
+		// `ctx` is done, so the channel is never closed while being sent to.
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers[target], sub)
+
+		close(sub.ch)
+	}()
+
+	return sub.ch
+}
+
+// HasSubscribers reports whether `target` has subscribers, so publishers can
+// skip building events.
+func (b *Broadcaster) HasSubscribers(target string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.subscribers[target]) > 0
+}
+
+// Publish sends `event` to its target's subscribers. It blocks until each one
+// received it, or stopped watching.
+func (b *Broadcaster) Publish(event ChangeEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	for sub := range b.subscribers[event.Target] {
+		e := event
+
+		if !sub.options.FullDocument {
+			e.Document = nil
+		}
+
+		SendChangeEvent(sub.ctx, sub.ch, e)
+	}
+}
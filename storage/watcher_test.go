@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch_Unsupported(t *testing.T) {
+	s, _ := newKVMock("watcherm1")
+
+	events := Watch(t.Context(), s, "users")
+
+	event, ok := <-events
+	require.True(t, ok)
+	require.ErrorIs(t, event.Err, ErrUnsupported)
+
+	_, ok = <-events
+	assert.False(t, ok)
+}
+
+func TestPollWatch(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		files = map[string]PollEntry{"a": {Size: 1}, "b": {Size: 1}}
+	)
+
+	set := func(f func()) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		f()
+	}
+
+	snapshot := func(_ context.Context) (map[string]PollEntry, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return maps.Clone(files), nil
+	}
+
+	read := func(_ context.Context, id string) ([]byte, error) {
+		return []byte(`"` + id + `"`), nil
+	}
+
+	o, err := NewWatchOptions(WithPollInterval(5*time.Millisecond), WithFullDocument())
+	require.NoError(t, err)
+
+	events := PollWatch(t.Context(), "dir", o, snapshot, read)
+
+	// The baseline isn't streamed.
+	set(func() { files["c"] = PollEntry{Size: 1} })
+
+	event := <-events
+	assert.Equal(t, OperationCreate, event.Operation)
+	assert.Equal(t, "c", event.ID)
+	assert.Equal(t, "dir", event.Target)
+	assert.JSONEq(t, `"c"`, string(event.Document))
+
+	set(func() { files["a"] = PollEntry{Size: 2} })
+
+	event = <-events
+	assert.Equal(t, OperationUpdate, event.Operation)
+	assert.Equal(t, "a", event.ID)
+
+	set(func() { delete(files, "b") })
+
+	event = <-events
+	assert.Equal(t, OperationDelete, event.Operation)
+	assert.Equal(t, "b", event.ID)
+	assert.Empty(t, event.Document)
+
+	o.ResumeToken = "1"
+
+	event = <-PollWatch(t.Context(), "dir", o, snapshot, read)
+	require.ErrorIs(t, event.Err, ErrUnsupported)
+}
+
+func TestBroadcaster(t *testing.T) {
+	var b Broadcaster
+
+	full, err := NewWatchOptions(WithFullDocument())
+	require.NoError(t, err)
+
+	partial, err := NewWatchOptions()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	withDocument := b.Subscribe(t.Context(), "users", full)
+	withoutDocument := b.Subscribe(ctx, "users", partial)
+
+	assert.True(t, b.HasSubscribers("users"))
+	assert.False(t, b.HasSubscribers("orders"))
+
+	b.Publish(ChangeEvent{Operation: OperationCreate, ID: "1", Target: "users", Document: []byte(`{}`)})
+
+	event := <-withDocument
+	assert.Equal(t, "1", event.ID)
+	assert.NotEmpty(t, event.Document)
+	assert.False(t, event.Timestamp.IsZero())
+
+	event = <-withoutDocument
+	assert.Equal(t, "1", event.ID)
+	assert.Empty(t, event.Document)
+
+	cancel()
+
+	// Closed once unsubscribed.
+	for range withoutDocument {
+	}
+
+	b.Publish(ChangeEvent{Operation: OperationDelete, ID: "1", Target: "users"})
+
+	event = <-withDocument
+	assert.Equal(t, OperationDelete, event.Operation)
+}
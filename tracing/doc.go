@@ -0,0 +1,9 @@
+// Package tracing abstracts the tracer every storage operation is traced
+// with. Elastic APM is the default; OpenTelemetry, and a no-op tracer are
+// built-in. Select one with `Set`, or the `DAL_TRACER` env var (`elasticapm`,
+// `otel`, or `none`).
+//
+// Spans carry the database context of the operation, including the native
+// statement. Enable `SetRedaction`, or `DAL_TRACING_REDACT` to keep the values
+// bound to statements out of traces.
+package tracing
@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/thalesfsp/sypl/v2/fields"
+	"go.elastic.co/apm"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// ElasticAPM traces with Elastic APM (`apm.DefaultTracer`). It's the default
+// tracer.
+type ElasticAPM struct{}
+
+// elasticAPMSpan wraps the APM span of a traced operation and, when the
+// operation had to start its own transaction (none was found in the incoming
+// context), the transaction as well. End ends both, so implicitly-created
+// transactions are reported and returned to the tracer's pool instead of
+// leaking.
+type elasticAPMSpan struct {
+	span *apm.Span
+
+	// tx is non-nil only when Start created the transaction itself.
+	tx *apm.Transaction
+
+	db Database
+}
+
+//////
+// Implements the ISpan interface.
+//////
+
+// SetDatabase sets the span's database context.
+func (s *elasticAPMSpan) SetDatabase(db Database) {
+	if s.span == nil || s.span.Dropped() {
+		return
+	}
+
+	s.db.Merge(db)
+
+	s.span.Context.SetDatabase(apm.DatabaseSpanContext{
+		Instance:  s.db.Name,
+		Statement: s.db.statement(),
+		Type:      s.db.System,
+		User:      s.db.User,
+	})
+}
+
+// SetRows sets the `db.rows_affected` label.
+func (s *elasticAPMSpan) SetRows(n int64) {
+	if s.span == nil || s.span.Dropped() {
+		return
+	}
+
+	s.span.Context.SetLabel(RowsAttribute, n)
+}
+
+// End ends the span, and the transaction if this operation created it.
+func (s *elasticAPMSpan) End() {
+	if s.span != nil {
+		s.span.End()
+	}
+
+	if s.tx != nil {
+		s.tx.End()
+	}
+}
+
+//////
+// Implements the ITracer interface.
+//////
+
+// Start uses the existing TX otherwise it fallback creating a new TX then it
+// creates a new span within the TX.
+func (ElasticAPM) Start(ctx context.Context, what, name, operation string) (context.Context, ISpan) {
+	var createdTX *apm.Transaction
+
+	tx := apm.TransactionFromContext(ctx)
+	if tx == nil {
+		tx = apm.DefaultTracer.StartTransaction(name, what)
+
+		createdTX = tx
+	}
+
+	ctx = apm.ContextWithTransaction(ctx, tx)
+
+	span, ctx := apm.StartSpan(
+		ctx,
+		fmt.Sprintf("%s.%s", name, operation),
+		fmt.Sprintf("%s.%s.%s", what, name, operation),
+	)
+
+	return ctx, &elasticAPMSpan{span: span, tx: createdTX}
+}
+
+// RecordError sets the span outcome to failure, and captures the error.
+func (ElasticAPM) RecordError(ctx context.Context, err error) {
+	// Get the current span from the context, if any, set the outcome.
+	span := apm.SpanFromContext(ctx)
+	if span != nil {
+		span.Outcome = "failure"
+	}
+
+	// Unwrap any nested errorcatalog. By default, apm.CaptureError() does not
+	// automatically unwrap nested errors or extract any additional context or
+	// metadata from the error.
+	for {
+		unwrapped := errors.Unwrap(err)
+		if unwrapped == nil {
+			break
+		}
+
+		err = unwrapped
+	}
+
+	// Tells APM that it that was an error.
+	if apmErr := apm.CaptureError(ctx, err); apmErr != nil {
+		apmErr.Send()
+	}
+}
+
+// LogFields adds the required APM fields enabling log correlation.
+//
+// NOTE: It expects the `apm.Transaction` to be in the context.
+func (ElasticAPM) LogFields(ctx context.Context, f fields.Fields) fields.Fields {
+	if f == nil {
+		f = fields.Fields{}
+	}
+
+	tx := apm.TransactionFromContext(ctx)
+	if tx != nil {
+		traceContext := tx.TraceContext()
+
+		f["trace.id"] = traceContext.Trace.String()
+		f["transaction.id"] = traceContext.Span.String()
+
+		if span := apm.SpanFromContext(ctx); span != nil {
+			f["span.id"] = span.TraceContext().Span.String()
+		}
+	}
+
+	return f
+}
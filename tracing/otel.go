@@ -0,0 +1,188 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thalesfsp/sypl/v2/fields"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// DefaultOTelInstrumentationName is the OpenTelemetry instrumentation scope.
+const DefaultOTelInstrumentationName = "github.com/thalesfsp/dal/v2"
+
+// OTelFunc allows to set OpenTelemetry tracer options.
+type OTelFunc func(o *OTel)
+
+// OTel traces with OpenTelemetry. Spans are client spans, following the
+// database semantic conventions (`db.system`, `db.name`, `db.operation`,
+// `db.statement`, `db.sql.table` / `db.mongodb.collection`).
+type OTel struct {
+	provider trace.TracerProvider
+}
+
+// otelSpan is an OpenTelemetry span.
+type otelSpan struct {
+	span trace.Span
+
+	db Database
+}
+
+//////
+// Implements the ISpan interface.
+//////
+
+// SetDatabase sets the database attributes.
+func (s *otelSpan) SetDatabase(db Database) {
+	s.db.Merge(db)
+
+	// The collection attribute depends on the system, which may have been
+	// set by a previous call.
+	if db.Collection != "" {
+		db.System = s.db.System
+	}
+
+	// Redaction may depend on the system as well.
+	if db.Statement != "" {
+		db.Statement = s.db.statement()
+	}
+
+	attrs := make([]attribute.KeyValue, 0, 6)
+
+	for key, value := range map[attribute.Key]string{
+		semconv.DBSystemKey:    db.System,
+		semconv.DBNameKey:      db.Name,
+		semconv.DBUserKey:      db.User,
+		semconv.DBOperationKey: db.Operation,
+		semconv.DBStatementKey: db.Statement,
+	} {
+		if value != "" {
+			attrs = append(attrs, key.String(value))
+		}
+	}
+
+	if db.Collection != "" {
+		attrs = append(attrs, collectionKey(db.System).String(db.Collection))
+	}
+
+	s.span.SetAttributes(attrs...)
+}
+
+// SetRows sets the `db.rows_affected` attribute.
+func (s *otelSpan) SetRows(n int64) {
+	s.span.SetAttributes(attribute.Int64(RowsAttribute, n))
+}
+
+// End ends the span.
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+//////
+// Helpers.
+//////
+
+// collectionKey returns the semantic conventions attribute of the
+// table/collection for a database system.
+func collectionKey(system string) attribute.Key {
+	switch system {
+	case semconv.DBSystemMongoDB.Value.AsString():
+		return semconv.DBMongoDBCollectionKey
+	case semconv.DBSystemPostgreSQL.Value.AsString(),
+		semconv.DBSystemMySQL.Value.AsString(),
+		semconv.DBSystemSqlite.Value.AsString():
+		return semconv.DBSQLTableKey
+	}
+
+	return attribute.Key("db.collection.name")
+}
+
+// tracer returns the OpenTelemetry tracer.
+func (o *OTel) tracer() trace.Tracer {
+	provider := o.provider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return provider.Tracer(DefaultOTelInstrumentationName)
+}
+
+//////
+// Implements the ITracer interface.
+//////
+
+// Start starts a client span named after the storage, and the operation, as
+// a child of the span in `ctx`, if any.
+func (o *OTel) Start(ctx context.Context, what, name, operation string) (context.Context, ISpan) {
+	ctx, span := o.tracer().Start(
+		ctx,
+		fmt.Sprintf("%s.%s", name, operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("dal.type", what)),
+	)
+
+	return ctx, &otelSpan{span: span}
+}
+
+// RecordError records `err` on the span in `ctx`, and sets its status to
+// error.
+func (o *OTel) RecordError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// LogFields adds the OpenTelemetry trace, and span ids enabling log
+// correlation.
+func (o *OTel) LogFields(ctx context.Context, f fields.Fields) fields.Fields {
+	if f == nil {
+		f = fields.Fields{}
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		f["trace.id"] = spanContext.TraceID().String()
+		f["span.id"] = spanContext.SpanID().String()
+	}
+
+	return f
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithTracerProvider sets the OpenTelemetry tracer provider. Default is the
+// global one (`otel.GetTracerProvider`), looked up on every span.
+func WithTracerProvider(provider trace.TracerProvider) OTelFunc {
+	return func(o *OTel) {
+		o.provider = provider
+	}
+}
+
+//////
+// Factory.
+//////
+
+// NewOTel returns an OpenTelemetry tracer.
+func NewOTel(opts ...OTelFunc) *OTel {
+	o := &OTel{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
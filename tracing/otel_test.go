@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newRecordingOTel(t *testing.T) (*OTel, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+
+	return NewOTel(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))), recorder
+}
+
+func TestOTel_Span(t *testing.T) {
+	tracer, recorder := newRecordingOTel(t)
+
+	ctx, span := tracer.Start(t.Context(), "storage", "postgres", "created")
+
+	span.SetDatabase(Database{System: DBSystem("postgres"), Operation: "created"})
+	span.SetDatabase(Database{Collection: "users"})
+	span.SetDatabase(Database{Statement: `INSERT INTO "users" ("id") VALUES ('1')`, User: "admin"})
+	span.SetRows(1)
+
+	tracer.RecordError(ctx, errors.New("duplicate key"))
+
+	// Log correlation.
+	f := tracer.LogFields(ctx, nil)
+	spanContext := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, spanContext.TraceID().String(), f["trace.id"])
+	assert.Equal(t, spanContext.SpanID().String(), f["span.id"])
+
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	s := spans[0]
+	assert.Equal(t, "postgres.created", s.Name())
+	assert.Equal(t, trace.SpanKindClient, s.SpanKind())
+	assert.Equal(t, codes.Error, s.Status().Code)
+	assert.Len(t, s.Events(), 1, "the error must be recorded")
+
+	attrs := map[attribute.Key]string{}
+	for _, kv := range s.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+
+	assert.Equal(t, "postgresql", attrs["db.system"])
+	assert.Equal(t, "created", attrs["db.operation"])
+	assert.Equal(t, "users", attrs["db.sql.table"])
+	assert.Equal(t, `INSERT INTO "users" ("id") VALUES ('1')`, attrs["db.statement"])
+	assert.Equal(t, "admin", attrs["db.user"])
+	assert.Equal(t, "1", attrs[RowsAttribute])
+}
+
+func TestOTel_Redaction(t *testing.T) {
+	t.Cleanup(func() { redaction.Store(nil) })
+
+	SetRedaction(true)
+
+	tracer, recorder := newRecordingOTel(t)
+
+	_, span := tracer.Start(t.Context(), "storage", "postgres", "retrieved")
+	span.SetDatabase(Database{System: "postgresql", Statement: `SELECT * FROM "users" WHERE ("id" = 'john@doe.com')`})
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.statement", `SELECT * FROM "users" WHERE ("id" = ?)`))
+}
+
+func TestOTel_ChildOfIncomingSpan(t *testing.T) {
+	tracer, recorder := newRecordingOTel(t)
+
+	parentCtx, parent := tracer.provider.Tracer("test").Start(t.Context(), "request")
+
+	_, span := tracer.Start(parentCtx, "storage", "mongodb", "retrieved")
+	span.SetDatabase(Database{System: "mongodb", Name: "db", Collection: "users"})
+	span.End()
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.mongodb.collection", "users"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.name", "db"))
+}
+
+func TestOTel_NoSpan(t *testing.T) {
+	tracer, _ := newRecordingOTel(t)
+
+	assert.NotPanics(t, func() { tracer.RecordError(t.Context(), errors.New("boom")) })
+	assert.Empty(t, tracer.LogFields(t.Context(), nil))
+}
@@ -0,0 +1,322 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/thalesfsp/sypl/v2/fields"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// EnvTracer is the env var which selects the tracer. See `FromName`.
+const EnvTracer = "DAL_TRACER"
+
+// EnvRedact is the env var which enables the redaction of statements. See
+// `SetRedaction`.
+const EnvRedact = "DAL_TRACING_REDACT"
+
+// Redacted replaces values in redacted statements.
+const Redacted = "?"
+
+// RowsAttribute is the attribute, or label of the number of rows affected,
+// or returned by an operation.
+const RowsAttribute = "db.rows_affected"
+
+// Built-in tracer names.
+const (
+	ElasticAPMName = "elasticapm"
+	NoopName       = "none"
+	OTelName       = "otel"
+)
+
+// Database describes the database operation a span represents. Empty fields
+// are ignored.
+type Database struct {
+	// System is the database system, e.g.: `postgresql`. See `DBSystem`.
+	System string `json:"system,omitempty"`
+
+	// Name of the database instance, e.g.: the Mongo database, or the S3
+	// bucket.
+	Name string `json:"name,omitempty"`
+
+	// User used to access the database.
+	User string `json:"user,omitempty"`
+
+	// Operation, e.g.: `create`.
+	Operation string `json:"operation,omitempty"`
+
+	// Statement, e.g.: the SQL, the Elasticsearch query, the Mongo filter, or
+	// the Redis command. See `SetRedaction`.
+	Statement string `json:"statement,omitempty"`
+
+	// Collection is the table, collection, index, or key space.
+	Collection string `json:"collection,omitempty"`
+}
+
+// ISpan is a traced operation.
+type ISpan interface {
+	// SetDatabase describes the database operation.
+	SetDatabase(db Database)
+
+	// SetRows sets the number of rows (documents, keys, objects) affected, or
+	// returned by the operation.
+	SetRows(n int64)
+
+	// End ends the span.
+	End()
+}
+
+// ITracer traces storage operations.
+type ITracer interface {
+	// Start starts a span for `operation` (e.g.: `created`) of the storage
+	// `name`. `what` is the type of the entity, e.g.: `storage`. The returned
+	// context carries the span.
+	Start(ctx context.Context, what, name, operation string) (context.Context, ISpan)
+
+	// RecordError tells the span in `ctx` - if any - that it failed, and
+	// captures `err`.
+	RecordError(ctx context.Context, err error)
+
+	// LogFields adds the fields correlating logs with the trace in `ctx`, e.g.:
+	// `trace.id`, and `span.id`.
+	LogFields(ctx context.Context, f fields.Fields) fields.Fields
+}
+
+// dbSystems maps storage names to the OpenTelemetry `db.system` values.
+var dbSystems = map[string]string{
+	"postgres": "postgresql",
+	"sqlite3":  "sqlite",
+}
+
+var (
+	tracerMu sync.RWMutex
+	tracer   ITracer
+)
+
+// redaction overrides `EnvRedact`, when set.
+var redaction atomic.Pointer[bool]
+
+var (
+	// sqlStringRegex matches SQL string literals.
+	sqlStringRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+	// sqlNumberRegex matches SQL numeric literals, but not placeholders
+	// (`$1`), or identifiers (`t1`).
+	sqlNumberRegex = regexp.MustCompile(`(^|[^\w$.])-?\d+(?:\.\d+)?\b`)
+)
+
+//////
+// Methods.
+//////
+
+// Merge sets the non-empty fields of `other` into `db`.
+func (db *Database) Merge(other Database) {
+	if other.System != "" {
+		db.System = other.System
+	}
+
+	if other.Name != "" {
+		db.Name = other.Name
+	}
+
+	if other.User != "" {
+		db.User = other.User
+	}
+
+	if other.Operation != "" {
+		db.Operation = other.Operation
+	}
+
+	if other.Statement != "" {
+		db.Statement = other.Statement
+	}
+
+	if other.Collection != "" {
+		db.Collection = other.Collection
+	}
+}
+
+// statement returns the statement, redacted if enabled.
+func (db *Database) statement() string {
+	if db.Statement == "" || !Redaction() {
+		return db.Statement
+	}
+
+	return Redact(db.System, db.Statement)
+}
+
+//////
+// Helpers.
+//////
+
+// redactJSON replaces every scalar of `v` with `Redacted`, keeping keys.
+func redactJSON(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, item := range value {
+			value[k] = redactJSON(item)
+		}
+
+		return value
+	case []any:
+		for i, item := range value {
+			value[i] = redactJSON(item)
+		}
+
+		return value
+	default:
+		return Redacted
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// DBSystem returns the database system of a storage, e.g.: `postgresql` for
+// `postgres`. Defaults to the storage name.
+func DBSystem(name string) string {
+	if system, ok := dbSystems[name]; ok {
+		return system
+	}
+
+	return name
+}
+
+// FromName returns a built-in tracer by name: `elasticapm`, `otel` (using the
+// global OpenTelemetry tracer provider), or `none`. Unknown names fall back to
+// Elastic APM.
+func FromName(name string) ITracer {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case OTelName, "opentelemetry":
+		return NewOTel()
+	case NoopName, "noop":
+		return Noop{}
+	default:
+		return ElasticAPM{}
+	}
+}
+
+// Get returns the tracer in use. It defaults to the one selected by the
+// `DAL_TRACER` env var, or Elastic APM.
+func Get() ITracer {
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+
+	if t != nil {
+		return t
+	}
+
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+
+	if tracer == nil {
+		tracer = FromName(os.Getenv(EnvTracer))
+	}
+
+	return tracer
+}
+
+// Set sets the tracer used by every storage. Nil resets it to the default.
+func Set(t ITracer) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+
+	tracer = t
+}
+
+// Redaction returns whether values bound to statements are redacted. It
+// defaults to the `DAL_TRACING_REDACT` env var.
+func Redaction() bool {
+	if enabled := redaction.Load(); enabled != nil {
+		return *enabled
+	}
+
+	enabled, _ := strconv.ParseBool(os.Getenv(EnvRedact))
+
+	return enabled
+}
+
+// SetRedaction enables, or disables the redaction of values bound to
+// statements, e.g.: to keep PII out of traces. It overrides the
+// `DAL_TRACING_REDACT` env var.
+func SetRedaction(enabled bool) {
+	redaction.Store(&enabled)
+}
+
+// Redact replaces the values bound to `statement` with `Redacted`:
+//
+//   - JSON (Elasticsearch queries, Mongo filters): every scalar, keeping keys
+//   - Redis commands: every argument after the key
+//   - SQL: string, and numeric literals.
+func Redact(system, statement string) string {
+	trimmed := strings.TrimSpace(statement)
+
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var v any
+		if err := json.Unmarshal([]byte(trimmed), &v); err == nil {
+			if b, err := json.Marshal(redactJSON(v)); err == nil {
+				return string(b)
+			}
+		}
+	}
+
+	if system == "redis" {
+		parts := strings.Fields(trimmed)
+
+		for i := 2; i < len(parts); i++ {
+			parts[i] = Redacted
+		}
+
+		return strings.Join(parts, " ")
+	}
+
+	redacted := sqlStringRegex.ReplaceAllString(statement, Redacted)
+
+	return sqlNumberRegex.ReplaceAllString(redacted, "${1}"+Redacted)
+}
+
+//////
+// No-op tracer.
+//////
+
+// Noop is a tracer which does nothing.
+type Noop struct{}
+
+type noopSpan struct{}
+
+// SetDatabase implements the ISpan interface.
+func (noopSpan) SetDatabase(Database) {}
+
+// SetRows implements the ISpan interface.
+func (noopSpan) SetRows(int64) {}
+
+// End implements the ISpan interface.
+func (noopSpan) End() {}
+
+// Start implements the ITracer interface.
+func (Noop) Start(ctx context.Context, _, _, _ string) (context.Context, ISpan) {
+	return ctx, noopSpan{}
+}
+
+// RecordError implements the ITracer interface.
+func (Noop) RecordError(context.Context, error) {}
+
+// LogFields implements the ITracer interface.
+func (Noop) LogFields(_ context.Context, f fields.Fields) fields.Fields {
+	if f == nil {
+		f = fields.Fields{}
+	}
+
+	return f
+}
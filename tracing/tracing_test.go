@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromName(t *testing.T) {
+	assert.IsType(t, ElasticAPM{}, FromName(""))
+	assert.IsType(t, ElasticAPM{}, FromName("unknown"))
+	assert.IsType(t, &OTel{}, FromName(" OTel "))
+	assert.IsType(t, Noop{}, FromName("none"))
+}
+
+func TestGetSet(t *testing.T) {
+	t.Cleanup(func() { Set(nil) })
+
+	// Selected by the env var.
+	Set(nil)
+	t.Setenv(EnvTracer, OTelName)
+	assert.IsType(t, &OTel{}, Get())
+
+	// Selected at runtime.
+	Set(Noop{})
+	assert.IsType(t, Noop{}, Get())
+}
+
+func TestDBSystem(t *testing.T) {
+	assert.Equal(t, "postgresql", DBSystem("postgres"))
+	assert.Equal(t, "sqlite", DBSystem("sqlite3"))
+	assert.Equal(t, "mongodb", DBSystem("mongodb"))
+}
+
+func TestNoop(t *testing.T) {
+	ctx, span := Noop{}.Start(t.Context(), "storage", "memory", "created")
+	assert.Equal(t, t.Context(), ctx)
+	assert.NotPanics(t, func() {
+		span.SetDatabase(Database{Statement: "x"})
+		span.End()
+	})
+
+	assert.NotNil(t, Noop{}.LogFields(ctx, nil))
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name      string
+		system    string
+		statement string
+		want      string
+	}{
+		{
+			name:      "sql",
+			system:    "postgresql",
+			statement: `SELECT * FROM "users" WHERE (("id" = 'it''s') AND ("age" > 42.5)) LIMIT 10`,
+			want:      `SELECT * FROM "users" WHERE (("id" = ?) AND ("age" > ?)) LIMIT ?`,
+		},
+		{
+			name:      "sql keeps placeholders, and identifiers",
+			system:    "postgresql",
+			statement: `UPDATE t1 SET "name"=$1 WHERE ("id" = $2)`,
+			want:      `UPDATE t1 SET "name"=$1 WHERE ("id" = $2)`,
+		},
+		{
+			name:      "json",
+			system:    "mongodb",
+			statement: `{"_id":"1","age":{"$gt":18},"tags":["a","b"]}`,
+			want:      `{"_id":"?","age":{"$gt":"?"},"tags":["?","?"]}`,
+		},
+		{
+			name:      "redis",
+			system:    "redis",
+			statement: `SET key {"name":"john"} NX`,
+			want:      `SET key ? ?`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Redact(tt.system, tt.statement))
+		})
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	t.Cleanup(func() { redaction.Store(nil) })
+
+	t.Setenv(EnvRedact, "true")
+	assert.True(t, Redaction())
+
+	SetRedaction(false)
+	assert.False(t, Redaction())
+}